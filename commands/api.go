@@ -62,6 +62,9 @@ func (c *ApiCommand) Execute([]string) error {
 	}
 	newConfig.AccessToken = c.config.AccessToken
 	newConfig.RefreshToken = c.config.RefreshToken
+	newConfig.ClientCertPath = c.config.ClientCertPath
+	newConfig.ClientKeyPath = c.config.ClientKeyPath
+	newConfig.CredentialProcess = c.config.CredentialProcess
 
 	err = verifyAuthServerConnection(newConfig, newConfig.InsecureSkipVerify)
 	if err != nil {
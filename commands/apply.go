@@ -0,0 +1,405 @@
+package commands
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"reflect"
+	"sort"
+	"strings"
+
+	"code.cloudfoundry.org/credhub-cli/credhub"
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials"
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials/values"
+	"code.cloudfoundry.org/credhub-cli/credhub/permissions"
+	"code.cloudfoundry.org/credhub-cli/errors"
+	"code.cloudfoundry.org/credhub-cli/models"
+)
+
+type ApplyCommand struct {
+	File      string `short:"f" long:"file" required:"yes" description:"File containing the desired state of the credentials under a path"`
+	Prune     bool   `long:"prune" description:"Delete the credentials under the path that are not in the file"`
+	PlanOnly  bool   `long:"plan" description:"Show the changes that would be made without making them"`
+	NoConfirm bool   `long:"no-confirm" description:"Make the changes without asking for confirmation"`
+	ClientCommand
+}
+
+type applyPlan struct {
+	Path    string       `json:"path" yaml:"path"`
+	Applied bool         `json:"applied" yaml:"applied"`
+	Steps   []*applyStep `json:"steps" yaml:"steps"`
+}
+
+type applyStep struct {
+	Name        string                   `json:"name" yaml:"name"`
+	Type        string                   `json:"type,omitempty" yaml:"type,omitempty"`
+	Action      string                   `json:"action" yaml:"action"`
+	Reason      string                   `json:"reason,omitempty" yaml:"reason,omitempty"`
+	Permissions []permissions.Permission `json:"permissions,omitempty" yaml:"permissions,omitempty"`
+
+	desired models.DesiredCredential
+	replace bool
+}
+
+const (
+	applyCreate     = "create"
+	applyUpdate     = "update"
+	applyRegenerate = "regenerate"
+	applyDelete     = "delete"
+	applyUnchanged  = "unchanged"
+)
+
+func (c *ApplyCommand) Execute([]string) error {
+	var state models.DesiredState
+	if err := state.ReadFile(c.File, c.currentPath); err != nil {
+		return err
+	}
+
+	plan, err := c.plan(state)
+	if err != nil {
+		return err
+	}
+
+	changes := plan.changes()
+
+	if !structuredOutput(false) {
+		printApplyPlan(plan)
+	}
+
+	if c.PlanOnly || changes == 0 {
+		return c.printResult(plan)
+	}
+
+	if !c.NoConfirm && !promptForConfirmation("Apply these changes? [y/N]: ") {
+		return errors.NewApplyCancelledError()
+	}
+
+	if err := c.apply(plan); err != nil {
+		return err
+	}
+
+	plan.Applied = true
+	return c.printResult(plan)
+}
+
+func (c *ApplyCommand) printResult(plan *applyPlan) error {
+	if structuredOutput(false) {
+		return printCredential(false, plan)
+	}
+	if plan.Applied {
+		fmt.Println("Apply complete.")
+	}
+	return nil
+}
+
+// plan compares the desired state with the credentials under its path
+func (c *ApplyCommand) plan(state models.DesiredState) (*applyPlan, error) {
+	existing, err := c.client.FindByPath(state.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &applyPlan{Path: state.Path, Steps: []*applyStep{}}
+	desired := map[string]bool{}
+
+	for _, credential := range state.Credentials {
+		desired[credential.Name] = true
+		step := &applyStep{Name: credential.Name, Type: credential.Type, Action: applyCreate, desired: credential}
+		exists := containsName(existing.Credentials, credential.Name)
+
+		if exists {
+			current, err := c.client.GetLatestVersion(credential.Name)
+			if err != nil {
+				return nil, err
+			}
+
+			step.Action, step.Reason = compareDesired(credential, current)
+			step.replace = current.Type != credential.Type
+		}
+
+		step.Permissions = credential.DesiredPermissions()
+		if exists && len(step.Permissions) > 0 {
+			current, err := c.client.GetPermissions(credential.Name)
+			if err != nil {
+				return nil, err
+			}
+			step.Permissions = missingPermissions(step.Permissions, current)
+		}
+
+		plan.Steps = append(plan.Steps, step)
+	}
+
+	if c.Prune {
+		var extra []string
+		for _, credential := range existing.Credentials {
+			name := absoluteName(credential.Name)
+			if !desired[name] {
+				extra = append(extra, name)
+			}
+		}
+		sort.Strings(extra)
+
+		for _, name := range extra {
+			plan.Steps = append(plan.Steps, &applyStep{Name: name, Action: applyDelete, Reason: "not in the desired state"})
+		}
+	}
+
+	return plan, nil
+}
+
+// compareDesired returns the action needed to bring the current credential to the desired state
+func compareDesired(desired models.DesiredCredential, current credentials.Credential) (string, string) {
+	action := applyUpdate
+	if desired.Generated() {
+		action = applyRegenerate
+	}
+
+	if current.Type != desired.Type {
+		return action, "the current type is " + current.Type
+	}
+
+	if !desired.Generated() {
+		if sameValue(credentials.Credential{Type: desired.Type, Value: desired.Value}, current) {
+			return applyUnchanged, ""
+		}
+		return applyUpdate, "the value differs"
+	}
+
+	parameters, err := desired.ParsedParameters()
+	if err != nil {
+		return applyRegenerate, err.Error()
+	}
+
+	if reason := generationDifference(desired.Type, parameters, current); reason != "" {
+		return applyRegenerate, reason
+	}
+
+	return applyUnchanged, ""
+}
+
+// generationDifference returns how the current value differs from one generated with the parameters,
+// considering only the parameters that can be read back from the value
+func generationDifference(credType string, parameters models.GenerationParameters, current credentials.Credential) string {
+	value, _ := current.Value.(map[string]interface{})
+
+	expectedLength := parameters.Length
+	if expectedLength == 0 {
+		expectedLength = 30
+	}
+
+	switch credType {
+	case "password":
+		if password, _ := current.Value.(string); len(password) != expectedLength {
+			return "the length differs"
+		}
+	case "user":
+		if username, _ := value["username"].(string); parameters.Username != "" && username != parameters.Username {
+			return "the username differs"
+		}
+		if password, _ := value["password"].(string); len(password) != expectedLength {
+			return "the length differs"
+		}
+	case "certificate":
+		certificate, _ := value["certificate"].(string)
+		block, _ := pem.Decode([]byte(certificate))
+		if block == nil {
+			return "the current certificate could not be parsed"
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return "the current certificate could not be parsed"
+		}
+
+		if cert.Subject.CommonName != parameters.CommonName {
+			return "the common name differs"
+		}
+		if !sameStrings(certificateSANs(cert), parameters.AlternativeName) {
+			return "the alternative names differ"
+		}
+		if cert.IsCA != parameters.IsCA {
+			return "is_ca differs"
+		}
+		if caName, _ := value["ca_name"].(string); parameters.Ca != "" && absoluteName(caName) != absoluteName(parameters.Ca) {
+			return "the CA differs"
+		}
+	case "rsa":
+		expectedKeyLength := parameters.KeyLength
+		if expectedKeyLength == 0 {
+			expectedKeyLength = 2048
+		}
+		publicKey, _ := value["public_key"].(string)
+		if block, _ := pem.Decode([]byte(publicKey)); block != nil {
+			if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+				if rsaKey, ok := key.(*rsa.PublicKey); ok && rsaKey.N.BitLen() != expectedKeyLength {
+					return "the key length differs"
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+func certificateSANs(cert *x509.Certificate) []string {
+	sans := append([]string{}, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	return sans
+}
+
+func sameStrings(a, b []string) bool {
+	a, b = append([]string{}, a...), append([]string{}, b...)
+	for i, s := range b {
+		if ip := net.ParseIP(s); ip != nil {
+			b[i] = ip.String()
+		}
+	}
+	sort.Strings(a)
+	sort.Strings(b)
+	return reflect.DeepEqual(a, b)
+}
+
+// missingPermissions returns the desired permissions that the current permissions do not grant
+func missingPermissions(desired, current []permissions.Permission) []permissions.Permission {
+	granted := map[string]map[string]bool{}
+	for _, permission := range current {
+		if granted[permission.Actor] == nil {
+			granted[permission.Actor] = map[string]bool{}
+		}
+		for _, operation := range permission.Operations {
+			granted[permission.Actor][operation] = true
+		}
+	}
+
+	var missing []permissions.Permission
+	for _, permission := range desired {
+		for _, operation := range permission.Operations {
+			if !granted[permission.Actor][operation] {
+				missing = append(missing, permission)
+				break
+			}
+		}
+	}
+	return missing
+}
+
+func (p *applyPlan) changes() int {
+	changes := 0
+	for _, step := range p.Steps {
+		if step.Action != applyUnchanged || len(step.Permissions) > 0 {
+			changes++
+		}
+	}
+	return changes
+}
+
+func printApplyPlan(plan *applyPlan) {
+	counts := map[string]int{}
+	permissionChanges := 0
+
+	fmt.Println("Plan for " + plan.Path + ":")
+	for _, step := range plan.Steps {
+		counts[step.Action]++
+
+		if step.Action != applyUnchanged {
+			line := fmt.Sprintf("  %-12s%s", step.Action, step.Name)
+			if step.Type != "" {
+				line += " [" + step.Type + "]"
+			}
+			if step.Reason != "" && step.Action != applyCreate {
+				line += ": " + step.Reason
+			}
+			fmt.Println(line)
+		}
+
+		for _, permission := range step.Permissions {
+			permissionChanges++
+			fmt.Printf("  %-12s%s: %s (%s)\n", "permissions", step.Name, permission.Actor, strings.Join(permission.Operations, ", "))
+		}
+	}
+
+	if plan.changes() == 0 {
+		fmt.Println("No changes. The credentials match the desired state.")
+		return
+	}
+
+	fmt.Printf("Plan: %d to create, %d to update, %d to regenerate, %d to delete, %d permission change(s), %d unchanged.\n",
+		counts[applyCreate], counts[applyUpdate], counts[applyRegenerate], counts[applyDelete], permissionChanges, counts[applyUnchanged])
+}
+
+// apply makes the changes in the plan, in the order of the desired state, and deletes extra credentials last
+func (c *ApplyCommand) apply(plan *applyPlan) error {
+	human := !structuredOutput(false)
+
+	for _, step := range plan.Steps {
+		if step.Action == applyDelete {
+			continue
+		}
+
+		if step.replace {
+			if err := c.client.Delete(step.Name); err != nil {
+				return err
+			}
+		}
+
+		switch step.Action {
+		case applyCreate, applyRegenerate:
+			if step.desired.Generated() {
+				parameters, err := step.desired.GenerationParameters()
+				if err != nil {
+					return err
+				}
+				if _, err := c.client.GenerateCredential(step.Name, step.Type, parameters, credhub.Converge); err != nil {
+					return err
+				}
+				break
+			}
+			fallthrough
+		case applyUpdate:
+			value, err := values.Typed(step.Type, step.desired.Value)
+			if err != nil {
+				return err
+			}
+			if _, err := c.client.SetCredential(step.Name, step.Type, value); err != nil {
+				return err
+			}
+		}
+
+		if len(step.Permissions) > 0 {
+			if _, err := c.client.AddPermissions(step.Name, step.Permissions); err != nil {
+				return err
+			}
+		}
+
+		if human && step.Action != applyUnchanged {
+			fmt.Println(applyDone[step.Action] + " " + step.Name)
+		}
+		if human && len(step.Permissions) > 0 {
+			fmt.Println("Added permissions to " + step.Name)
+		}
+	}
+
+	for _, step := range plan.Steps {
+		if step.Action != applyDelete {
+			continue
+		}
+		if err := c.client.Delete(step.Name); err != nil {
+			return err
+		}
+		if human {
+			fmt.Println(applyDone[step.Action] + " " + step.Name)
+		}
+	}
+
+	return nil
+}
+
+var applyDone = map[string]string{
+	applyCreate:     "Created",
+	applyUpdate:     "Updated",
+	applyRegenerate: "Regenerated",
+	applyDelete:     "Deleted",
+}
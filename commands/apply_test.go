@@ -0,0 +1,306 @@
+package commands_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("Apply", func() {
+	var (
+		lock        sync.Mutex
+		stored      map[string]string
+		storedPerms map[string]string
+		requests    []string
+		bodies      map[string]map[string]interface{}
+		desiredFile string
+	)
+
+	credentialJSON := func(name, credType string, value interface{}) string {
+		encoded, _ := json.Marshal(value)
+		return `{"type":"` + credType + `","id":"` + UUID + `","name":"` + name + `","version_created_at":"` + TIMESTAMP + `","value":` + string(encoded) + `}`
+	}
+
+	writeDesired := func(contents string) {
+		Expect(ioutil.WriteFile(desiredFile, []byte(contents), 0600)).To(Succeed())
+	}
+
+	const desired = `
+path: /deploy
+credentials:
+- name: api-key
+  type: value
+  value: new-api-key
+- name: db-password
+  type: password
+  value: same-password
+  permissions:
+  - actor: uaa-user:some-user
+    operations: [read, write]
+- name: admin-password
+  type: password
+  parameters:
+    length: 30
+- name: generated
+  type: password
+  parameters:
+    length: 40
+- name: new-password
+  type: password
+  parameters: {}
+- name: cert
+  type: certificate
+  parameters:
+    common_name: new-name
+`
+
+	BeforeEach(func() {
+		login()
+
+		desiredFile = filepath.Join(homeDir, "desired.yml")
+		writeDesired(desired)
+
+		stored = map[string]string{
+			"/deploy/api-key":        credentialJSON("/deploy/api-key", "value", "old-api-key"),
+			"/deploy/db-password":    credentialJSON("/deploy/db-password", "password", "same-password"),
+			"/deploy/admin-password": credentialJSON("/deploy/admin-password", "password", strings.Repeat("a", 30)),
+			"/deploy/generated":      credentialJSON("/deploy/generated", "password", strings.Repeat("a", 30)),
+			"/deploy/cert":           credentialJSON("/deploy/cert", "certificate", map[string]string{"certificate": selfSignedCertificate("old-name", nil, time.Now().AddDate(1, 0, 0))}),
+			"/deploy/extra":          credentialJSON("/deploy/extra", "value", "extra"),
+		}
+		storedPerms = map[string]string{
+			"/deploy/db-password": `[{"actor":"uaa-user:some-user","operations":["read"]}]`,
+		}
+		requests = nil
+		bodies = map[string]map[string]interface{}{}
+
+		server.RouteToHandler("GET", "/api/v1/data", func(w http.ResponseWriter, r *http.Request) {
+			lock.Lock()
+			defer lock.Unlock()
+
+			query := r.URL.Query()
+			if query.Get("path") != "" {
+				var names []string
+				for name := range stored {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+
+				found := []map[string]string{}
+				for _, name := range names {
+					found = append(found, map[string]string{"name": name, "version_created_at": TIMESTAMP})
+				}
+				json.NewEncoder(w).Encode(map[string]interface{}{"credentials": found})
+				return
+			}
+			w.Write([]byte(`{"data":[` + stored[query.Get("name")] + `]}`))
+		})
+
+		record := func(w http.ResponseWriter, r *http.Request) {
+			lock.Lock()
+			defer lock.Unlock()
+
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+
+			name, _ := body["name"].(string)
+			if name == "" {
+				name, _ = body["credential_name"].(string)
+			}
+			if name == "" {
+				name = r.URL.Query().Get("name")
+			}
+
+			requests = append(requests, r.Method+" "+r.URL.Path+" "+name)
+			bodies[r.Method+" "+r.URL.Path+" "+name] = body
+
+			if r.URL.Path == "/api/v1/data" && r.Method != "DELETE" {
+				w.Write([]byte(credentialJSON(name, body["type"].(string), "<redacted>")))
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{}`))
+		}
+		server.RouteToHandler("PUT", "/api/v1/data", record)
+		server.RouteToHandler("POST", "/api/v1/data", record)
+		server.RouteToHandler("DELETE", "/api/v1/data", record)
+		server.RouteToHandler("POST", "/api/v1/permissions", record)
+
+		server.RouteToHandler("GET", "/api/v1/permissions", func(w http.ResponseWriter, r *http.Request) {
+			name := r.URL.Query().Get("credential_name")
+			perms := storedPerms[name]
+			if perms == "" {
+				perms = "[]"
+			}
+			w.Write([]byte(`{"credential_name":"` + name + `","permissions":` + perms + `}`))
+		})
+	})
+
+	ItRequiresAuthentication("apply", "-f", "desired.yml")
+	ItRequiresAnAPIToBeSet("apply", "-f", "desired.yml")
+
+	ItBehavesLikeHelp("apply", "apply", func(session *Session) {
+		Expect(session.Err).To(Say("file"))
+		Expect(session.Err).To(Say("prune"))
+		Expect(session.Err).To(Say("plan"))
+		Expect(session.Err).To(Say("no-confirm"))
+	})
+
+	It("shows the plan without making changes with --plan", func() {
+		session := runCommand("apply", "-f", desiredFile, "--prune", "--plan")
+
+		Eventually(session).Should(Exit(0))
+		Expect(session.Out).To(Say(`Plan for /deploy:`))
+		Expect(session.Out).To(Say(`update      /deploy/api-key \[value\]: the value differs`))
+		Expect(session.Out).To(Say(`permissions /deploy/db-password: uaa-user:some-user \(read, write\)`))
+		Expect(session.Out).To(Say(`regenerate  /deploy/generated \[password\]: the length differs`))
+		Expect(session.Out).To(Say(`create      /deploy/new-password \[password\]`))
+		Expect(session.Out).To(Say(`regenerate  /deploy/cert \[certificate\]: the common name differs`))
+		Expect(session.Out).To(Say(`delete      /deploy/extra: not in the desired state`))
+		Expect(session.Out).To(Say(`Plan: 1 to create, 1 to update, 2 to regenerate, 1 to delete, 1 permission change\(s\), 2 unchanged.`))
+		Expect(string(session.Out.Contents())).NotTo(ContainSubstring("admin-password"))
+		Expect(string(session.Out.Contents())).NotTo(ContainSubstring("new-api-key"))
+		Expect(requests).To(BeEmpty())
+	})
+
+	It("applies the plan", func() {
+		session := runCommand("apply", "-f", desiredFile, "--prune", "--no-confirm")
+
+		Eventually(session).Should(Exit(0))
+		Expect(session.Out).To(Say("Updated /deploy/api-key"))
+		Expect(session.Out).To(Say("Added permissions to /deploy/db-password"))
+		Expect(session.Out).To(Say("Regenerated /deploy/generated"))
+		Expect(session.Out).To(Say("Created /deploy/new-password"))
+		Expect(session.Out).To(Say("Regenerated /deploy/cert"))
+		Expect(session.Out).To(Say("Deleted /deploy/extra"))
+		Expect(session.Out).To(Say("Apply complete."))
+
+		Expect(requests).To(Equal([]string{
+			"PUT /api/v1/data /deploy/api-key",
+			"POST /api/v1/permissions /deploy/db-password",
+			"POST /api/v1/data /deploy/generated",
+			"POST /api/v1/data /deploy/new-password",
+			"POST /api/v1/data /deploy/cert",
+			"DELETE /api/v1/data /deploy/extra",
+		}))
+
+		Expect(bodies["PUT /api/v1/data /deploy/api-key"]).To(Equal(map[string]interface{}{
+			"name": "/deploy/api-key", "type": "value", "value": "new-api-key",
+		}))
+		Expect(bodies["POST /api/v1/data /deploy/generated"]).To(Equal(map[string]interface{}{
+			"name": "/deploy/generated", "type": "password", "mode": "converge", "parameters": map[string]interface{}{"length": float64(40)},
+		}))
+		Expect(bodies["POST /api/v1/data /deploy/cert"]["parameters"]).To(Equal(map[string]interface{}{"common_name": "new-name"}))
+		Expect(bodies["POST /api/v1/permissions /deploy/db-password"]["permissions"]).To(Equal([]interface{}{
+			map[string]interface{}{"actor": "uaa-user:some-user", "operations": []interface{}{"read", "write"}},
+		}))
+	})
+
+	It("does not delete extra credentials without --prune", func() {
+		session := runCommand("apply", "-f", desiredFile, "--no-confirm")
+
+		Eventually(session).Should(Exit(0))
+		Expect(requests).NotTo(ContainElement("DELETE /api/v1/data /deploy/extra"))
+	})
+
+	It("deletes and recreates credentials whose type differs", func() {
+		writeDesired("path: /deploy\ncredentials:\n- name: api-key\n  type: password\n  parameters: {}\n")
+
+		session := runCommand("apply", "-f", desiredFile, "--no-confirm")
+
+		Eventually(session).Should(Exit(0))
+		Expect(session.Out).To(Say(`regenerate  /deploy/api-key \[password\]: the current type is value`))
+		Expect(requests).To(Equal([]string{
+			"DELETE /api/v1/data /deploy/api-key",
+			"POST /api/v1/data /deploy/api-key",
+		}))
+	})
+
+	It("makes no changes when the credentials match the desired state", func() {
+		writeDesired("path: /deploy\ncredentials:\n- name: db-password\n  type: password\n  value: same-password\n- name: admin-password\n  type: password\n  parameters: {}\n")
+
+		session := runCommand("apply", "-f", desiredFile)
+
+		Eventually(session).Should(Exit(0))
+		Expect(session.Out).To(Say("No changes. The credentials match the desired state."))
+		Expect(requests).To(BeEmpty())
+	})
+
+	It("makes no changes when planning again after applying a certificate signed by a relative CA", func() {
+		writeDesired("path: /deploy\ncredentials:\n- name: signed-cert\n  type: certificate\n  parameters:\n    common_name: signed\n    ca: my-ca\n")
+
+		server.RouteToHandler("POST", "/api/v1/data", func(w http.ResponseWriter, r *http.Request) {
+			lock.Lock()
+			defer lock.Unlock()
+
+			var body struct {
+				Name       string                 `json:"name"`
+				Parameters map[string]interface{} `json:"parameters"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+
+			requests = append(requests, r.Method+" "+r.URL.Path+" "+body.Name)
+			stored[body.Name] = credentialJSON(body.Name, "certificate", map[string]interface{}{
+				"ca_name":     body.Parameters["ca"],
+				"certificate": selfSignedCertificate(body.Parameters["common_name"].(string), nil, time.Now().AddDate(1, 0, 0)),
+			})
+			w.Write([]byte(stored[body.Name]))
+		})
+
+		session := runCommand("apply", "-f", desiredFile, "--no-confirm")
+		Eventually(session).Should(Exit(0))
+		Expect(requests).To(Equal([]string{"POST /api/v1/data /deploy/signed-cert"}))
+
+		session = runCommand("apply", "-f", desiredFile, "--plan")
+		Eventually(session).Should(Exit(0))
+		Expect(session.Out).To(Say("No changes. The credentials match the desired state."))
+		Expect(stored["/deploy/signed-cert"]).To(ContainSubstring(`"ca_name":"/deploy/my-ca"`))
+	})
+
+	It("does not make changes when they are not confirmed", func() {
+		session := runCommandWithStdin(strings.NewReader("n\n"), "apply", "-f", desiredFile)
+
+		Eventually(session).Should(Exit(1))
+		Expect(session.Out).To(Say(`Apply these changes\? \[y/N\]: `))
+		Expect(session.Err).To(Say("The changes were not applied."))
+		Expect(requests).To(BeEmpty())
+	})
+
+	It("returns the plan and whether it was applied in JSON format", func() {
+		session := runCommand("apply", "-f", desiredFile, "--plan", "--output", "json")
+
+		Eventually(session).Should(Exit(0))
+
+		var plan struct {
+			Path    string                   `json:"path"`
+			Applied bool                     `json:"applied"`
+			Steps   []map[string]interface{} `json:"steps"`
+		}
+		Expect(json.Unmarshal(session.Out.Contents(), &plan)).To(Succeed())
+		Expect(plan.Path).To(Equal("/deploy"))
+		Expect(plan.Applied).To(BeFalse())
+		Expect(plan.Steps).To(HaveLen(6))
+		Expect(plan.Steps[0]).To(Equal(map[string]interface{}{
+			"name": "/deploy/api-key", "type": "value", "action": "update", "reason": "the value differs",
+		}))
+	})
+
+	It("returns an error for an invalid desired state file", func() {
+		writeDesired("credentials: []")
+
+		session := runCommand("apply", "-f", desiredFile)
+
+		Eventually(session).Should(Exit(1))
+		Expect(session.Err).To(Say("The desired state file is not valid: a path is required. Please update and retry your request."))
+	})
+})
@@ -0,0 +1,134 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+
+	"code.cloudfoundry.org/credhub-cli/models"
+)
+
+type AuditRefsCommand struct {
+	Path      string   `short:"p" long:"path" required:"yes" description:"Path the deployment's credentials are stored under, e.g. /director/deployment"`
+	Manifests []string `short:"m" long:"manifest" required:"yes" description:"BOSH manifest or runtime config to collect references from (may be specified multiple times)"`
+	ClientCommand
+}
+
+type auditRefsResult struct {
+	Path         string             `json:"path" yaml:"path"`
+	Unreferenced []string           `json:"unreferenced" yaml:"unreferenced"`
+	Missing      []missingReference `json:"missing" yaml:"missing"`
+}
+
+type missingReference struct {
+	Name  string   `json:"name" yaml:"name"`
+	Files []string `json:"files" yaml:"files"`
+}
+
+func (c *AuditRefsCommand) ResolveNames() {
+	c.Path = c.resolvePath(c.Path)
+}
+
+// Execute also accepts the files after the flags, so that several manifests can be given with one -m
+func (c *AuditRefsCommand) Execute(args []string) error {
+	prefix := path.Clean("/" + c.Path)
+	files := append(c.Manifests, args...)
+
+	referencedBy := map[string][]string{}
+	declared := map[string]bool{}
+
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		references, err := models.ReadBoshReferences(data)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range references.Referenced {
+			name = models.CredentialName(prefix, name)
+			if !containsString(referencedBy[name], file) {
+				referencedBy[name] = append(referencedBy[name], file)
+			}
+		}
+		for _, name := range references.Declared {
+			declared[models.CredentialName(prefix, name)] = true
+		}
+	}
+
+	existing, err := c.client.FindByPath(prefix)
+	if err != nil {
+		return err
+	}
+
+	found := map[string]bool{}
+	result := auditRefsResult{Path: prefix, Unreferenced: []string{}, Missing: []missingReference{}}
+
+	for _, credential := range existing.Credentials {
+		name := absoluteName(credential.Name)
+		found[name] = true
+		if referencedBy[name] == nil && !declared[name] {
+			result.Unreferenced = append(result.Unreferenced, name)
+		}
+	}
+
+	// references outside the path are looked up under their own parent path
+	searched := map[string]bool{prefix: true}
+	for name := range referencedBy {
+		parent := path.Dir(name)
+		if found[name] || declared[name] || searched[parent] || strings.HasPrefix(name, strings.TrimSuffix(prefix, "/")+"/") {
+			continue
+		}
+		searched[parent] = true
+
+		others, err := c.client.FindByPath(parent)
+		if err != nil {
+			return err
+		}
+		for _, credential := range others.Credentials {
+			found[absoluteName(credential.Name)] = true
+		}
+	}
+
+	for name, referencingFiles := range referencedBy {
+		if !found[name] && !declared[name] {
+			result.Missing = append(result.Missing, missingReference{Name: name, Files: referencingFiles})
+		}
+	}
+
+	sort.Strings(result.Unreferenced)
+	sort.Slice(result.Missing, func(i, j int) bool { return result.Missing[i].Name < result.Missing[j].Name })
+
+	if structuredOutput(false) {
+		return printCredential(false, result)
+	}
+
+	printAuditRefs(result)
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func printAuditRefs(result auditRefsResult) {
+	fmt.Printf("Unreferenced credentials under %s (%d):\n", result.Path, len(result.Unreferenced))
+	for _, name := range result.Unreferenced {
+		fmt.Println("  " + name)
+	}
+
+	fmt.Printf("Missing references (%d):\n", len(result.Missing))
+	for _, missing := range result.Missing {
+		fmt.Printf("  %s (%s)\n", missing.Name, strings.Join(missing.Files, ", "))
+	}
+}
@@ -0,0 +1,120 @@
+package commands_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"regexp"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("Audit-Refs", func() {
+	var (
+		manifestFile      string
+		runtimeConfigFile string
+		searchedPaths     []string
+	)
+
+	stored := map[string][]string{
+		"/director/deployment": {
+			"/director/deployment/admin_password",
+			"/director/deployment/ca",
+			"/director/deployment/tls",
+			"/director/deployment/old_password",
+			"/director/deployment/removed/api_key",
+		},
+		"/shared": {"/shared/dns_key"},
+	}
+
+	BeforeEach(func() {
+		login()
+
+		manifestFile = filepath.Join(homeDir, "manifest.yml")
+		Expect(ioutil.WriteFile(manifestFile, []byte(`
+instance_groups:
+- name: web
+  properties:
+    password: ((admin_password))
+    tls: ((tls.certificate))
+    db_password: ((db_password))
+variables:
+- name: tls
+  type: certificate
+  options:
+    ca: ca
+- name: new_password
+  type: password
+`), 0600)).To(Succeed())
+
+		runtimeConfigFile = filepath.Join(homeDir, "runtime-config.yml")
+		Expect(ioutil.WriteFile(runtimeConfigFile, []byte(`
+addons:
+- name: dns
+  properties:
+    key: ((/shared/dns_key))
+    other: ((/shared/missing))
+    password: ((db_password))
+`), 0600)).To(Succeed())
+
+		searchedPaths = nil
+		server.RouteToHandler("GET", "/api/v1/data", func(w http.ResponseWriter, r *http.Request) {
+			searched := r.URL.Query().Get("path")
+			searchedPaths = append(searchedPaths, searched)
+
+			found := []map[string]string{}
+			for _, name := range stored[searched] {
+				found = append(found, map[string]string{"name": name, "version_created_at": TIMESTAMP})
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"credentials": found})
+		})
+	})
+
+	ItRequiresAuthentication("audit-refs", "-p", "/director/deployment", "-m", "manifest.yml")
+	ItRequiresAnAPIToBeSet("audit-refs", "-p", "/director/deployment", "-m", "manifest.yml")
+
+	ItBehavesLikeHelp("audit-refs", "audit-refs", func(session *Session) {
+		Expect(session.Err).To(Say("path"))
+		Expect(session.Err).To(Say("manifest"))
+	})
+
+	It("reports unreferenced credentials and missing references", func() {
+		session := runCommand("audit-refs", "-p", "/director/deployment/", "-m", manifestFile, runtimeConfigFile)
+
+		Eventually(session).Should(Exit(0))
+		Expect(session.Out).To(Say(`Unreferenced credentials under /director/deployment \(2\):\n`))
+		Expect(session.Out).To(Say(`  /director/deployment/old_password\n`))
+		Expect(session.Out).To(Say(`  /director/deployment/removed/api_key\n`))
+		Expect(session.Out).To(Say(`Missing references \(2\):\n`))
+		Expect(session.Out).To(Say(`  /director/deployment/db_password \(%s, %s\)\n`, regexp.QuoteMeta(manifestFile), regexp.QuoteMeta(runtimeConfigFile)))
+		Expect(session.Out).To(Say(`  /shared/missing \(%s\)\n`, regexp.QuoteMeta(runtimeConfigFile)))
+		Expect(searchedPaths).To(Equal([]string{"/director/deployment", "/shared"}))
+	})
+
+	It("accepts the files with -m multiple times and returns the results in JSON format", func() {
+		session := runCommand("audit-refs", "-p", "/director/deployment", "-m", manifestFile, "-m", runtimeConfigFile, "--output", "json")
+
+		Eventually(session).Should(Exit(0))
+		Expect(string(session.Out.Contents())).To(MatchJSON(`{
+			"path": "/director/deployment",
+			"unreferenced": ["/director/deployment/old_password", "/director/deployment/removed/api_key"],
+			"missing": [
+				{"name": "/director/deployment/db_password", "files": ["` + manifestFile + `", "` + runtimeConfigFile + `"]},
+				{"name": "/shared/missing", "files": ["` + runtimeConfigFile + `"]}
+			]
+		}`))
+	})
+
+	It("returns an error for a file that is not YAML", func() {
+		Expect(ioutil.WriteFile(manifestFile, []byte("a: [b"), 0600)).To(Succeed())
+
+		session := runCommand("audit-refs", "-p", "/director/deployment", "-m", manifestFile)
+
+		Eventually(session).Should(Exit(1))
+		Expect(session.Err).To(Say("The manifest is not valid: "))
+	})
+})
@@ -1,18 +1,101 @@
 package commands
 
+import (
+	"fmt"
+	"time"
+
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials"
+	"code.cloudfoundry.org/credhub-cli/errors"
+)
+
 type BulkRegenerateCommand struct {
-	SignedBy   string `required:"yes" long:"signed-by" description:"Selects the credential whose children should recursively be regenerated"`
-	OutputJSON bool   `short:"j" long:"output-json" description:"Return response in JSON format"`
+	SignedBy    string `required:"yes" long:"signed-by" description:"Selects the credential whose children should recursively be regenerated"`
+	DryRun      bool   `long:"dry-run" description:"Show the tree of certificates signed by the credential without regenerating them"`
+	Concurrency int    `long:"concurrency" default:"4" description:"Number of certificates to fetch at the same time with --dry-run"`
+	OutputJSON  bool   `short:"j" long:"output-json" description:"Return response in JSON format"`
 	ClientCommand
 }
 
+func (c *BulkRegenerateCommand) ResolveNames() {
+	c.SignedBy = c.resolveName(c.SignedBy)
+}
+
 func (c *BulkRegenerateCommand) Execute([]string) error {
+	if c.DryRun {
+		return c.printPlan()
+	}
+
 	credentials, err := c.client.BulkRegenerate(c.SignedBy)
 	if err != nil {
 		return err
 	}
 
-	printCredential(c.OutputJSON, credentials)
+	return printCredential(c.OutputJSON, credentials)
+}
+
+// printPlan prints the certificates signed by the credential through ca_name,
+// which are those that would be regenerated, as the certificates tree command does.
+// Only the values of those certificates are fetched.
+func (c *BulkRegenerateCommand) printPlan() error {
+	metadata, err := c.client.GetAllCertificatesMetadata()
+	if err != nil {
+		return err
+	}
+
+	name := absoluteName(c.SignedBy)
+	names := signedCertificateNames(metadata, name)
+	if len(names) == 0 {
+		return errors.NewCertificateNotFoundError(name)
+	}
+
+	creds, err := fetchLatestVersions(c.client, names, c.Concurrency)
+	if err != nil {
+		return err
+	}
 
+	roots, err := newCertificateTree(creds)
+	if err != nil {
+		return err
+	}
+
+	ca := findCertificateNode(roots, name)
+	if ca == nil {
+		return errors.NewCertificateNotFoundError(name)
+	}
+
+	if structuredOutput(c.OutputJSON) {
+		return printCredential(c.OutputJSON, ca)
+	}
+
+	printCertificateTree([]*certificateNode{ca}, time.Now())
+	fmt.Printf("Dry run: %d certificate(s) signed by %s would be regenerated. Certificates it signs that have no ca_name are not regenerated and not shown; use certificates tree --ca to see them.\n", ca.regenerated(), name)
 	return nil
 }
+
+// signedCertificateNames returns the name of the CA followed by the names of
+// the certificates it signs directly or indirectly, or nothing if it is not a
+// stored certificate
+func signedCertificateNames(metadata []credentials.CertificateMetadata, ca string) []string {
+	byName := map[string]credentials.CertificateMetadata{}
+	for _, certificate := range metadata {
+		byName[absoluteName(certificate.Name)] = certificate
+	}
+
+	if _, ok := byName[ca]; !ok {
+		return nil
+	}
+
+	names := []string{ca}
+	seen := map[string]bool{ca: true}
+	for i := 0; i < len(names); i++ {
+		for _, signed := range byName[names[i]].Signs {
+			signed = absoluteName(signed)
+			if !seen[signed] {
+				seen[signed] = true
+				names = append(names, signed)
+			}
+		}
+	}
+
+	return names
+}
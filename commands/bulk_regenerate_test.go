@@ -2,6 +2,7 @@ package commands_test
 
 import (
 	"net/http"
+	"sort"
 
 	"code.cloudfoundry.org/credhub-cli/commands"
 	. "github.com/onsi/ginkgo"
@@ -70,6 +71,43 @@ var _ = Describe("Bulk-regenerate", func() {
 		})
 	})
 
+	Describe("--dry-run", func() {
+		BeforeEach(func() {
+			serveCertificates()
+		})
+
+		It("prints the tree of certificates signed by the CA through ca_name without regenerating them", func() {
+			session := runCommand("bulk-regenerate", "--signed-by", "/root-ca", "--dry-run", "--concurrency", "2")
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Out).To(Say(`^/root-ca \[CA\] expires \S+ \(in 36\d days\)\n`))
+			Expect(session.Out).To(Say(`^└── /intermediate \[CA\] .*\n    ├── /leaf-a .*\n    └── /leaf-expired .*\n`))
+			Expect(session.Out).To(Say(`Dry run: 3 certificate\(s\) signed by /root-ca would be regenerated.`))
+		})
+
+		It("only fetches the values of the certificates signed by the CA", func() {
+			session := runCommand("bulk-regenerate", "--signed-by", "/intermediate", "--dry-run")
+
+			Eventually(session).Should(Exit(0))
+
+			var fetched []string
+			for _, request := range server.ReceivedRequests() {
+				if request.URL.Path == "/api/v1/data" {
+					fetched = append(fetched, request.URL.Query().Get("name"))
+				}
+			}
+			sort.Strings(fetched)
+			Expect(fetched).To(Equal([]string{"/intermediate", "/leaf-a", "/leaf-expired"}))
+		})
+
+		It("returns an error when the CA is not found", func() {
+			session := runCommand("bulk-regenerate", "--signed-by", "/missing-ca", "--dry-run")
+
+			Eventually(session).Should(Exit(1))
+			Expect(session.Err).To(Say(`The certificate "/missing-ca" was not found.`))
+		})
+	})
+
 	Describe("help", func() {
 		It("Behaves like help", func() {
 			session := runCommand("bulk-regenerate", "-h")
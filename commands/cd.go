@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"code.cloudfoundry.org/credhub-cli/config"
+)
+
+type CdCommand struct {
+	Args CdPositionalArgs `positional-args:"yes"`
+	ConfigCommand
+}
+
+type CdPositionalArgs struct {
+	Path string `positional-arg-name:"PATH" description:"Path to change to, absolute or relative to the current path. Defaults to /"`
+}
+
+type PwdCommand struct {
+	ConfigCommand
+}
+
+func (c *CdCommand) Execute([]string) error {
+	target := c.Args.Path
+	if target == "" {
+		target = "/"
+	}
+
+	if strings.HasPrefix(target, "/") {
+		target = path.Clean(target)
+	} else {
+		target = path.Join("/", c.config.CurrentPath, target)
+	}
+
+	if target == "/" {
+		target = ""
+	}
+
+	c.config.CurrentPath = target
+	if err := config.WriteConfig(c.config); err != nil {
+		return err
+	}
+
+	fmt.Println(currentPath(c.config))
+	return nil
+}
+
+func (c *PwdCommand) Execute([]string) error {
+	fmt.Println(currentPath(c.config))
+	return nil
+}
+
+func currentPath(cfg config.Config) string {
+	if cfg.CurrentPath == "" {
+		return "/"
+	}
+	return cfg.CurrentPath
+}
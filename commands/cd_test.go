@@ -0,0 +1,244 @@
+package commands_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+
+	"code.cloudfoundry.org/credhub-cli/config"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+	. "github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Cd and pwd", func() {
+	ItBehavesLikeHelp("cd", "cd", func(session *Session) {
+		Expect(session.Err).To(Say("cd"))
+		Expect(session.Err).To(Say("PATH"))
+	})
+
+	ItBehavesLikeHelp("pwd", "pwd", func(session *Session) {
+		Expect(session.Err).To(Say("pwd"))
+	})
+
+	cd := func(path string) {
+		session := runCommand("cd", path)
+		Eventually(session).Should(Exit(0))
+	}
+
+	pwd := func() string {
+		session := runCommand("pwd")
+		Eventually(session).Should(Exit(0))
+		return string(session.Out.Contents())
+	}
+
+	It("shows / when no current path is set", func() {
+		Expect(pwd()).To(Equal("/\n"))
+	})
+
+	It("changes to an absolute path", func() {
+		session := runCommand("cd", "/bosh-director/cf/")
+
+		Eventually(session).Should(Exit(0))
+		Expect(session.Out).To(Say("^/bosh-director/cf\n"))
+		Expect(pwd()).To(Equal("/bosh-director/cf\n"))
+		Expect(config.ReadConfig().CurrentPath).To(Equal("/bosh-director/cf"))
+	})
+
+	It("changes to a path relative to the current path", func() {
+		cd("/bosh-director")
+		cd("cf/diego")
+		Expect(pwd()).To(Equal("/bosh-director/cf/diego\n"))
+
+		cd("..")
+		Expect(pwd()).To(Equal("/bosh-director/cf\n"))
+
+		cd("../../../..")
+		Expect(pwd()).To(Equal("/\n"))
+	})
+
+	It("changes back to / without a path", func() {
+		cd("/bosh-director")
+
+		session := runCommand("cd")
+
+		Eventually(session).Should(Exit(0))
+		Expect(pwd()).To(Equal("/\n"))
+		Expect(config.ReadConfig().CurrentPath).To(BeEmpty())
+	})
+
+	It("keeps the rest of the config", func() {
+		apiURL := config.ReadConfig().ApiURL
+
+		cd("/bosh-director")
+
+		Expect(config.ReadConfig().ApiURL).To(Equal(apiURL))
+	})
+
+	Describe("resolving relative names", func() {
+		BeforeEach(func() {
+			login()
+			cd("/bosh-director/cf")
+		})
+
+		It("resolves names passed to get", func() {
+			server.RouteToHandler("GET", "/api/v1/data",
+				CombineHandlers(
+					VerifyRequest("GET", "/api/v1/data", "current=true&name=%2Fbosh-director%2Fcf%2Fadmin"),
+					RespondWith(http.StatusOK, `{"data":[`+passwordCredentialJSON("/bosh-director/cf/admin")+`]}`),
+				),
+			)
+
+			session := runCommand("get", "-n", "admin")
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Out).To(Say("name: /bosh-director/cf/admin"))
+		})
+
+		It("resolves .. against the parent path", func() {
+			server.RouteToHandler("GET", "/api/v1/data",
+				CombineHandlers(
+					VerifyRequest("GET", "/api/v1/data", "current=true&name=%2Fbosh-director%2Fdiego%2Fadmin"),
+					RespondWith(http.StatusOK, `{"data":[`+passwordCredentialJSON("/bosh-director/diego/admin")+`]}`),
+				),
+			)
+
+			session := runCommand("get", "-n", "../diego/admin")
+
+			Eventually(session).Should(Exit(0))
+		})
+
+		It("leaves absolute names unchanged", func() {
+			server.RouteToHandler("GET", "/api/v1/data",
+				CombineHandlers(
+					VerifyRequest("GET", "/api/v1/data", "current=true&name=%2Fother%2Fadmin"),
+					RespondWith(http.StatusOK, `{"data":[`+passwordCredentialJSON("/other/admin")+`]}`),
+				),
+			)
+
+			session := runCommand("get", "-n", "/other/admin")
+
+			Eventually(session).Should(Exit(0))
+		})
+
+		It("resolves names passed to set", func() {
+			server.RouteToHandler("PUT", "/api/v1/data",
+				CombineHandlers(
+					VerifyJSON(`{"name":"/bosh-director/cf/admin","type":"password","value":"some-password"}`),
+					RespondWith(http.StatusOK, passwordCredentialJSON("/bosh-director/cf/admin")),
+				),
+			)
+
+			session := runCommand("set", "-n", "admin", "-t", "password", "-w", "some-password")
+
+			Eventually(session).Should(Exit(0))
+		})
+
+		It("resolves names passed to generate", func() {
+			server.RouteToHandler("POST", "/api/v1/data",
+				CombineHandlers(
+					VerifyJSON(`{"name":"/bosh-director/cf/admin","type":"password","parameters":{},"overwrite":true}`),
+					RespondWith(http.StatusOK, passwordCredentialJSON("/bosh-director/cf/admin")),
+				),
+			)
+
+			session := runCommand("generate", "-n", "admin", "-t", "password")
+
+			Eventually(session).Should(Exit(0))
+		})
+
+		It("resolves names passed to delete", func() {
+			server.RouteToHandler("DELETE", "/api/v1/data",
+				CombineHandlers(
+					VerifyRequest("DELETE", "/api/v1/data", "name=%2Fbosh-director%2Fcf%2Fadmin"),
+					RespondWith(http.StatusNoContent, ""),
+				),
+			)
+
+			session := runCommand("delete", "-n", "admin")
+
+			Eventually(session).Should(Exit(0))
+		})
+
+		It("finds credentials under the current path", func() {
+			server.RouteToHandler("GET", "/api/v1/data",
+				CombineHandlers(
+					VerifyRequest("GET", "/api/v1/data", "path=%2Fbosh-director%2Fcf"),
+					RespondWith(http.StatusOK, `{"credentials":[{"name":"/bosh-director/cf/admin","version_created_at":"`+TIMESTAMP+`"}]}`),
+				),
+			)
+
+			session := runCommand("find")
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Out).To(Say("name: /bosh-director/cf/admin"))
+		})
+
+		It("resolves paths passed to find", func() {
+			server.RouteToHandler("GET", "/api/v1/data",
+				CombineHandlers(
+					VerifyRequest("GET", "/api/v1/data", "path=%2Fbosh-director%2Fcf%2Fdiego"),
+					RespondWith(http.StatusOK, `{"credentials":[]}`),
+				),
+			)
+
+			session := runCommand("find", "-p", "diego")
+
+			Eventually(session).Should(Exit(0))
+		})
+
+		It("only finds credentials under the current path by partial name", func() {
+			server.RouteToHandler("GET", "/api/v1/data",
+				CombineHandlers(
+					VerifyRequest("GET", "/api/v1/data", "name-like=admin"),
+					RespondWith(http.StatusOK, `{"credentials":[{"name":"/bosh-director/cf/admin","version_created_at":"`+TIMESTAMP+`"},{"name":"/other/admin","version_created_at":"`+TIMESTAMP+`"}]}`),
+				),
+			)
+
+			session := runCommand("find", "-n", "admin")
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Out).To(Say("name: /bosh-director/cf/admin"))
+			Expect(string(session.Out.Contents())).NotTo(ContainSubstring("/other/admin"))
+		})
+
+		It("resolves paths passed to export", func() {
+			server.RouteToHandler("GET", "/api/v1/data", func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("path") != "" {
+					Expect(r.URL.Query().Get("path")).To(Equal("/bosh-director/cf/diego"))
+					w.Write([]byte(`{"credentials":[{"name":"/bosh-director/cf/diego/admin","version_created_at":"` + TIMESTAMP + `"}]}`))
+					return
+				}
+				w.Write([]byte(`{"data":[` + passwordCredentialJSON("/bosh-director/cf/diego/admin") + `]}`))
+			})
+
+			session := runCommand("export", "-p", "diego")
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Out).To(Say("name: /bosh-director/cf/diego/admin"))
+		})
+
+		It("resolves the path of the file passed to apply", func() {
+			desiredFile := filepath.Join(homeDir, "desired.yml")
+			Expect(ioutil.WriteFile(desiredFile, []byte("path: diego\ncredentials: []\n"), 0600)).To(Succeed())
+
+			server.RouteToHandler("GET", "/api/v1/data",
+				CombineHandlers(
+					VerifyRequest("GET", "/api/v1/data", "path=%2Fbosh-director%2Fcf%2Fdiego"),
+					RespondWith(http.StatusOK, `{"credentials":[]}`),
+				),
+			)
+
+			session := runCommand("apply", "-f", desiredFile, "--plan", "--output", "json")
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Out).To(Say(`"path": "/bosh-director/cf/diego"`))
+		})
+	})
+})
+
+func passwordCredentialJSON(name string) string {
+	return `{"type":"password","id":"` + UUID + `","name":"` + name + `","version_created_at":"` + TIMESTAMP + `","value":"some-password"}`
+}
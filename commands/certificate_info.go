@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+// certificateInfo is the human-readable summary of a PEM encoded certificate
+type certificateInfo struct {
+	Subject     string
+	Issuer      string
+	SANs        []string
+	Serial      string
+	NotAfter    time.Time
+	Fingerprint string
+}
+
+func parseCertificateInfo(certificatePEM string) (*certificateInfo, error) {
+	cert, err := parseCertificate(certificatePEM)
+	if err != nil {
+		return nil, err
+	}
+
+	var sans []string
+	sans = append(sans, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	sans = append(sans, cert.EmailAddresses...)
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+	sort.Strings(sans)
+
+	fingerprint := sha256.Sum256(cert.Raw)
+
+	return &certificateInfo{
+		Subject:     cert.Subject.String(),
+		Issuer:      cert.Issuer.String(),
+		SANs:        sans,
+		Serial:      cert.SerialNumber.Text(16),
+		NotAfter:    cert.NotAfter.UTC(),
+		Fingerprint: hex.EncodeToString(fingerprint[:]),
+	}, nil
+}
+
+func parseCertificate(certificatePEM string) (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(certificatePEM))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("no PEM encoded certificate found")
+	}
+
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// fields returns the summary as named, comparable values
+func (i *certificateInfo) fields() map[string]interface{} {
+	return map[string]interface{}{
+		"subject":     i.Subject,
+		"issuer":      i.Issuer,
+		"sans":        strings.Join(i.SANs, ", "),
+		"expires":     i.NotAfter.Format(time.RFC3339),
+		"fingerprint": i.Fingerprint,
+	}
+}
@@ -0,0 +1,320 @@
+package commands
+
+import (
+	"bytes"
+	"crypto/x509"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials"
+	"code.cloudfoundry.org/credhub-cli/errors"
+)
+
+type CertificatesCommand struct {
+	Tree CertificatesTreeCommand `command:"tree" description:"Show which CAs sign which certificates as a tree" long-description:"Show the certificates under a path, or under the current path if no path is provided, as a tree of the CAs and the certificates they sign. A certificate is placed under the CA its ca_name refers to or, for certificates that were set without one, the stored CA whose subject key ID matches its authority key ID or whose subject matches its issuer and whose key signed it. Each certificate is annotated with its expiry. With --ca, only the CA and the certificates it signs, directly or indirectly, are shown. With --dot, the tree is printed as a Graphviz DOT graph."`
+}
+
+type CertificatesTreeCommand struct {
+	CA          string `long:"ca" description:"Only show this CA and the certificates it signs"`
+	Path        string `short:"p" long:"path" description:"Path of the certificates to include, defaults to the current path"`
+	Dot         bool   `long:"dot" description:"Return the tree as a Graphviz DOT graph"`
+	Concurrency int    `long:"concurrency" default:"4" description:"Number of credentials to fetch at the same time"`
+	ClientCommand
+}
+
+type certificateNode struct {
+	Name      string             `json:"name" yaml:"name"`
+	Subject   string             `json:"subject,omitempty" yaml:"subject,omitempty"`
+	CA        bool               `json:"ca" yaml:"ca"`
+	Expires   string             `json:"expires,omitempty" yaml:"expires,omitempty"`
+	SignedBy  string             `json:"signed_by,omitempty" yaml:"signed_by,omitempty"`
+	MatchedBy string             `json:"matched_by,omitempty" yaml:"matched_by,omitempty"`
+	Error     string             `json:"error,omitempty" yaml:"error,omitempty"`
+	Children  []*certificateNode `json:"children" yaml:"children"`
+
+	cert   *x509.Certificate
+	parent *certificateNode
+}
+
+// How a certificate was matched to the CA that signed it
+const (
+	matchedByCaName         = "ca_name"
+	matchedByAuthorityKeyId = "authority_key_id"
+	matchedByIssuer         = "issuer"
+)
+
+func (c *CertificatesTreeCommand) ResolveNames() {
+	c.CA = c.resolveName(c.CA)
+	c.Path = c.resolvePath(c.Path)
+}
+
+func (c *CertificatesTreeCommand) Execute([]string) error {
+	roots, err := buildCertificateTree(c.ClientCommand, c.Path, c.Concurrency)
+	if err != nil {
+		return err
+	}
+
+	if c.CA != "" {
+		ca := findCertificateNode(roots, absoluteName(c.CA))
+		if ca == nil {
+			return errors.NewCertificateNotFoundError(absoluteName(c.CA))
+		}
+		roots = []*certificateNode{ca}
+	}
+
+	switch {
+	case c.Dot:
+		printCertificateDot(roots, time.Now())
+	case structuredOutput(false):
+		return printCredential(false, map[string][]*certificateNode{"certificates": roots})
+	default:
+		printCertificateTree(roots, time.Now())
+	}
+
+	return nil
+}
+
+// buildCertificateTree returns the certificates under the path, each under the
+// CA that signed it. Certificates whose CA is not stored under the path are roots.
+func buildCertificateTree(c ClientCommand, requested string, concurrency int) ([]*certificateNode, error) {
+	prefix := path.Clean("/" + requested)
+
+	found, err := c.client.FindByPath(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, credential := range found.Credentials {
+		names = append(names, credential.Name)
+	}
+
+	creds, err := fetchLatestVersions(c.client, names, concurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	return newCertificateTree(creds)
+}
+
+// newCertificateTree places each of the certificates among the credentials
+// under the CA that signed it. Certificates whose CA is not among them are roots.
+func newCertificateTree(creds []credentials.Credential) ([]*certificateNode, error) {
+	var nodes []*certificateNode
+	caNames := map[*certificateNode]string{}
+	byName := map[string]*certificateNode{}
+
+	for _, credential := range creds {
+		if credential.Type != "certificate" {
+			continue
+		}
+
+		node := &certificateNode{Name: absoluteName(credential.Name), Children: []*certificateNode{}}
+		value, _ := credential.Value.(map[string]interface{})
+		certificate, _ := value["certificate"].(string)
+		if caName, _ := value["ca_name"].(string); caName != "" {
+			caNames[node] = absoluteName(caName)
+		}
+
+		if cert, err := parseCertificate(certificate); err != nil {
+			node.Error = err.Error()
+		} else {
+			node.cert = cert
+			node.Subject = cert.Subject.String()
+			node.CA = cert.IsCA
+			node.Expires = cert.NotAfter.UTC().Format(time.RFC3339)
+		}
+
+		nodes = append(nodes, node)
+		byName[node.Name] = node
+	}
+
+	if len(nodes) == 0 {
+		return nil, errors.NewNoMatchingCredentialsFoundError()
+	}
+
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+
+	for _, node := range nodes {
+		var parent *certificateNode
+
+		if caName := caNames[node]; caName != "" && caName != node.Name {
+			node.SignedBy, node.MatchedBy = caName, matchedByCaName
+			parent = byName[caName]
+		} else if node.cert != nil && !selfSigned(node.cert) {
+			parent, node.MatchedBy = findIssuer(node, nodes)
+			if parent != nil {
+				node.SignedBy = parent.Name
+			}
+		}
+
+		if parent != nil && !parent.descendsFrom(node) {
+			node.parent = parent
+			parent.Children = append(parent.Children, node)
+		}
+	}
+
+	var roots []*certificateNode
+	for _, node := range nodes {
+		if node.parent == nil {
+			roots = append(roots, node)
+		}
+	}
+
+	return roots, nil
+}
+
+func selfSigned(cert *x509.Certificate) bool {
+	return bytes.Equal(cert.RawIssuer, cert.RawSubject) &&
+		(len(cert.AuthorityKeyId) == 0 || bytes.Equal(cert.AuthorityKeyId, cert.SubjectKeyId))
+}
+
+// findIssuer returns the CA among the nodes whose subject key ID is the
+// certificate's authority key ID or, failing that, whose subject is its issuer
+// and whose key signed it
+func findIssuer(node *certificateNode, nodes []*certificateNode) (*certificateNode, string) {
+	if len(node.cert.AuthorityKeyId) > 0 {
+		for _, candidate := range nodes {
+			if candidate != node && candidate.cert != nil && bytes.Equal(candidate.cert.SubjectKeyId, node.cert.AuthorityKeyId) {
+				return candidate, matchedByAuthorityKeyId
+			}
+		}
+	}
+
+	for _, candidate := range nodes {
+		if candidate != node && candidate.cert != nil && bytes.Equal(candidate.cert.RawSubject, node.cert.RawIssuer) &&
+			node.cert.CheckSignatureFrom(candidate.cert) == nil {
+			return candidate, matchedByIssuer
+		}
+	}
+
+	return nil, ""
+}
+
+// descendsFrom reports whether the node is, or is signed directly or indirectly by, the ancestor
+func (n *certificateNode) descendsFrom(ancestor *certificateNode) bool {
+	for node := n; node != nil; node = node.parent {
+		if node == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+func findCertificateNode(nodes []*certificateNode, name string) *certificateNode {
+	for _, node := range nodes {
+		if node.Name == name {
+			return node
+		}
+		if found := findCertificateNode(node.Children, name); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// regenerated returns the number of certificates bulk-regenerate would
+// regenerate for the node: those it signs directly or indirectly through ca_name
+func (n *certificateNode) regenerated() int {
+	total := 0
+	for _, child := range n.Children {
+		if child.MatchedBy == matchedByCaName {
+			total += 1 + child.regenerated()
+		}
+	}
+	return total
+}
+
+// annotation describes the node's expiry and how it was matched to its CA,
+// e.g. "[CA] expires 2027-01-01 (in 440 days)"
+func (n *certificateNode) annotation(now time.Time) string {
+	var parts []string
+
+	if n.CA {
+		parts = append(parts, "[CA]")
+	}
+
+	if n.cert == nil {
+		parts = append(parts, "[certificate could not be parsed: "+n.Error+"]")
+	} else {
+		notAfter := n.cert.NotAfter.UTC()
+		days := int(notAfter.Sub(now).Hours() / 24)
+		if notAfter.Before(now) {
+			parts = append(parts, fmt.Sprintf("EXPIRED %s (%d days ago)", notAfter.Format("2006-01-02"), -days))
+		} else {
+			parts = append(parts, fmt.Sprintf("expires %s (in %d days)", notAfter.Format("2006-01-02"), days))
+		}
+	}
+
+	switch {
+	case n.parent == nil && n.SignedBy != "":
+		parts = append(parts, "[signed by "+n.SignedBy+", which is not stored under the path]")
+	case n.MatchedBy == matchedByAuthorityKeyId:
+		parts = append(parts, "[matched by authority key ID]")
+	case n.MatchedBy == matchedByIssuer:
+		parts = append(parts, "[matched by issuer]")
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func printCertificateTree(roots []*certificateNode, now time.Time) {
+	for _, root := range roots {
+		fmt.Println(root.Name + " " + root.annotation(now))
+		root.printChildren("", now)
+	}
+}
+
+func (n *certificateNode) printChildren(indent string, now time.Time) {
+	for i, child := range n.Children {
+		prefix, next := "├── ", "│   "
+		if i == len(n.Children)-1 {
+			prefix, next = "└── ", "    "
+		}
+		fmt.Println(indent + prefix + child.Name + " " + child.annotation(now))
+		child.printChildren(indent+next, now)
+	}
+}
+
+// printCertificateDot prints the tree as a Graphviz graph with an edge from
+// each CA to the certificates it signs. Expired certificates are red, and
+// edges not matched by ca_name are dashed.
+func printCertificateDot(roots []*certificateNode, now time.Time) {
+	fmt.Println("digraph certificates {")
+	fmt.Println("\trankdir=LR;")
+	fmt.Println("\tnode [shape=box];")
+
+	var visit func(node *certificateNode)
+	visit = func(node *certificateNode) {
+		attributes := []string{"label=" + dotQuote(node.Name+"\n"+node.annotation(now))}
+		if node.CA {
+			attributes = append(attributes, "style=bold")
+		}
+		if node.cert == nil || node.cert.NotAfter.Before(now) {
+			attributes = append(attributes, "color=red")
+		}
+		fmt.Printf("\t%s [%s];\n", dotQuote(node.Name), strings.Join(attributes, ", "))
+
+		for _, child := range node.Children {
+			edge := ""
+			if child.MatchedBy != matchedByCaName {
+				edge = " [style=dashed]"
+			}
+			fmt.Printf("\t%s -> %s%s;\n", dotQuote(node.Name), dotQuote(child.Name), edge)
+			visit(child)
+		}
+	}
+
+	for _, root := range roots {
+		visit(root)
+	}
+
+	fmt.Println("}")
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
@@ -0,0 +1,225 @@
+package commands_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"sort"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+)
+
+type certificateFixture struct {
+	cert *x509.Certificate
+	key  *rsa.PrivateKey
+	pem  string
+}
+
+// signedCertificate returns a certificate signed by the issuer, or self-signed
+// if the issuer is nil. Without an authority key ID, the issuer's subject key
+// ID is not copied to the certificate.
+func signedCertificate(commonName string, isCA bool, notAfter time.Time, issuer *certificateFixture, authorityKeyId bool) *certificateFixture {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	Expect(err).NotTo(HaveOccurred())
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             notAfter.AddDate(-1, 0, 0),
+		NotAfter:              notAfter,
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+
+	parent, signer := template, key
+	if issuer != nil {
+		parentCopy := *issuer.cert
+		if !authorityKeyId {
+			parentCopy.SubjectKeyId = nil
+		}
+		parent, signer = &parentCopy, issuer.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	Expect(err).NotTo(HaveOccurred())
+	cert, err := x509.ParseCertificate(der)
+	Expect(err).NotTo(HaveOccurred())
+
+	return &certificateFixture{cert: cert, key: key, pem: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))}
+}
+
+// serveCertificates responds to find, get and certificate metadata requests
+// with a hierarchy of certificates under a root CA, some linked by ca_name and
+// some only by their authority key ID or issuer, and a password
+func serveCertificates() {
+	inAYear := time.Now().AddDate(1, 0, 0)
+
+	rootCA := signedCertificate("root", true, inAYear, nil, true)
+	intermediate := signedCertificate("intermediate", true, inAYear, rootCA, true)
+	external := signedCertificate("external", true, inAYear, nil, true)
+
+	stored := map[string]map[string]interface{}{
+		"/root-ca":      {"type": "certificate", "certificate": rootCA.pem, "ca_name": "/root-ca"},
+		"/intermediate": {"type": "certificate", "certificate": intermediate.pem, "ca_name": "/root-ca"},
+		"/leaf-a": {"type": "certificate", "ca_name": "/intermediate",
+			"certificate": signedCertificate("leaf-a", false, time.Now().Add(30*24*time.Hour+time.Hour), intermediate, true).pem},
+		"/leaf-expired": {"type": "certificate", "ca_name": "/intermediate",
+			"certificate": signedCertificate("leaf-expired", false, time.Now().Add(-10*24*time.Hour-time.Hour), intermediate, true).pem},
+		"/set-leaf":    {"type": "certificate", "certificate": signedCertificate("set-leaf", false, inAYear, rootCA, true).pem},
+		"/issuer-leaf": {"type": "certificate", "certificate": signedCertificate("issuer-leaf", false, inAYear, rootCA, false).pem},
+		"/external":    {"type": "certificate", "ca_name": "/missing-ca", "certificate": signedCertificate("external-leaf", false, inAYear, external, true).pem},
+		"/password":    {"type": "password"},
+	}
+
+	var names []string
+	for name := range stored {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	server.RouteToHandler("GET", "/api/v1/certificates", func(w http.ResponseWriter, r *http.Request) {
+		metadata := []map[string]interface{}{}
+		for _, name := range names {
+			if stored[name]["type"] != "certificate" {
+				continue
+			}
+			signs := []string{}
+			for _, other := range names {
+				if other != name && stored[other]["ca_name"] == name {
+					signs = append(signs, other)
+				}
+			}
+			metadata = append(metadata, map[string]interface{}{"id": UUID, "name": name, "signed_by": stored[name]["ca_name"], "signs": signs})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"certificates": metadata})
+	})
+
+	server.RouteToHandler("GET", "/api/v1/data", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("path") != "" {
+
+			found := []map[string]string{}
+			for _, name := range names {
+				found = append(found, map[string]string{"name": name, "version_created_at": TIMESTAMP})
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"credentials": found})
+			return
+		}
+
+		name := r.URL.Query().Get("name")
+		value := map[string]interface{}{}
+		for k, v := range stored[name] {
+			if k != "type" {
+				value[k] = v
+			}
+		}
+		var credentialValue interface{} = value
+		if stored[name]["type"] == "password" {
+			credentialValue = "some-password"
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": []interface{}{map[string]interface{}{
+			"type":               stored[name]["type"],
+			"id":                 UUID,
+			"name":               name,
+			"version_created_at": TIMESTAMP,
+			"value":              credentialValue,
+		}}})
+	})
+}
+
+var _ = Describe("Certificates", func() {
+	BeforeEach(func() {
+		login()
+		serveCertificates()
+	})
+
+	ItRequiresAuthentication("certificates", "tree")
+	ItRequiresAnAPIToBeSet("certificates", "tree")
+
+	It("displays help", func() {
+		session := runCommand("certificates", "tree", "-h")
+
+		Eventually(session).Should(Exit(1))
+		Expect(session.Err).To(Say("certificates tree"))
+		Expect(session.Err).To(Say("--ca"))
+		Expect(session.Err).To(Say("--dot"))
+	})
+
+	Describe("tree", func() {
+		It("shows the certificates under the CAs that sign them, with their expiry", func() {
+			session := runCommand("certificates", "tree")
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Out).To(Say(`^/external expires \S+ \(in 36\d days\) \[signed by /missing-ca, which is not stored under the path\]\n`))
+			Expect(session.Out).To(Say(`^/root-ca \[CA\] expires \S+ \(in 36\d days\)\n`))
+			Expect(session.Out).To(Say(`^├── /intermediate \[CA\] expires \S+ \(in 36\d days\)\n`))
+			Expect(session.Out).To(Say(`^│   ├── /leaf-a expires \S+ \(in 30 days\)\n`))
+			Expect(session.Out).To(Say(`^│   └── /leaf-expired EXPIRED \S+ \(10 days ago\)\n`))
+			Expect(session.Out).To(Say(`^├── /issuer-leaf expires \S+ \(in 36\d days\) \[matched by issuer\]\n`))
+			Expect(session.Out).To(Say(`^└── /set-leaf expires \S+ \(in 36\d days\) \[matched by authority key ID\]\n$`))
+			Expect(string(session.Out.Contents())).NotTo(ContainSubstring("/password"))
+		})
+
+		It("only shows the certificates signed by the CA given with --ca", func() {
+			session := runCommand("certificates", "tree", "--ca", "/intermediate")
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Out).To(Say(`^/intermediate \[CA\] expires \S+ \(in 36\d days\)\n├── /leaf-a .*\n└── /leaf-expired .*\n$`))
+		})
+
+		It("returns the tree as a DOT graph", func() {
+			session := runCommand("certificates", "tree", "--ca", "/root-ca", "--dot")
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Out).To(Say(`digraph certificates {\n`))
+			Expect(session.Out).To(Say(`\t"/root-ca" \[label="/root-ca\\n\[CA\] expires \S+ \(in 36\d days\)", style=bold\];\n`))
+			Expect(session.Out).To(Say(`\t"/root-ca" -> "/intermediate";\n`))
+			Expect(session.Out).To(Say(`\t"/leaf-expired" \[label=".*", color=red\];\n`))
+			Expect(session.Out).To(Say(`\t"/root-ca" -> "/issuer-leaf" \[style=dashed\];\n`))
+			Expect(session.Out).To(Say(`}\n`))
+		})
+
+		It("returns the tree in JSON format", func() {
+			session := runCommand("certificates", "tree", "--ca", "/root-ca", "--output", "json")
+
+			Eventually(session).Should(Exit(0))
+
+			var output struct {
+				Certificates []struct {
+					Name     string `json:"name"`
+					CA       bool   `json:"ca"`
+					Children []struct {
+						Name      string `json:"name"`
+						SignedBy  string `json:"signed_by"`
+						MatchedBy string `json:"matched_by"`
+					} `json:"children"`
+				} `json:"certificates"`
+			}
+			Expect(json.Unmarshal(session.Out.Contents(), &output)).To(Succeed())
+			Expect(output.Certificates).To(HaveLen(1))
+			Expect(output.Certificates[0].CA).To(BeTrue())
+			Expect(output.Certificates[0].Children).To(HaveLen(3))
+			Expect(output.Certificates[0].Children[0].Name).To(Equal("/intermediate"))
+			Expect(output.Certificates[0].Children[0].MatchedBy).To(Equal("ca_name"))
+			Expect(output.Certificates[0].Children[2].SignedBy).To(Equal("/root-ca"))
+			Expect(output.Certificates[0].Children[2].MatchedBy).To(Equal("authority_key_id"))
+		})
+
+		It("returns an error when the CA is not found", func() {
+			session := runCommand("certificates", "tree", "--ca", "/missing-ca")
+
+			Eventually(session).Should(Exit(1))
+			Expect(session.Err).To(Say(`The certificate "/missing-ca" was not found.`))
+		})
+	})
+})
@@ -1,11 +1,15 @@
 package commands_test
 
 import (
+	"io/ioutil"
 	"net/http"
+	"os"
+	"path/filepath"
 
 	"code.cloudfoundry.org/credhub-cli/config"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
 	. "github.com/onsi/gomega/gexec"
 	. "github.com/onsi/gomega/ghttp"
 	"strings"
@@ -103,4 +107,82 @@ var _ = Describe("Find", func() {
 			Eventually(string(session.Out.Contents())).Should(Equal("credentials: []\n\n"))
 		})
 	})
+
+	Describe("authenticating with a static bearer token", func() {
+		BeforeEach(func() {
+			config.WriteConfig(config.Config{})
+
+			server.RouteToHandler("GET", "/info",
+				RespondWith(http.StatusOK, `{
+				"app":{"name":"CredHub"},
+				"auth-server":{"url":"`+authServer.URL()+`"}
+				}`),
+			)
+		})
+
+		It("uses the token from the environment without logging in", func() {
+			server.RouteToHandler("GET", "/api/v1/data",
+				CombineHandlers(
+					VerifyRequest("GET", "/api/v1/data"),
+					VerifyHeader(http.Header{"Authorization": []string{"Bearer some-pipeline-token"}}),
+					RespondWith(http.StatusOK, `{"credentials": []}`),
+				),
+			)
+
+			session := runCommandWithEnv([]string{"CREDHUB_CA_CERT=../test/server-and-auth-stacked-cert.pem", "CREDHUB_SERVER=" + server.URL(), "CREDHUB_TOKEN=some-pipeline-token"}, "find")
+
+			Eventually(session).Should(Exit(0))
+			Eventually(string(session.Out.Contents())).Should(Equal("credentials: []\n\n"))
+			Expect(authServer.ReceivedRequests()).To(HaveLen(0))
+		})
+
+		It("reads the token from a file", func() {
+			tokenFile, err := ioutil.TempFile("", "credhub-token")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.Remove(tokenFile.Name())
+			tokenFile.WriteString("some-pipeline-token\n")
+			tokenFile.Close()
+
+			server.RouteToHandler("GET", "/api/v1/data",
+				CombineHandlers(
+					VerifyHeader(http.Header{"Authorization": []string{"Bearer some-pipeline-token"}}),
+					RespondWith(http.StatusOK, `{"credentials": []}`),
+				),
+			)
+
+			session := runCommandWithEnv([]string{"CREDHUB_CA_CERT=../test/server-and-auth-stacked-cert.pem", "CREDHUB_SERVER=" + server.URL(), "CREDHUB_TOKEN_FILE=" + tokenFile.Name()}, "find")
+
+			Eventually(session).Should(Exit(0))
+		})
+
+		It("fails without contacting the server when the token file cannot be read", func() {
+			missingFile := filepath.Join(homeDir, "missing-token")
+
+			session := runCommandWithEnv([]string{"CREDHUB_CA_CERT=../test/server-and-auth-stacked-cert.pem", "CREDHUB_SERVER=" + server.URL(), "CREDHUB_TOKEN_FILE=" + missingFile}, "find")
+
+			Eventually(session).Should(Exit(1))
+			Eventually(session.Err).Should(Say(`The token file ".*missing-token" set in CREDHUB_TOKEN_FILE could not be read: .*\. Please update and retry your request.\n`))
+			Expect(server.ReceivedRequests()).To(HaveLen(0))
+		})
+
+		It("does not refresh the token when the server reports it has expired", func() {
+			server.RouteToHandler("GET", "/api/v1/data",
+				RespondWith(http.StatusUnauthorized, `{"error": "access_token_expired"}`),
+			)
+
+			session := runCommandWithEnv([]string{"CREDHUB_CA_CERT=../test/server-and-auth-stacked-cert.pem", "CREDHUB_SERVER=" + server.URL(), "CREDHUB_TOKEN=some-pipeline-token"}, "find")
+
+			Eventually(session).Should(Exit(1))
+			Eventually(session.Err).Should(Say("The provided bearer token has expired and cannot be refreshed. Please provide a new token and retry your request."))
+			Expect(authServer.ReceivedRequests()).To(HaveLen(0))
+		})
+
+		It("fails without contacting the server when the token has expired", func() {
+			session := runCommandWithEnv([]string{"CREDHUB_CA_CERT=../test/server-and-auth-stacked-cert.pem", "CREDHUB_SERVER=" + server.URL(), "CREDHUB_TOKEN=" + VALID_ACCESS_TOKEN}, "find")
+
+			Eventually(session).Should(Exit(1))
+			Eventually(session.Err).Should(Say("The provided bearer token has expired and cannot be refreshed. Please provide a new token and retry your request."))
+			Expect(server.ReceivedRequests()).To(HaveLen(0))
+		})
+	})
 })
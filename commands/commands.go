@@ -1,39 +1,92 @@
 package commands
 
 import (
+	"path"
+	"strings"
+
 	"code.cloudfoundry.org/credhub-cli/config"
 	"code.cloudfoundry.org/credhub-cli/credhub"
 )
 
 type CredhubCommand struct {
 	API            ApiCommand            `command:"api"        alias:"a" description:"Get or set the CredHub API target where commands are sent" long-description:"Get or set the CredHub API target where commands are sent. The api command without any flags will return the current target. If --ca-cert or --skip-tls-validation are provided, these preferences will be cached for future requests."`
-	Delete         DeleteCommand         `command:"delete"     alias:"d" description:"Delete a credential" long-description:"Delete a credential. This will delete all versions of the credential.\n\n More information: https://credhub-api.cfapps.io/#delete-credentials"`
-	Export         ExportCommand         `command:"export"     alias:"e" description:"Export all credentials" long-description:"Export all credentials.\n\n More information: https://credhub-api.cfapps.io/#export-credentials"`
+	Apply          ApplyCommand          `command:"apply"      description:"Make the credentials under a path match a desired state file" long-description:"Make the credentials under a path match a desired state file. The file has a path, resolved against the current path unless it starts with /, and a list of credentials, each with a name relative to the path, a type, either a literal value or generation parameters, and optionally permissions. A plan of the credentials to create, update, regenerate and, with --prune, delete is shown and applied after confirmation. Generated credentials are regenerated when their type, or a parameter that can be read back from the value, differs. Permissions are added but never removed."`
+	AuditRefs      AuditRefsCommand      `command:"audit-refs" description:"Compare the credentials under a path with the variables BOSH manifests refer to" long-description:"Compare the credentials under a deployment's path with the variables its BOSH manifests and runtime configs refer to. Credentials that no ((variable)) reference, variables block or ca option refers to are reported as unreferenced, and references to credentials that do not exist and are not declared in a variables block are reported as missing. Names that do not start with / are resolved under --path. Several files may be given after -m or with -m multiple times."`
+	Cd             CdCommand             `command:"cd"         description:"Change the current path that relative credential names are resolved against" long-description:"Change the current path. Credential names and paths that do not start with / are resolved against the current path, and .. refers to the parent path. The cd command without a path changes back to /. The current path is stored with the targeted API and is reset when a new API is targeted."`
+	Certificates   CertificatesCommand   `command:"certificates" description:"Inspect the certificates stored in CredHub" long-description:"Inspect the certificates stored in CredHub. The tree subcommand shows which CAs sign which certificates, with their expiry, to see which certificates bulk-regenerate would change."`
+	Copy           CopyCommand           `command:"cp"         alias:"copy" description:"Copy a credential, or every credential under a path, to a new name" long-description:"Copy a credential to a new name, or with --recursive every credential under a path to the same relative names under another path. Only the latest version is copied unless --all-versions is provided. Existing destination credentials are handled according to --mode."`
+	Delete         DeleteCommand         `command:"delete"     alias:"d" description:"Delete a credential, or every credential under a path" long-description:"Delete a credential. This will delete all versions of the credential. With --path and --recursive, every credential under the path is deleted after the path is typed to confirm, optionally filtered with --include and --exclude globs and backed up to an encrypted file first.\n\n More information: https://credhub-api.cfapps.io/#delete-credentials"`
+	Diff           DiffCommand           `command:"diff"       description:"Show the differences between two versions of a credential" long-description:"Show the field-level differences between two versions of a credential. The latest two versions are compared unless --from or --to is provided. Secret values are redacted and shown by a hash that is keyed for the run, so that a change is visible without revealing the value, unless --show-secrets is provided, and certificates are compared by subject, SANs, expiry and issuer."`
+	Duplicates     DuplicatesCommand     `command:"duplicates" description:"Find credentials under a path that share the same secret" long-description:"Find groups of credentials under a path that share secret material: the same password of password and user credentials, the same value or JSON, or the same private key of certificate, RSA and SSH credentials in any encoding. The latest versions are fetched concurrently and compared by a hash salted with a random key for each run. Credentials that cannot be fetched are reported and the others are still compared. Values and hashes are never printed."`
+	Export         ExportCommand         `command:"export"     alias:"e" description:"Export all credentials" long-description:"Export all credentials. With --format vars-store, the credentials are exported as a BOSH vars-store file, a map of names relative to --path to values in the shape BOSH generates them in.\n\n More information: https://credhub-api.cfapps.io/#export-credentials"`
 	Find           FindCommand           `command:"find"       alias:"f" description:"Find stored credential names or paths based on query parameters" long-description:"Find stored credential names or paths based on query parameters.\n\n More information: https://credhub-api.cfapps.io/#find-credentials"`
 	Generate       GenerateCommand       `command:"generate"   alias:"n" description:"Generate and set a credential value" long-description:"Set a credential with generated value(s). A type must be specified when generating a credential. The provided flags are used to set parameters for the credential that is generated, e.g. a certificate credential may use --common-name, --duration and --self-sign to generate an appropriate value. Supported credential types are prefixed in the flag description.\n\n More information: https://credhub-api.cfapps.io/#generate-credentials"`
+	GenerateVars   GenerateVarsCommand   `command:"generate-vars" description:"Generate the variables declared in a BOSH deployment manifest" long-description:"Generate the password, certificate, ssh, rsa and user variables declared in the variables block of a BOSH deployment manifest, as the director would when deploying it. Variable names and the ca option that do not start with / are stored under --prefix, e.g. /director/deployment. CAs are generated before the certificates they sign, and existing variables are only regenerated when their options have changed."`
 	Get            GetCommand            `command:"get"        alias:"g" description:"Get a credential value" long-description:"Get a credential value by name or ID.\n\n More information: https://credhub-api.cfapps.io/#get-credentials"`
-	Import         ImportCommand         `command:"import"     alias:"i" description:"Set multiple credential values" long-description:"Set multiple credential values from import file. File must be in yaml format containing a list of credentials under the key 'credentials'. Name, type and value are required for each credential in the list.\n\n More information: https://credhub-api.cfapps.io/#bulk-import"`
-	Login          LoginCommand          `command:"login"      alias:"l" description:"Authenticate with CredHub" long-description:"Authenticate with CredHub. UAA password and client credential grants are supported. If client credentials exist in the environment, authentication will be performed automatically without the need to explicitly call this command."`
+	History        HistoryCommand        `command:"history"    description:"List every version of a credential" long-description:"List every version of a credential, or of every credential under a path, with its ID, creation time, type and a non-secret fingerprint of its value. Certificates are fingerprinted by serial number and expiry, RSA and SSH keys by public key fingerprint and other values by a hash keyed for each run, so that versions with the same value can be spotted in one output but hashes cannot be compared across runs."`
+	Import         ImportCommand         `command:"import"     alias:"i" description:"Set multiple credential values" long-description:"Set multiple credential values from import file. File must be in yaml format containing a list of credentials under the key 'credentials'. Name, type and value are required for each credential in the list. With --format vars-store, the file is a BOSH vars-store file instead, and each variable is set under --path with its type inferred from the shape of its value: strings as passwords, and maps as certificates, ssh keys, rsa keys or users by their keys, or otherwise as json.\n\n More information: https://credhub-api.cfapps.io/#bulk-import"`
+	Inventory      InventoryCommand      `command:"inventory"  description:"Report the number, types and age of the credentials under a path" long-description:"Report on the credentials under a path, or under the current path if no path is provided: the number of credentials by type and by path prefix, a histogram of the age of their latest version, the number of versions of each credential, and the credentials not rotated within --older-than. Every version of each credential is fetched concurrently. The report is shown as tables, JSON, or with --prometheus as metrics for the textfile collector of the Prometheus node exporter."`
+	Lint           LintCommand           `command:"lint"       description:"Check the credentials under a path against a policy" long-description:"Check the latest version of the credentials under a path against the rules of a policy file: the minimum length and required character classes of passwords, the minimum length of RSA and SSH keys, certificates signed with SHA-1, valid for longer than a maximum or signing other certificates without being a CA, and patterns credential names must or must not match. Credentials that cannot be fetched are reported as error violations. Violations are shown as a table, JSON or JUnit XML, and the command fails if any have the error severity. The same policy file can be given to set, generate and import with --policy to check credentials before they are written."`
+	Login          LoginCommand          `command:"login"      alias:"l" description:"Authenticate with CredHub" long-description:"Authenticate with CredHub. UAA password, client credential, SSO passcode, browser and device grants are supported, as well as mutual TLS client certificates and external credential processes. If client credentials exist in the environment, authentication will be performed automatically without the need to explicitly call this command."`
+	Ls             LsCommand             `command:"ls"         description:"List the paths and credentials directly under a path" long-description:"List the paths and credentials directly under a path, or under / if no path is provided. Each path is shown with the number of credentials under it and each credential with its type."`
 	Logout         LogoutCommand         `command:"logout"     alias:"o" description:"Discard authenticated user session" long-description:"Discard authenticated session. Refresh token revocation will be attempted for password grants."`
+	Move           MoveCommand           `command:"mv"         alias:"move" description:"Move a credential, or every credential under a path, to a new name" long-description:"Move a credential to a new name, or with --recursive every credential under a path to the same relative names under another path. Each credential is copied as by the cp command and then deleted from its source. Sources whose destination is skipped are not deleted."`
+	Pwd            PwdCommand            `command:"pwd"        description:"Show the current path that relative credential names are resolved against" long-description:"Show the current path set by the cd command. Credential names and paths that do not start with / are resolved against it."`
 	Regenerate     RegenerateCommand     `command:"regenerate" alias:"r" description:"Generate and set a credential value using the same attributes as the stored value" long-description:"Set a credential with a generated value using the same attributes as the stored value.\n\n More information: https://credhub-api.cfapps.io/#regenerate-credentials"`
-	BulkRegenerate BulkRegenerateCommand `command:"bulk-regenerate" description:"Recursively regenerate all certificates signed by the provided certificate" long-description:"Recursively regenerate all certificates signed by the provided certificate\n\n More information: https://credhub-api.cfapps.io/#certificate-signed-by-a-ca"`
+	BulkRegenerate BulkRegenerateCommand `command:"bulk-regenerate" description:"Recursively regenerate all certificates signed by the provided certificate" long-description:"Recursively regenerate all certificates signed by the provided certificate. With --dry-run, the certificates that would be regenerated, those signed by it directly or indirectly through their ca_name, are shown as a tree, as by the certificates tree command, without regenerating them.\n\n More information: https://credhub-api.cfapps.io/#certificate-signed-by-a-ca"`
+	Rollback       RollbackCommand       `command:"rollback"   description:"Set a credential to the value of a previous version" long-description:"Set a credential to the value of a previous version, selected by ID or by a number of versions to go back. A new version is created with the same type and value as the selected version. You will be asked to confirm unless --no-confirm is provided."`
+	Rotate         RotateCommand         `command:"rotate"     description:"Regenerate the credentials under a path that are older than an age" long-description:"Regenerate the credentials under a path whose latest version was created longer ago than --older-than, e.g. 90d. Only password, user, ssh and rsa credentials are rotated unless --type selects others; certificates are only rotated if included. Value and json credentials are skipped. Credentials are regenerated concurrently with the same parameters they were generated with, and a report of the rotated, skipped and failed credentials is shown. Credentials whose value was set rather than generated cannot be regenerated and are skipped. With --dry-run, the credentials that would be rotated are shown without regenerating them; whether a credential was set or generated cannot be known in advance, so some of them may be skipped when rotated."`
 	Set            SetCommand            `command:"set"        alias:"s" description:"Set a credential with a provided value" long-description:"Set a credential with provided value(s). A type must be specified when setting a credential. The provided flags are used to set specific values of a credential, e.g. a certificate credential may use --root, --certificate and --private to set each value. Supported credential types are prefixed in the flag description.\n\n More information: https://credhub-api.cfapps.io/#set-credentials"`
+	Sync           SyncCommand           `command:"sync"       description:"Copy the credentials under a path from one CredHub target to another" long-description:"Copy the latest version of the credentials under a path from one CredHub target to another, optionally to a different path. Each target is a config file written by the CLI, e.g. a copy of ~/.credhub/config.json made after targeting and logging in to it. Credentials are compared by a hash of their type and value keyed for each run, and neither values nor hashes are printed. Certificates signed by a CA that is synced are set with the destination name of the CA."`
+	Tree           TreeCommand           `command:"tree"       description:"Show the paths and credentials under a path as a tree" long-description:"Show every path and credential under a path, or under / if no path is provided, as a tree. Each path is shown with the number of credentials under it broken down by type, and each credential with its type."`
 	Curl           CurlCommand           `command:"curl"       description:"Make an arbitrary request to the targeted CredHub server." long-description:"Make an arbitrary request to the targeted CredHub server"`
+	TokenCmd       TokenCommand          `command:"token"      description:"Inspect the current authentication token" long-description:"Inspect the current authentication token. Use the --token flag to print the token as a bearer authorization header."`
 
-	Version func() `long:"version" description:"Version of CLI and targeted CredHub API"`
-	Token   func() `long:"token" description:"Return your current CredHub authentication token"`
+	Output  OutputFormat `long:"output" value-name:"FORMAT" description:"Output format: yaml, json, jsonl, table, template=<go template> or jsonpath=<expression>"`
+	Version func()       `long:"version" description:"Version of CLI and targeted CredHub API"`
+	Token   func()       `long:"token" description:"Return your current CredHub authentication token"`
 }
 
 var CredHub CredhubCommand
 
 type ClientCommand struct {
 	client *credhub.CredHub
+	CurrentPathCommand
 }
 
 func (n *ClientCommand) SetClient(client *credhub.CredHub) {
 	n.client = client
 }
 
+// CurrentPathCommand is embedded by commands that take credential names or
+// paths relative to the current path set by the cd command. Such commands
+// implement ResolveNames, which is called before Execute, to make them absolute.
+type CurrentPathCommand struct {
+	currentPath string
+}
+
+func (n *CurrentPathCommand) SetCurrentPath(currentPath string) {
+	n.currentPath = currentPath
+}
+
+// resolveName returns the absolute name of a credential given relative to the
+// current path. Absolute names, and any name when no current path is set, are
+// returned unchanged.
+func (n *CurrentPathCommand) resolveName(name string) string {
+	if name == "" {
+		return ""
+	}
+	return n.resolvePath(name)
+}
+
+// resolvePath is like resolveName, but an empty path resolves to the current path
+func (n *CurrentPathCommand) resolvePath(p string) string {
+	if n.currentPath == "" || strings.HasPrefix(p, "/") {
+		return p
+	}
+	return path.Join(n.currentPath, p)
+}
+
 type ConfigCommand struct {
 	config config.Config
 }
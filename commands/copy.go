@@ -0,0 +1,229 @@
+package commands
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"code.cloudfoundry.org/credhub-cli/credhub"
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials"
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials/values"
+	"code.cloudfoundry.org/credhub-cli/errors"
+)
+
+type CopyCommand struct {
+	Source      string `short:"s" long:"source" required:"yes" description:"Name of the source credential, or the source path with --recursive"`
+	Destination string `short:"d" long:"destination" required:"yes" description:"Name of the destination credential, or the destination path with --recursive"`
+	Recursive   bool   `short:"r" long:"recursive" description:"Include every credential under the source path"`
+	AllVersions bool   `long:"all-versions" description:"Include every version, oldest first, instead of only the latest"`
+	Permissions bool   `long:"permissions" description:"Add the permissions of each source credential to its destination"`
+	Mode        string `long:"mode" default:"no-overwrite" description:"Handling of existing destination credentials: 'overwrite' always sets them, 'no-overwrite' skips them and 'converge' only sets them if the value differs"`
+	DryRun      bool   `long:"dry-run" description:"Show what would be done without making any changes"`
+	ClientCommand
+}
+
+type MoveCommand struct {
+	CopyCommand
+}
+
+type copyPlan struct {
+	source      string
+	destination string
+	exists      bool
+}
+
+func (c *CopyCommand) ResolveNames() {
+	c.Source = c.resolveName(c.Source)
+	c.Destination = c.resolveName(c.Destination)
+}
+
+func (c *CopyCommand) Execute([]string) error {
+	return c.copy(false)
+}
+
+func (c *MoveCommand) Execute([]string) error {
+	return c.copy(true)
+}
+
+func (c *CopyCommand) copy(move bool) error {
+	mode := credhub.Mode(c.Mode)
+	if mode != credhub.Overwrite && mode != credhub.NoOverwrite && mode != credhub.Converge {
+		return errors.NewInvalidModeError(c.Mode)
+	}
+
+	plans, err := c.plans()
+	if err != nil {
+		return err
+	}
+
+	verb, done := "copy", "Copied"
+	if move {
+		verb, done = "move", "Moved"
+	}
+
+	for _, plan := range plans {
+		arrow := plan.source + " -> " + plan.destination
+
+		if plan.exists && mode == credhub.NoOverwrite {
+			fmt.Println("Skipped " + arrow + ": the destination already exists")
+			continue
+		}
+
+		versions, err := c.sourceVersions(plan.source)
+		if err != nil {
+			return err
+		}
+
+		unchanged := false
+		if plan.exists && mode == credhub.Converge {
+			current, err := c.client.GetLatestVersion(plan.destination)
+			if err != nil {
+				return err
+			}
+			unchanged = sameValue(versions[len(versions)-1], current)
+		}
+
+		if c.DryRun {
+			if unchanged {
+				fmt.Println("Would leave " + plan.destination + " unchanged")
+				if move {
+					fmt.Println("Would delete " + plan.source)
+				}
+			} else {
+				fmt.Printf("Would %s %s (%d version(s))\n", verb, arrow, len(versions))
+			}
+			continue
+		}
+
+		if !unchanged {
+			for _, version := range versions {
+				value, err := values.Typed(version.Type, version.Value)
+				if err != nil {
+					return err
+				}
+				if _, err := c.client.SetCredential(plan.destination, version.Type, value); err != nil {
+					return err
+				}
+			}
+
+			if c.Permissions {
+				perms, err := c.client.GetPermissions(plan.source)
+				if err != nil {
+					return err
+				}
+				if len(perms) > 0 {
+					if _, err := c.client.AddPermissions(plan.destination, perms); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		if move {
+			if err := c.client.Delete(plan.source); err != nil {
+				return err
+			}
+		}
+
+		if unchanged {
+			fmt.Println("Unchanged " + arrow + ": the destination already has the same value")
+		} else {
+			fmt.Printf("%s %s (%d version(s))\n", done, arrow, len(versions))
+		}
+	}
+
+	return nil
+}
+
+// plans pairs each source credential with its destination name
+func (c *CopyCommand) plans() ([]copyPlan, error) {
+	if !c.Recursive {
+		destination := absoluteName(c.Destination)
+
+		existing, err := c.client.FindByPartialName(destination)
+		if err != nil {
+			return nil, err
+		}
+
+		return []copyPlan{{
+			source:      absoluteName(c.Source),
+			destination: destination,
+			exists:      containsName(existing.Credentials, destination),
+		}}, nil
+	}
+
+	sourcePath := strings.TrimSuffix(absoluteName(c.Source), "/")
+	destinationPath := strings.TrimSuffix(absoluteName(c.Destination), "/")
+
+	sources, err := c.client.FindByPath(sourcePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sources.Credentials) == 0 {
+		return nil, errors.NewNoMatchingCredentialsFoundError()
+	}
+
+	existing, err := c.client.FindByPath(destinationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var plans []copyPlan
+	for _, source := range sources.Credentials {
+		name := absoluteName(source.Name)
+		destination := destinationPath + "/" + strings.TrimPrefix(strings.TrimPrefix(name, sourcePath), "/")
+
+		plans = append(plans, copyPlan{
+			source:      name,
+			destination: destination,
+			exists:      containsName(existing.Credentials, destination),
+		})
+	}
+
+	return plans, nil
+}
+
+// sourceVersions returns the versions to copy, oldest first
+func (c *CopyCommand) sourceVersions(name string) ([]credentials.Credential, error) {
+	if !c.AllVersions {
+		latest, err := c.client.GetLatestVersion(name)
+		return []credentials.Credential{latest}, err
+	}
+
+	versions, err := c.client.GetAllVersions(name)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(versions)-1; i < j; i, j = i+1, j-1 {
+		versions[i], versions[j] = versions[j], versions[i]
+	}
+
+	return versions, nil
+}
+
+// sameValue reports whether two credentials have the same type and value, ignoring fields computed by the server
+func sameValue(a, b credentials.Credential) bool {
+	if a.Type != b.Type {
+		return false
+	}
+
+	typedA, errA := values.Typed(a.Type, a.Value)
+	typedB, errB := values.Typed(b.Type, b.Value)
+
+	return errA == nil && errB == nil && reflect.DeepEqual(typedA, typedB)
+}
+
+func absoluteName(name string) string {
+	return "/" + strings.TrimPrefix(name, "/")
+}
+
+func containsName(found []credentials.Base, name string) bool {
+	for _, credential := range found {
+		if absoluteName(credential.Name) == name {
+			return true
+		}
+	}
+	return false
+}
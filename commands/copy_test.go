@@ -0,0 +1,270 @@
+package commands_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("Copy and move", func() {
+	var (
+		lock        sync.Mutex
+		stored      map[string][]string
+		setRequests []map[string]interface{}
+		deleted     []string
+		permissions []map[string]interface{}
+	)
+
+	versionJSON := func(name, id, value string) string {
+		return `{"type":"password","id":"` + id + `","name":"` + name + `","version_created_at":"` + TIMESTAMP + `","value":"` + value + `"}`
+	}
+
+	BeforeEach(func() {
+		login()
+
+		stored = map[string][]string{
+			"/old/a":     {versionJSON("/old/a", "a2", "a-new"), versionJSON("/old/a", "a1", "a-old")},
+			"/old/sub/b": {versionJSON("/old/sub/b", "b1", "b")},
+			"/new/sub/b": {versionJSON("/new/sub/b", "nb1", "b")},
+			"/other":     {versionJSON("/other", "o1", "other")},
+		}
+		setRequests = nil
+		deleted = nil
+		permissions = nil
+
+		server.RouteToHandler("GET", "/api/v1/data", func(w http.ResponseWriter, r *http.Request) {
+			lock.Lock()
+			defer lock.Unlock()
+
+			query := r.URL.Query()
+			switch {
+			case query.Get("path") != "" || query.Get("name-like") != "":
+				prefix, nameLike := query.Get("path"), query.Get("name-like")
+				var names []string
+				for name := range stored {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+
+				found := []map[string]string{}
+				for _, name := range names {
+					if (prefix != "" && len(name) > len(prefix) && name[:len(prefix)+1] == prefix+"/") || (nameLike != "" && name == nameLike) {
+						found = append(found, map[string]string{"name": name, "version_created_at": TIMESTAMP})
+					}
+				}
+				json.NewEncoder(w).Encode(map[string]interface{}{"credentials": found})
+			case query.Get("name") != "":
+				versions, ok := stored[query.Get("name")]
+				if !ok {
+					w.WriteHeader(http.StatusNotFound)
+					w.Write([]byte(`{"error":"The request could not be completed because the credential does not exist or you do not have sufficient authorization."}`))
+					return
+				}
+				if query.Get("current") == "true" {
+					versions = versions[:1]
+				}
+				data := "["
+				for i, version := range versions {
+					if i > 0 {
+						data += ","
+					}
+					data += version
+				}
+				w.Write([]byte(`{"data":` + data + `]}`))
+			}
+		})
+
+		server.RouteToHandler("PUT", "/api/v1/data", func(w http.ResponseWriter, r *http.Request) {
+			lock.Lock()
+			defer lock.Unlock()
+
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			setRequests = append(setRequests, body)
+			w.Write([]byte(versionJSON(body["name"].(string), "some-id", "<redacted>")))
+		})
+
+		server.RouteToHandler("DELETE", "/api/v1/data", func(w http.ResponseWriter, r *http.Request) {
+			lock.Lock()
+			defer lock.Unlock()
+
+			deleted = append(deleted, r.URL.Query().Get("name"))
+			w.WriteHeader(http.StatusNoContent)
+		})
+
+		server.RouteToHandler("GET", "/api/v1/permissions", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"credential_name":"` + r.URL.Query().Get("credential_name") + `","permissions":[{"actor":"uaa-user:some-user","operations":["read"]}]}`))
+		})
+
+		server.RouteToHandler("POST", "/api/v1/permissions", func(w http.ResponseWriter, r *http.Request) {
+			lock.Lock()
+			defer lock.Unlock()
+
+			body, _ := ioutil.ReadAll(r.Body)
+			var request map[string]interface{}
+			json.Unmarshal(body, &request)
+			permissions = append(permissions, request)
+			w.WriteHeader(http.StatusCreated)
+		})
+	})
+
+	setNames := func() []string {
+		var names []string
+		for _, request := range setRequests {
+			names = append(names, request["name"].(string)+"="+request["value"].(string))
+		}
+		return names
+	}
+
+	ItRequiresAuthentication("cp", "-s", "/old/a", "-d", "/new/a")
+	ItRequiresAnAPIToBeSet("cp", "-s", "/old/a", "-d", "/new/a")
+
+	ItBehavesLikeHelp("cp", "copy", func(session *Session) {
+		Expect(session.Err).To(Say("source"))
+		Expect(session.Err).To(Say("destination"))
+		Expect(session.Err).To(Say("recursive"))
+		Expect(session.Err).To(Say("all-versions"))
+		Expect(session.Err).To(Say("permissions"))
+		Expect(session.Err).To(Say("mode"))
+		Expect(session.Err).To(Say("dry-run"))
+	})
+
+	ItBehavesLikeHelp("mv", "move", func(session *Session) {
+		Expect(session.Err).To(Say("source"))
+		Expect(session.Err).To(Say("destination"))
+	})
+
+	Describe("cp", func() {
+		It("copies the latest version of a credential", func() {
+			session := runCommand("cp", "-s", "old/a", "-d", "/new/a")
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Out).To(Say(`Copied /old/a -> /new/a \(1 version\(s\)\)`))
+			Expect(setNames()).To(Equal([]string{"/new/a=a-new"}))
+			Expect(setRequests[0]["type"]).To(Equal("password"))
+			Expect(deleted).To(BeEmpty())
+		})
+
+		It("copies every version oldest first", func() {
+			session := runCommand("cp", "-s", "/old/a", "-d", "/new/a", "--all-versions")
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Out).To(Say(`Copied /old/a -> /new/a \(2 version\(s\)\)`))
+			Expect(setNames()).To(Equal([]string{"/new/a=a-old", "/new/a=a-new"}))
+		})
+
+		It("copies every credential under a path, skipping existing destinations", func() {
+			session := runCommand("cp", "-s", "/old", "-d", "/new/", "-r")
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Out).To(Say(`Copied /old/a -> /new/a`))
+			Expect(session.Out).To(Say(`Skipped /old/sub/b -> /new/sub/b: the destination already exists`))
+			Expect(setNames()).To(Equal([]string{"/new/a=a-new"}))
+		})
+
+		It("overwrites existing destinations with --mode overwrite", func() {
+			session := runCommand("cp", "-s", "/old", "-d", "/new", "-r", "--mode", "overwrite")
+
+			Eventually(session).Should(Exit(0))
+			Expect(setNames()).To(ConsistOf("/new/a=a-new", "/new/sub/b=b"))
+		})
+
+		It("only sets existing destinations with a different value with --mode converge", func() {
+			stored["/new/a"] = []string{versionJSON("/new/a", "na1", "different")}
+
+			session := runCommand("cp", "-s", "/old", "-d", "/new", "-r", "--mode", "converge")
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Out).To(Say(`Copied /old/a -> /new/a`))
+			Expect(session.Out).To(Say(`Unchanged /old/sub/b -> /new/sub/b: the destination already has the same value`))
+			Expect(setNames()).To(Equal([]string{"/new/a=a-new"}))
+		})
+
+		It("adds the permissions of the source to the destination", func() {
+			session := runCommand("cp", "-s", "/old/a", "-d", "/new/a", "--permissions")
+
+			Eventually(session).Should(Exit(0))
+			Expect(permissions).To(HaveLen(1))
+			Expect(permissions[0]["credential_name"]).To(Equal("/new/a"))
+			Expect(permissions[0]["permissions"]).To(Equal([]interface{}{
+				map[string]interface{}{"actor": "uaa-user:some-user", "operations": []interface{}{"read"}},
+			}))
+		})
+
+		It("makes no changes with --dry-run", func() {
+			session := runCommand("cp", "-s", "/old", "-d", "/new", "-r", "--all-versions", "--dry-run")
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Out).To(Say(`Would copy /old/a -> /new/a \(2 version\(s\)\)`))
+			Expect(session.Out).To(Say(`Skipped /old/sub/b -> /new/sub/b: the destination already exists`))
+			Expect(setRequests).To(BeEmpty())
+		})
+
+		It("returns an error when no credentials exist under the source path", func() {
+			session := runCommand("cp", "-s", "/missing", "-d", "/new", "-r")
+
+			Eventually(session).Should(Exit(1))
+			Expect(session.Err).To(Say("No credentials exist which match the provided parameters."))
+		})
+
+		It("returns an error when the source does not exist", func() {
+			session := runCommand("cp", "-s", "/missing", "-d", "/new/missing")
+
+			Eventually(session).Should(Exit(1))
+			Expect(session.Err).To(Say("The request could not be completed because the credential does not exist or you do not have sufficient authorization."))
+			Expect(setRequests).To(BeEmpty())
+		})
+
+		It("returns an error for an unknown mode", func() {
+			session := runCommand("cp", "-s", "/old/a", "-d", "/new/a", "--mode", "replace")
+
+			Eventually(session).Should(Exit(1))
+			Expect(session.Err).To(Say(`The mode "replace" is not supported. Valid modes are 'overwrite', 'no-overwrite' and 'converge'.`))
+		})
+	})
+
+	Describe("mv", func() {
+		It("copies and then deletes the source", func() {
+			session := runCommand("mv", "-s", "/old/a", "-d", "/new/a", "--all-versions")
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Out).To(Say(`Moved /old/a -> /new/a \(2 version\(s\)\)`))
+			Expect(setNames()).To(Equal([]string{"/new/a=a-old", "/new/a=a-new"}))
+			Expect(deleted).To(Equal([]string{"/old/a"}))
+		})
+
+		It("does not delete sources whose destination was skipped", func() {
+			session := runCommand("mv", "-s", "/old", "-d", "/new", "-r")
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Out).To(Say(`Moved /old/a -> /new/a`))
+			Expect(session.Out).To(Say(`Skipped /old/sub/b -> /new/sub/b`))
+			Expect(deleted).To(Equal([]string{"/old/a"}))
+		})
+
+		It("deletes sources whose destination already has the same value with --mode converge", func() {
+			session := runCommand("mv", "-s", "/old/sub/b", "-d", "/new/sub/b", "--mode", "converge")
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Out).To(Say(`Unchanged /old/sub/b -> /new/sub/b`))
+			Expect(setRequests).To(BeEmpty())
+			Expect(deleted).To(Equal([]string{"/old/sub/b"}))
+		})
+
+		It("makes no changes with --dry-run", func() {
+			session := runCommand("mv", "-s", "/old/a", "-d", "/new/a", "--dry-run")
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Out).To(Say(`Would move /old/a -> /new/a \(1 version\(s\)\)`))
+			Expect(setRequests).To(BeEmpty())
+			Expect(deleted).To(BeEmpty())
+		})
+	})
+})
@@ -2,17 +2,237 @@ package commands
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials"
+	"code.cloudfoundry.org/credhub-cli/errors"
+	"code.cloudfoundry.org/credhub-cli/models"
+	"github.com/howeyc/gopass"
 )
 
 type DeleteCommand struct {
-	CredentialIdentifier string `short:"n" long:"name" required:"yes" description:"Name of the credential to delete"`
+	CredentialIdentifier string   `short:"n" long:"name" description:"Name of the credential to delete"`
+	Path                 string   `short:"p" long:"path" description:"Path of the credentials to delete, requires --recursive"`
+	Recursive            bool     `short:"r" long:"recursive" description:"Delete every credential under --path"`
+	Include              []string `long:"include" value-name:"GLOB" description:"Only delete credentials whose name, or name relative to --path, matches the glob. May be repeated."`
+	Exclude              []string `long:"exclude" value-name:"GLOB" description:"Do not delete credentials whose name, or name relative to --path, matches the glob. May be repeated."`
+	Force                bool     `long:"force" description:"Delete without asking for confirmation"`
+	DryRun               bool     `long:"dry-run" description:"List the credentials that would be deleted without deleting them"`
+	Concurrency          int      `long:"concurrency" default:"4" description:"Number of credentials to delete at the same time"`
+	Backup               string   `long:"backup" value-name:"FILE" description:"Write the values of every version of the deleted credentials to an encrypted file first. The passphrase is read from CREDHUB_BACKUP_PASSPHRASE or prompted for. The file can be restored with the import command."`
 	ClientCommand
 }
 
+func (c *DeleteCommand) ResolveNames() {
+	c.CredentialIdentifier = c.resolveName(c.CredentialIdentifier)
+	c.Path = c.resolveName(c.Path)
+}
+
 func (c *DeleteCommand) Execute([]string) error {
+	if c.Path != "" || c.Recursive {
+		return c.deletePath()
+	}
+
+	if c.CredentialIdentifier == "" {
+		return errors.NewMissingDeleteParametersError()
+	}
+
+	if c.Backup != "" {
+		if err := c.writeBackup([]string{c.CredentialIdentifier}); err != nil {
+			return err
+		}
+	}
+
 	if err := c.client.Delete(c.CredentialIdentifier); err != nil {
 		return err
 	}
 	fmt.Println("Credential successfully deleted")
 	return nil
 }
+
+func (c *DeleteCommand) deletePath() error {
+	if c.CredentialIdentifier != "" || c.Path == "" || !c.Recursive {
+		return errors.NewRecursiveDeleteParametersError()
+	}
+
+	names, err := c.matchingNames()
+	if err != nil {
+		return err
+	}
+
+	if len(names) == 0 {
+		return errors.NewNoMatchingCredentialsFoundError()
+	}
+
+	fmt.Printf("The following %d credential(s) will be deleted:\n", len(names))
+	for _, name := range names {
+		fmt.Println("  " + name)
+	}
+
+	if c.DryRun {
+		return nil
+	}
+
+	if !c.Force {
+		var confirmation string
+		fmt.Println()
+		promptForInput("Type the path to confirm: ", &confirmation)
+		if confirmation == "" || path.Clean(absoluteName(c.resolveName(confirmation))) != path.Clean(absoluteName(c.Path)) {
+			return errors.NewDeleteCancelledError()
+		}
+	}
+
+	if c.Backup != "" {
+		if err := c.writeBackup(names); err != nil {
+			return err
+		}
+		fmt.Println("Backup written to " + c.Backup)
+	}
+
+	failures := c.deleteAll(names)
+
+	fmt.Printf("Deleted %d of %d credential(s)\n", len(names)-len(failures), len(names))
+
+	if len(failures) == 0 {
+		return nil
+	}
+
+	for _, name := range names {
+		if err, ok := failures[name]; ok {
+			fmt.Printf(" - %s: %s\n", name, err)
+		}
+	}
+
+	return errors.NewDeleteFailuresError(len(failures))
+}
+
+// matchingNames returns the names of the credentials under the path that match the include and exclude globs
+func (c *DeleteCommand) matchingNames() ([]string, error) {
+	if err := validateGlobs(c.Include, c.Exclude); err != nil {
+		return nil, err
+	}
+
+	prefix := strings.TrimSuffix(absoluteName(c.Path), "/") + "/"
+
+	results, err := c.client.FindByPath(path.Clean(prefix))
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, credential := range results.Credentials {
+		relative := strings.TrimPrefix(absoluteName(credential.Name), prefix)
+
+		if len(c.Include) > 0 && !matchesAny(c.Include, credential.Name, relative) {
+			continue
+		}
+		if matchesAny(c.Exclude, credential.Name, relative) {
+			continue
+		}
+
+		names = append(names, credential.Name)
+	}
+
+	return names, nil
+}
+
+func validateGlobs(patterns ...[]string) error {
+	for _, list := range patterns {
+		for _, pattern := range list {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return errors.NewInvalidGlobError(pattern)
+			}
+		}
+	}
+	return nil
+}
+
+func matchesAny(patterns []string, names ...string) bool {
+	for _, pattern := range patterns {
+		for _, name := range names {
+			if matched, _ := path.Match(pattern, name); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// deleteAll deletes the credentials concurrently and returns the errors of those that could not be deleted
+func (c *DeleteCommand) deleteAll(names []string) map[string]error {
+	errs := make([]error, len(names))
+
+	forEachConcurrently(len(names), c.Concurrency, func(index int) {
+		errs[index] = c.client.Delete(names[index])
+	})
+
+	failures := map[string]error{}
+	for i, err := range errs {
+		if err != nil {
+			failures[names[i]] = err
+		}
+	}
+
+	return failures
+}
+
+// writeBackup writes every version of the credentials, oldest first, to an encrypted import file
+func (c *DeleteCommand) writeBackup(names []string) error {
+	var all []credentials.Credential
+
+	for _, name := range names {
+		versions, err := c.client.GetAllVersions(name)
+		if err != nil {
+			return err
+		}
+		for i := len(versions) - 1; i >= 0; i-- {
+			all = append(all, versions[i])
+		}
+	}
+
+	export, err := models.ExportCredentials(all)
+	if err != nil {
+		return err
+	}
+
+	passphrase, err := readBackupPassphrase(true)
+	if err != nil {
+		return err
+	}
+
+	backup, err := models.EncryptBackup(export.Bytes, passphrase)
+	if err != nil {
+		return errors.NewBackupError(err)
+	}
+
+	if err := ioutil.WriteFile(c.Backup, backup, 0600); err != nil {
+		return errors.NewBackupError(err)
+	}
+
+	return nil
+}
+
+// readBackupPassphrase returns CREDHUB_BACKUP_PASSPHRASE, or prompts for the
+// passphrase if it is not set. With confirm, the passphrase is prompted for
+// twice and an error is returned if the two do not match.
+func readBackupPassphrase(confirm bool) (string, error) {
+	if passphrase, ok := os.LookupEnv("CREDHUB_BACKUP_PASSPHRASE"); ok {
+		return passphrase, nil
+	}
+
+	fmt.Printf("backup passphrase: ")
+	passphrase, _ := gopass.GetPasswdMasked()
+
+	if confirm {
+		fmt.Printf("confirm backup passphrase: ")
+		confirmation, _ := gopass.GetPasswdMasked()
+		if string(confirmation) != string(passphrase) {
+			return "", errors.NewBackupPassphraseMismatchError()
+		}
+	}
+
+	return string(passphrase), nil
+}
@@ -1,11 +1,17 @@
 package commands_test
 
 import (
+	"io/ioutil"
 	"net/http"
-
-	"runtime"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
 
 	"code.cloudfoundry.org/credhub-cli/config"
+	"code.cloudfoundry.org/credhub-cli/models"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	. "github.com/onsi/gomega/gbytes"
@@ -59,12 +65,191 @@ var _ = Describe("Delete", func() {
 			session := runCommand("delete")
 
 			Eventually(session).Should(Exit(1))
+			Expect(session.Err).To(Say("A name or path must be provided. Please update and retry your request."))
+		})
+	})
+
+	Describe("recursive delete by path", func() {
+		var (
+			lock    sync.Mutex
+			deleted []string
+		)
+
+		BeforeEach(func() {
+			deleted = nil
+
+			server.RouteToHandler("GET", "/api/v1/data", func(w http.ResponseWriter, r *http.Request) {
+				query := r.URL.Query()
+				switch {
+				case query.Get("path") == "/deployment":
+					w.Write([]byte(`{"credentials":[` +
+						`{"name":"/deployment/db/password","version_created_at":"` + TIMESTAMP + `"},` +
+						`{"name":"/deployment/db/certificate","version_created_at":"` + TIMESTAMP + `"},` +
+						`{"name":"/deployment/api/password","version_created_at":"` + TIMESTAMP + `"},` +
+						`{"name":"/deployment/fail","version_created_at":"` + TIMESTAMP + `"}]}`))
+				case query.Get("path") != "":
+					w.Write([]byte(`{"credentials":[]}`))
+				case query.Get("name") != "":
+					name := query.Get("name")
+					w.Write([]byte(`{"data":[` +
+						`{"type":"password","id":"2","name":"` + name + `","version_created_at":"` + TIMESTAMP + `","value":"new-` + filepath.Base(name) + `"},` +
+						`{"type":"password","id":"1","name":"` + name + `","version_created_at":"` + TIMESTAMP + `","value":"old-` + filepath.Base(name) + `"}]}`))
+				}
+			})
+
+			server.RouteToHandler("DELETE", "/api/v1/data", func(w http.ResponseWriter, r *http.Request) {
+				name := r.URL.Query().Get("name")
+				if name == "/deployment/fail" {
+					w.WriteHeader(http.StatusForbidden)
+					w.Write([]byte(`{"error":"The request could not be completed because the credential does not exist or you do not have sufficient authorization."}`))
+					return
+				}
+
+				lock.Lock()
+				deleted = append(deleted, name)
+				lock.Unlock()
+				w.WriteHeader(http.StatusNoContent)
+			})
+		})
+
+		deletedNames := func() []string {
+			lock.Lock()
+			defer lock.Unlock()
+			names := append([]string{}, deleted...)
+			sort.Strings(names)
+			return names
+		}
+
+		It("lists the credentials and deletes them after the path is typed to confirm", func() {
+			session := runCommandWithStdin(strings.NewReader("/deployment\n"), "delete", "-p", "/deployment", "-r", "--exclude", "fail")
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Out).To(Say(`The following 3 credential\(s\) will be deleted:`))
+			Expect(session.Out).To(Say(`  /deployment/db/password\n  /deployment/db/certificate\n  /deployment/api/password\n`))
+			Expect(session.Out).To(Say(`Type the path to confirm: `))
+			Expect(session.Out).To(Say(`Deleted 3 of 3 credential\(s\)`))
+			Expect(deletedNames()).To(Equal([]string{"/deployment/api/password", "/deployment/db/certificate", "/deployment/db/password"}))
+		})
+
+		It("does not delete anything when the path is not confirmed", func() {
+			session := runCommandWithStdin(strings.NewReader("yes\n"), "delete", "-p", "/deployment", "-r")
+
+			Eventually(session).Should(Exit(1))
+			Expect(session.Err).To(Say("The path was not confirmed. No credentials were deleted."))
+			Expect(deletedNames()).To(BeEmpty())
+		})
+
+		It("does not ask for confirmation with --force", func() {
+			session := runCommand("delete", "-p", "/deployment/", "-r", "--force", "--include", "db/*", "--concurrency", "1")
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Out).NotTo(Say("Type the path"))
+			Expect(deletedNames()).To(Equal([]string{"/deployment/db/certificate", "/deployment/db/password"}))
+		})
+
+		It("filters with include and exclude globs on full or relative names", func() {
+			session := runCommand("delete", "-p", "/deployment", "-r", "--force", "--include", "*/password", "--exclude", "/deployment/api/*")
+
+			Eventually(session).Should(Exit(0))
+			Expect(deletedNames()).To(Equal([]string{"/deployment/db/password"}))
+		})
+
+		It("only lists the credentials with --dry-run", func() {
+			session := runCommand("delete", "-p", "/deployment", "-r", "--dry-run")
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Out).To(Say(`The following 4 credential\(s\) will be deleted:`))
+			Expect(deletedNames()).To(BeEmpty())
+		})
+
+		It("deletes the rest and summarises the errors when some credentials cannot be deleted", func() {
+			session := runCommand("delete", "-p", "/deployment", "-r", "--force")
+
+			Eventually(session).Should(Exit(1))
+			Expect(session.Out).To(Say(`Deleted 3 of 4 credential\(s\)`))
+			Expect(session.Out).To(Say(` - /deployment/fail: The request could not be completed because the credential does not exist or you do not have sufficient authorization.`))
+			Expect(session.Err).To(Say(`1 credential\(s\) could not be deleted.`))
+			Expect(deletedNames()).To(HaveLen(3))
+		})
 
-			if runtime.GOOS == "windows" {
-				Expect(session.Err).To(Say("the required flag `/n, /name' was not specified"))
-			} else {
-				Expect(session.Err).To(Say("the required flag `-n, --name' was not specified"))
-			}
+		It("writes an encrypted backup of every version before deleting", func() {
+			dir, err := ioutil.TempDir("", "credhub-delete-backup")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(dir)
+			backupFile := filepath.Join(dir, "backup.json")
+
+			session := runCommandWithEnv([]string{"CREDHUB_BACKUP_PASSPHRASE=some-passphrase"}, "delete", "-p", "/deployment", "-r", "--force", "--include", "db/password", "--backup", backupFile)
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Out).To(Say("Backup written to %s", regexp.QuoteMeta(backupFile)))
+
+			info, err := os.Stat(backupFile)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+
+			encrypted, _ := ioutil.ReadFile(backupFile)
+			Expect(string(encrypted)).NotTo(ContainSubstring("new-password"))
+
+			decrypted, err := models.DecryptBackup(encrypted, "some-passphrase")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(decrypted)).To(Equal("credentials:\n" +
+				"- name: /deployment/db/password\n  type: password\n  value: old-password\n" +
+				"- name: /deployment/db/password\n  type: password\n  value: new-password\n"))
+		})
+
+		It("prompts for the backup passphrase twice", func() {
+			dir, err := ioutil.TempDir("", "credhub-delete-backup")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(dir)
+			backupFile := filepath.Join(dir, "backup.json")
+
+			session := runCommandWithStdin(strings.NewReader("some-passphrase\nsome-passphrase\n"), "delete", "-p", "/deployment", "-r", "--force", "--include", "db/password", "--backup", backupFile)
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Out).To(Say("backup passphrase: "))
+			Expect(session.Out).To(Say("confirm backup passphrase: "))
+
+			encrypted, _ := ioutil.ReadFile(backupFile)
+			_, err = models.DecryptBackup(encrypted, "some-passphrase")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("does not delete anything when the backup passphrases do not match", func() {
+			session := runCommandWithStdin(strings.NewReader("some-passphrase\nsome-typo\n"), "delete", "-p", "/deployment", "-r", "--force", "--backup", "/tmp/some-backup")
+
+			Eventually(session).Should(Exit(1))
+			Expect(session.Err).To(Say("The backup passphrases do not match. No credentials were deleted."))
+			Expect(deletedNames()).To(BeEmpty())
+		})
+
+		It("does not delete anything when the backup cannot be written", func() {
+			session := runCommandWithEnv([]string{"CREDHUB_BACKUP_PASSPHRASE="}, "delete", "-p", "/deployment", "-r", "--force", "--backup", "/tmp/some-backup")
+
+			Eventually(session).Should(Exit(1))
+			Expect(session.Err).To(Say("The backup could not be written: a passphrase is required to encrypt a backup. No credentials were deleted."))
+			Expect(deletedNames()).To(BeEmpty())
+		})
+
+		It("returns an error when no credentials match", func() {
+			session := runCommand("delete", "-p", "/empty", "-r", "--force")
+
+			Eventually(session).Should(Exit(1))
+			Expect(session.Err).To(Say("No credentials exist which match the provided parameters."))
+		})
+
+		It("requires --recursive with --path", func() {
+			session := runCommand("delete", "-p", "/deployment")
+
+			Eventually(session).Should(Exit(1))
+			Expect(session.Err).To(Say("Deleting by path requires both --path and --recursive, and cannot be combined with --name."))
+			Expect(server.ReceivedRequests()).To(BeEmpty())
+		})
+
+		It("returns an error for an invalid glob", func() {
+			session := runCommand("delete", "-p", "/deployment", "-r", "--include", "[")
+
+			Eventually(session).Should(Exit(1))
+			Expect(session.Err).To(Say(`The glob "\[" is not valid.`))
 		})
 	})
 })
@@ -0,0 +1,251 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials"
+	"code.cloudfoundry.org/credhub-cli/errors"
+)
+
+type DiffCommand struct {
+	CredentialIdentifier string `required:"yes" short:"n" long:"name" description:"Name of the credential to compare"`
+	From                 string `long:"from" description:"ID of the version to compare from (default: the version before --to)"`
+	To                   string `long:"to" description:"ID of the version to compare to (default: the latest version)"`
+	ShowSecrets          bool   `long:"show-secrets" description:"Show secret values instead of redacting them and showing their hashes"`
+	ClientCommand
+}
+
+type credentialDiff struct {
+	Name    string        `json:"name" yaml:"name"`
+	From    diffVersion   `json:"from" yaml:"from"`
+	To      diffVersion   `json:"to" yaml:"to"`
+	Changes []fieldChange `json:"changes" yaml:"changes"`
+}
+
+type diffVersion struct {
+	Id               string `json:"id" yaml:"id"`
+	VersionCreatedAt string `json:"version_created_at" yaml:"version_created_at"`
+}
+
+type fieldChange struct {
+	Field  string `json:"field" yaml:"field"`
+	Change string `json:"change" yaml:"change"`
+	From   string `json:"from,omitempty" yaml:"from,omitempty"`
+	To     string `json:"to,omitempty" yaml:"to,omitempty"`
+}
+
+// publicFields are the credential value fields that are shown in diffs without --show-secrets
+var publicFields = map[string]bool{
+	"username":               true,
+	"public_key":             true,
+	"public_key_fingerprint": true,
+	"certificate":            true,
+	"ca":                     true,
+	"ca_name":                true,
+}
+
+func (c *DiffCommand) ResolveNames() {
+	c.CredentialIdentifier = c.resolveName(c.CredentialIdentifier)
+}
+
+func (c *DiffCommand) Execute([]string) error {
+	from, to, err := c.versions()
+	if err != nil {
+		return err
+	}
+
+	var hasher *valueHasher
+	if !c.ShowSecrets {
+		if hasher, err = newValueHasher(); err != nil {
+			return err
+		}
+	}
+
+	diff := credentialDiff{
+		Name:    to.Name,
+		From:    diffVersion{Id: from.Id, VersionCreatedAt: from.VersionCreatedAt},
+		To:      diffVersion{Id: to.Id, VersionCreatedAt: to.VersionCreatedAt},
+		Changes: diffCredentials(from, to, hasher),
+	}
+
+	if structuredOutput(false) {
+		return printCredential(false, diff)
+	}
+
+	fmt.Println("name: " + diff.Name)
+	fmt.Println("from: " + diff.From.Id + " (" + diff.From.VersionCreatedAt + ")")
+	fmt.Println("to:   " + diff.To.Id + " (" + diff.To.VersionCreatedAt + ")")
+	fmt.Println()
+
+	if len(diff.Changes) == 0 {
+		fmt.Println("No differences found.")
+		return nil
+	}
+
+	for _, change := range diff.Changes {
+		switch change.Change {
+		case "added":
+			fmt.Println("+ " + change.Field + ": " + escapeNewlines(change.To))
+		case "removed":
+			fmt.Println("- " + change.Field + ": " + escapeNewlines(change.From))
+		default:
+			fmt.Println("~ " + change.Field + ": " + escapeNewlines(change.From) + " -> " + escapeNewlines(change.To))
+		}
+	}
+
+	return nil
+}
+
+// versions returns the two versions to compare, oldest first
+func (c *DiffCommand) versions() (credentials.Credential, credentials.Credential, error) {
+	var from, to credentials.Credential
+
+	if c.From == "" && c.To == "" {
+		versions, err := c.client.GetNVersions(c.CredentialIdentifier, 2)
+		if err != nil {
+			return from, to, err
+		}
+		if len(versions) < 2 {
+			return from, to, errors.NewDiffSingleVersionError(c.CredentialIdentifier)
+		}
+		return versions[1], versions[0], nil
+	}
+
+	var err error
+
+	if c.To != "" {
+		to, err = c.version(c.To)
+	} else {
+		to, err = c.client.GetLatestVersion(c.CredentialIdentifier)
+	}
+	if err != nil {
+		return from, to, err
+	}
+
+	if c.From != "" {
+		from, err = c.version(c.From)
+		return from, to, err
+	}
+
+	versions, err := c.client.GetAllVersions(c.CredentialIdentifier)
+	if err != nil {
+		return from, to, err
+	}
+	for i, version := range versions {
+		if version.Id == to.Id && i+1 < len(versions) {
+			return versions[i+1], to, nil
+		}
+	}
+
+	return from, to, errors.NewDiffNoEarlierVersionError(to.Id)
+}
+
+func (c *DiffCommand) version(id string) (credentials.Credential, error) {
+	credential, err := c.client.GetById(id)
+	if err != nil {
+		return credential, err
+	}
+
+	if strings.TrimPrefix(credential.Name, "/") != strings.TrimPrefix(c.CredentialIdentifier, "/") {
+		return credential, errors.NewVersionNameMismatchError(id, c.CredentialIdentifier)
+	}
+
+	return credential, nil
+}
+
+// diffCredentials returns the field-level changes between two credential versions, sorted by field
+//
+// Certificates are compared by subject, issuer, SANs, expiry and fingerprint. Secret fields are
+// redacted and shown by their keyed hash, unless hasher is nil.
+func diffCredentials(from, to credentials.Credential, hasher *valueHasher) []fieldChange {
+	fromFields := credentialFields(from)
+	toFields := credentialFields(to)
+
+	var names []string
+	for name := range fromFields {
+		names = append(names, name)
+	}
+	for name := range toFields {
+		if _, ok := fromFields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	changes := []fieldChange{}
+	for _, name := range names {
+		fromValue, inFrom := fromFields[name]
+		toValue, inTo := toFields[name]
+
+		display := func(value string) string {
+			if hasher == nil || !(isSecretField(from.Type, name) || isSecretField(to.Type, name)) {
+				return value
+			}
+			return "<redacted " + hasher.hash(value) + ">"
+		}
+
+		switch {
+		case !inFrom:
+			changes = append(changes, fieldChange{Field: name, Change: "added", To: display(toValue)})
+		case !inTo:
+			changes = append(changes, fieldChange{Field: name, Change: "removed", From: display(fromValue)})
+		case fromValue != toValue:
+			changes = append(changes, fieldChange{Field: name, Change: "modified", From: display(fromValue), To: display(toValue)})
+		}
+	}
+
+	return changes
+}
+
+// credentialFields flattens a credential into its comparable fields
+func credentialFields(credential credentials.Credential) map[string]string {
+	fields := map[string]string{"type": credential.Type}
+
+	value, ok := credential.Value.(map[string]interface{})
+	if !ok {
+		fields["value"] = formatScalar(credential.Value)
+		return fields
+	}
+
+	addValueFields(fields, credential.Type, "", value)
+
+	return fields
+}
+
+func addValueFields(fields map[string]string, credentialType, prefix string, value map[string]interface{}) {
+	for key, v := range value {
+		name := prefix + key
+
+		if nested, ok := v.(map[string]interface{}); ok && credentialType == "json" {
+			addValueFields(fields, credentialType, name+".", nested)
+			continue
+		}
+
+		if s, ok := v.(string); ok && (key == "certificate" || key == "ca") && credentialType == "certificate" {
+			if info, err := parseCertificateInfo(s); err == nil {
+				for field, summary := range info.fields() {
+					fields[name+"."+field] = formatScalar(summary)
+				}
+				continue
+			}
+		}
+
+		fields[name] = formatScalar(v)
+	}
+}
+
+func isSecretField(credentialType, field string) bool {
+	if field == "type" {
+		return false
+	}
+	if credentialType == "json" {
+		return true
+	}
+	return !publicFields[strings.SplitN(field, ".", 2)[0]]
+}
+
+func escapeNewlines(s string) string {
+	return strings.Replace(s, "\n", `\n`, -1)
+}
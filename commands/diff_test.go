@@ -0,0 +1,297 @@
+package commands_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"regexp"
+	"runtime"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+	. "github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Diff", func() {
+	BeforeEach(func() {
+		login()
+	})
+
+	ItRequiresAuthentication("diff", "-n", "test-credential")
+	ItRequiresAnAPIToBeSet("diff", "-n", "test-credential")
+	ItAutomaticallyLogsIn("GET", "diff_response.json", "/api/v1/data", "diff", "-n", "test-credential")
+
+	ItBehavesLikeHelp("diff", "diff", func(session *Session) {
+		Expect(session.Err).To(Say("diff"))
+		Expect(session.Err).To(Say("name"))
+		Expect(session.Err).To(Say("from"))
+		Expect(session.Err).To(Say("to"))
+	})
+
+	It("displays missing required parameter", func() {
+		session := runCommand("diff")
+
+		Eventually(session).Should(Exit(1))
+
+		if runtime.GOOS == "windows" {
+			Expect(session.Err).To(Say("the required flag `/n, /name' was not specified"))
+		} else {
+			Expect(session.Err).To(Say("the required flag `-n, --name' was not specified"))
+		}
+	})
+
+	versionJSON := func(id, credentialType, value string) string {
+		return `{"type":"` + credentialType + `","id":"` + id + `","name":"/my-credential","version_created_at":"` + TIMESTAMP + `","value":` + value + `}`
+	}
+
+	Context("comparing the latest two versions", func() {
+		respondWithVersions := func(newVersion, oldVersion string) {
+			server.RouteToHandler("GET", "/api/v1/data",
+				CombineHandlers(
+					VerifyRequest("GET", "/api/v1/data", "name=my-credential&versions=2"),
+					RespondWith(http.StatusOK, `{"data":[`+newVersion+`,`+oldVersion+`]}`),
+				),
+			)
+		}
+
+		It("redacts secret values and shows their hashes", func() {
+			respondWithVersions(versionJSON("new-id", "password", `"new-password"`), versionJSON("old-id", "password", `"old-password"`))
+
+			session := runCommand("diff", "-n", "my-credential")
+
+			Eventually(session).Should(Exit(0))
+			output := string(session.Out.Contents())
+			Expect(output).To(MatchRegexp(
+				"^name: /my-credential\n" +
+					"from: old-id \\(" + TIMESTAMP + "\\)\n" +
+					"to:   new-id \\(" + TIMESTAMP + "\\)\n" +
+					"\n" +
+					"~ value: <redacted hmac:[0-9a-f]{12}> -> <redacted hmac:[0-9a-f]{12}>\n$"))
+			hashes := regexp.MustCompile(`hmac:[0-9a-f]{12}`).FindAllString(output, -1)
+			Expect(hashes[0]).NotTo(Equal(hashes[1]))
+			Expect(string(session.Out.Contents())).NotTo(ContainSubstring("new-password"))
+			Expect(string(session.Out.Contents())).NotTo(ContainSubstring(shortHash("new-password")))
+		})
+
+		It("shows secret values with --show-secrets", func() {
+			respondWithVersions(versionJSON("new-id", "password", `"new-password"`), versionJSON("old-id", "password", `"old-password"`))
+
+			session := runCommand("diff", "-n", "my-credential", "--show-secrets")
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Out).To(Say("~ value: old-password -> new-password\n"))
+		})
+
+		It("shows added, removed and modified fields of a user", func() {
+			respondWithVersions(
+				versionJSON("new-id", "user", `{"username":"new-user","password":"some-password"}`),
+				versionJSON("old-id", "user", `{"username":"old-user","password":"some-password","password_hash":"some-hash"}`))
+
+			session := runCommand("diff", "-n", "my-credential")
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Out).To(Say(`- password_hash: <redacted hmac:[0-9a-f]{12}>\n`))
+			Expect(session.Out).To(Say("~ username: old-user -> new-user\n"))
+			Expect(string(session.Out.Contents())).NotTo(ContainSubstring("~ password:"))
+		})
+
+		It("compares nested fields of a json credential", func() {
+			respondWithVersions(
+				versionJSON("new-id", "json", `{"db":{"host":"10.0.0.2","port":5432},"added":true}`),
+				versionJSON("old-id", "json", `{"db":{"host":"10.0.0.1","port":5432}}`))
+
+			session := runCommand("diff", "-n", "my-credential", "--show-secrets")
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Out).To(Say("\\+ added: true\n"))
+			Expect(session.Out).To(Say("~ db.host: 10.0.0.1 -> 10.0.0.2\n"))
+			Expect(string(session.Out.Contents())).NotTo(ContainSubstring("db.port"))
+		})
+
+		It("compares certificates by subject, SANs, expiry and issuer", func() {
+			oldNotAfter := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+			newNotAfter := time.Date(2031, 1, 1, 0, 0, 0, 0, time.UTC)
+			oldCert := selfSignedCertificate("old.example.com", []string{"old.example.com"}, oldNotAfter)
+			newCert := selfSignedCertificate("new.example.com", []string{"new.example.com", "www.example.com"}, newNotAfter)
+
+			certificateValue := func(cert, privateKey string) string {
+				value, _ := json.Marshal(map[string]string{"certificate": cert, "private_key": privateKey})
+				return string(value)
+			}
+			respondWithVersions(
+				versionJSON("new-id", "certificate", certificateValue(newCert, "new-private-key")),
+				versionJSON("old-id", "certificate", certificateValue(oldCert, "old-private-key")))
+
+			session := runCommand("diff", "-n", "my-credential")
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Out).To(Say("~ certificate.expires: 2030-01-01T00:00:00Z -> 2031-01-01T00:00:00Z\n"))
+			Expect(session.Out).To(Say("~ certificate.fingerprint: [0-9a-f]{64} -> [0-9a-f]{64}\n"))
+			Expect(session.Out).To(Say("~ certificate.issuer: CN=old.example.com -> CN=new.example.com\n"))
+			Expect(session.Out).To(Say("~ certificate.sans: old.example.com -> new.example.com, www.example.com\n"))
+			Expect(session.Out).To(Say("~ certificate.subject: CN=old.example.com -> CN=new.example.com\n"))
+			Expect(session.Out).To(Say(`~ private_key: <redacted hmac:[0-9a-f]{12}> -> <redacted hmac:[0-9a-f]{12}>\n`))
+			Expect(string(session.Out.Contents())).NotTo(ContainSubstring("BEGIN"))
+		})
+
+		It("reports when there are no differences", func() {
+			respondWithVersions(versionJSON("new-id", "value", `"same"`), versionJSON("old-id", "value", `"same"`))
+
+			session := runCommand("diff", "-n", "my-credential")
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Out).To(Say("No differences found."))
+		})
+
+		It("returns the changes in JSON format", func() {
+			respondWithVersions(versionJSON("new-id", "value", `"new-value"`), versionJSON("old-id", "password", `"old-value"`))
+
+			session := runCommand("diff", "-n", "my-credential", "--output", "json")
+
+			Eventually(session).Should(Exit(0))
+			var diff struct {
+				Name    string              `json:"name"`
+				From    map[string]string   `json:"from"`
+				To      map[string]string   `json:"to"`
+				Changes []map[string]string `json:"changes"`
+			}
+			Expect(json.Unmarshal(session.Out.Contents(), &diff)).To(Succeed())
+			Expect(diff.Name).To(Equal("/my-credential"))
+			Expect(diff.From).To(Equal(map[string]string{"id": "old-id", "version_created_at": TIMESTAMP}))
+			Expect(diff.To).To(Equal(map[string]string{"id": "new-id", "version_created_at": TIMESTAMP}))
+			Expect(diff.Changes).To(HaveLen(2))
+			Expect(diff.Changes[0]).To(Equal(map[string]string{"field": "type", "change": "modified", "from": "password", "to": "value"}))
+			Expect(diff.Changes[1]["field"]).To(Equal("value"))
+			Expect(diff.Changes[1]["from"]).To(MatchRegexp(`^<redacted hmac:[0-9a-f]{12}>$`))
+			Expect(diff.Changes[1]["to"]).To(MatchRegexp(`^<redacted hmac:[0-9a-f]{12}>$`))
+		})
+
+		It("returns an error when the credential has only one version", func() {
+			server.RouteToHandler("GET", "/api/v1/data",
+				CombineHandlers(
+					VerifyRequest("GET", "/api/v1/data", "name=my-credential&versions=2"),
+					RespondWith(http.StatusOK, `{"data":[`+versionJSON("new-id", "value", `"value"`)+`]}`),
+				),
+			)
+
+			session := runCommand("diff", "-n", "my-credential")
+
+			Eventually(session).Should(Exit(1))
+			Expect(session.Err).To(Say(`The credential "my-credential" has only one version. At least two versions are required to show a diff.`))
+		})
+	})
+
+	Context("comparing specific versions", func() {
+		respondWithVersion := func(id, credentialJSON string) {
+			server.RouteToHandler("GET", "/api/v1/data/"+id,
+				CombineHandlers(
+					VerifyRequest("GET", "/api/v1/data/"+id),
+					RespondWith(http.StatusOK, credentialJSON),
+				),
+			)
+		}
+
+		It("compares the versions given by --from and --to", func() {
+			respondWithVersion("first-id", versionJSON("first-id", "value", `"first"`))
+			respondWithVersion("second-id", versionJSON("second-id", "value", `"second"`))
+
+			session := runCommand("diff", "-n", "/my-credential", "--from", "first-id", "--to", "second-id", "--show-secrets")
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Out).To(Say("from: first-id"))
+			Expect(session.Out).To(Say("to:   second-id"))
+			Expect(session.Out).To(Say("~ value: first -> second\n"))
+		})
+
+		It("compares --from with the latest version", func() {
+			respondWithVersion("first-id", versionJSON("first-id", "value", `"first"`))
+			server.RouteToHandler("GET", "/api/v1/data",
+				CombineHandlers(
+					VerifyRequest("GET", "/api/v1/data", "current=true&name=my-credential"),
+					RespondWith(http.StatusOK, `{"data":[`+versionJSON("latest-id", "value", `"latest"`)+`]}`),
+				),
+			)
+
+			session := runCommand("diff", "-n", "my-credential", "--from", "first-id", "--show-secrets")
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Out).To(Say("~ value: first -> latest\n"))
+		})
+
+		It("compares --to with the version before it", func() {
+			respondWithVersion("second-id", versionJSON("second-id", "value", `"second"`))
+			server.RouteToHandler("GET", "/api/v1/data",
+				CombineHandlers(
+					VerifyRequest("GET", "/api/v1/data", "name=my-credential"),
+					RespondWith(http.StatusOK, `{"data":[`+
+						versionJSON("third-id", "value", `"third"`)+`,`+
+						versionJSON("second-id", "value", `"second"`)+`,`+
+						versionJSON("first-id", "value", `"first"`)+`]}`),
+				),
+			)
+
+			session := runCommand("diff", "-n", "my-credential", "--to", "second-id", "--show-secrets")
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Out).To(Say("~ value: first -> second\n"))
+		})
+
+		It("returns an error when --to is the earliest version", func() {
+			respondWithVersion("first-id", versionJSON("first-id", "value", `"first"`))
+			server.RouteToHandler("GET", "/api/v1/data",
+				CombineHandlers(
+					VerifyRequest("GET", "/api/v1/data", "name=my-credential"),
+					RespondWith(http.StatusOK, `{"data":[`+versionJSON("first-id", "value", `"first"`)+`]}`),
+				),
+			)
+
+			session := runCommand("diff", "-n", "my-credential", "--to", "first-id")
+
+			Eventually(session).Should(Exit(1))
+			Expect(session.Err).To(Say(`The version "first-id" is the earliest version of the credential.`))
+		})
+
+		It("returns an error when a version belongs to another credential", func() {
+			respondWithVersion("first-id", `{"type":"value","id":"first-id","name":"/other-credential","version_created_at":"`+TIMESTAMP+`","value":"first"}`)
+			respondWithVersion("second-id", versionJSON("second-id", "value", `"second"`))
+
+			session := runCommand("diff", "-n", "my-credential", "--from", "first-id", "--to", "second-id")
+
+			Eventually(session).Should(Exit(1))
+			Expect(session.Err).To(Say(`The version "first-id" does not belong to the credential "my-credential".`))
+		})
+	})
+})
+
+func shortHash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+func selfSignedCertificate(commonName string, dnsNames []string, notAfter time.Time) string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	Expect(err).NotTo(HaveOccurred())
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     dnsNames,
+		NotBefore:    notAfter.AddDate(-1, 0, 0),
+		NotAfter:     notAfter,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).NotTo(HaveOccurred())
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
@@ -0,0 +1,228 @@
+package commands
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials"
+	"code.cloudfoundry.org/credhub-cli/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+type DuplicatesCommand struct {
+	Path        string `short:"p" long:"path" required:"yes" description:"Path of the credentials to compare"`
+	Concurrency int    `long:"concurrency" default:"4" description:"Number of credentials to fetch at the same time"`
+	ClientCommand
+}
+
+type duplicateGroup struct {
+	Secret      string               `json:"secret" yaml:"secret"`
+	Credentials []duplicateReference `json:"credentials" yaml:"credentials"`
+}
+
+type duplicateReference struct {
+	Name string `json:"name" yaml:"name"`
+	Type string `json:"type" yaml:"type"`
+}
+
+// The kinds of secret material that are compared. Credentials only share
+// secret material with credentials that have the same kind.
+const (
+	secretPassword   = "password"
+	secretValue      = "value"
+	secretJSON       = "json"
+	secretPrivateKey = "private key"
+)
+
+func (c *DuplicatesCommand) ResolveNames() {
+	c.Path = c.resolvePath(c.Path)
+}
+
+func (c *DuplicatesCommand) Execute([]string) error {
+	prefix := path.Clean("/" + c.Path)
+
+	found, err := c.client.FindByPath(prefix)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, credential := range found.Credentials {
+		names = append(names, credential.Name)
+	}
+
+	creds, failures := fetchEachLatestVersion(c.client, names, c.Concurrency)
+
+	groups, err := duplicateGroups(creds)
+	if err != nil {
+		return err
+	}
+
+	if err := printDuplicateGroups(groups, prefix); err != nil {
+		return err
+	}
+
+	if len(failures) == 0 {
+		return nil
+	}
+
+	for _, name := range names {
+		if err, ok := failures[name]; ok {
+			fmt.Fprintf(os.Stderr, "Warning: %s could not be fetched: %s\n", absoluteName(name), err)
+		}
+	}
+
+	return errors.NewDuplicatesFetchFailuresError(len(failures))
+}
+
+func printDuplicateGroups(groups []*duplicateGroup, prefix string) error {
+	if structuredOutput(false) {
+		return printCredential(false, map[string][]*duplicateGroup{"duplicates": groups})
+	}
+
+	if len(groups) == 0 {
+		fmt.Printf("No credentials under %s share secret material.\n", prefix)
+		return nil
+	}
+
+	fmt.Printf("Found %d group(s) of credentials sharing secret material under %s:\n", len(groups), prefix)
+	for _, group := range groups {
+		fmt.Printf("%s shared by %d credential(s):\n", group.Secret, len(group.Credentials))
+		for _, credential := range group.Credentials {
+			fmt.Printf("  %s [%s]\n", credential.Name, credential.Type)
+		}
+	}
+
+	return nil
+}
+
+// duplicateGroups returns the groups of credentials that share secret material,
+// compared by their hash under the run's random key
+func duplicateGroups(creds []credentials.Credential) ([]*duplicateGroup, error) {
+	hasher, err := newValueHasher()
+	if err != nil {
+		return nil, err
+	}
+
+	byHash := map[string]*duplicateGroup{}
+	for _, credential := range creds {
+		for _, secret := range secretMaterial(credential) {
+			hash := hasher.hash(secret.kind + "\x00" + string(secret.material))
+
+			group, ok := byHash[hash]
+			if !ok {
+				group = &duplicateGroup{Secret: secret.kind}
+				byHash[hash] = group
+			}
+			group.Credentials = append(group.Credentials, duplicateReference{Name: absoluteName(credential.Name), Type: credential.Type})
+		}
+	}
+
+	groups := []*duplicateGroup{}
+	for _, group := range byHash {
+		if len(group.Credentials) < 2 {
+			continue
+		}
+		sort.Slice(group.Credentials, func(i, j int) bool { return group.Credentials[i].Name < group.Credentials[j].Name })
+		groups = append(groups, group)
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Secret != groups[j].Secret {
+			return groups[i].Secret < groups[j].Secret
+		}
+		return groups[i].Credentials[0].Name < groups[j].Credentials[0].Name
+	})
+
+	return groups, nil
+}
+
+type secret struct {
+	kind     string
+	material []byte
+}
+
+// secretMaterial returns the secret parts of a credential's value
+//
+// Passwords of password and user credentials are compared with each other, as
+// are the private keys of certificate, RSA and SSH credentials, which are
+// identified by their public key so that the same key matches in any encoding.
+// Public certificates and keys are not secret and are not compared.
+func secretMaterial(credential credentials.Credential) []secret {
+	value, _ := credential.Value.(map[string]interface{})
+	var secrets []secret
+
+	add := func(kind, material string) {
+		if material != "" {
+			secrets = append(secrets, secret{kind: kind, material: []byte(material)})
+		}
+	}
+
+	switch credential.Type {
+	case "password":
+		password, _ := credential.Value.(string)
+		add(secretPassword, password)
+	case "user":
+		password, _ := value["password"].(string)
+		add(secretPassword, password)
+	case "value":
+		add(secretValue, formatScalar(credential.Value))
+	case "json":
+		if compact, err := marshalCompact(credential.Value); err == nil {
+			add(secretJSON, string(compact))
+		}
+	case "certificate", "rsa", "ssh":
+		privateKey, _ := value["private_key"].(string)
+		add(secretPrivateKey, privateKeyIdentity(privateKey))
+	}
+
+	return secrets
+}
+
+// privateKeyIdentity returns the public key of a PEM encoded private key, or the
+// trimmed PEM itself if it cannot be parsed
+func privateKeyIdentity(privateKey string) string {
+	privateKey = strings.TrimSpace(privateKey)
+	if privateKey == "" {
+		return ""
+	}
+
+	signer, ok := parsePrivateKey(privateKey).(crypto.Signer)
+	if !ok {
+		return privateKey
+	}
+
+	publicKey, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		return privateKey
+	}
+
+	return string(publicKey)
+}
+
+func parsePrivateKey(privateKey string) interface{} {
+	block, _ := pem.Decode([]byte(privateKey))
+	if block == nil {
+		return nil
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		return key
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key
+	}
+	if key, err := ssh.ParseRawPrivateKey([]byte(privateKey)); err == nil {
+		return key
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package commands_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"sort"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("Duplicates", func() {
+	var (
+		stored     map[string]string
+		pkcs1Key   string
+		pkcs8Key   string
+		anotherKey string
+		broken     string
+	)
+
+	credentialJSON := func(name, credType string, value interface{}) string {
+		encoded, _ := json.Marshal(value)
+		return `{"type":"` + credType + `","id":"` + UUID + `","name":"` + name + `","version_created_at":"` + TIMESTAMP + `","value":` + string(encoded) + `}`
+	}
+
+	BeforeEach(func() {
+		login()
+
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).NotTo(HaveOccurred())
+		pkcs1Key = string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+		pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+		Expect(err).NotTo(HaveOccurred())
+		pkcs8Key = string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}))
+
+		other, err := rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).NotTo(HaveOccurred())
+		anotherKey = string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(other)}))
+
+		stored = map[string]string{
+			"/team/db-password":  credentialJSON("/team/db-password", "password", "shared-secret"),
+			"/team/admin":        credentialJSON("/team/admin", "user", map[string]string{"username": "admin", "password": "shared-secret"}),
+			"/team/unique":       credentialJSON("/team/unique", "password", "unique-secret"),
+			"/team/url":          credentialJSON("/team/url", "value", "shared-secret"),
+			"/team/config-a":     credentialJSON("/team/config-a", "json", map[string]interface{}{"a": 1, "b": "c"}),
+			"/team/config-b":     credentialJSON("/team/config-b", "json", map[string]interface{}{"b": "c", "a": 1}),
+			"/team/tls":          credentialJSON("/team/tls", "certificate", map[string]string{"certificate": "some-certificate", "private_key": pkcs1Key}),
+			"/team/jwt":          credentialJSON("/team/jwt", "rsa", map[string]string{"public_key": "some-public-key", "private_key": pkcs8Key}),
+			"/team/ssh":          credentialJSON("/team/ssh", "ssh", map[string]string{"public_key": "some-public-key", "private_key": anotherKey}),
+			"/team/other-tls":    credentialJSON("/team/other-tls", "certificate", map[string]string{"certificate": "some-certificate", "private_key": anotherKey}),
+			"/team/no-key-cert":  credentialJSON("/team/no-key-cert", "certificate", map[string]string{"certificate": "some-certificate"}),
+			"/team/no-key-cert2": credentialJSON("/team/no-key-cert2", "certificate", map[string]string{"certificate": "some-certificate"}),
+		}
+
+		broken = ""
+
+		server.RouteToHandler("GET", "/api/v1/data", func(w http.ResponseWriter, r *http.Request) {
+			if broken != "" && r.URL.Query().Get("name") == broken {
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(`{"error":"something went wrong"}`))
+				return
+			}
+			if r.URL.Query().Get("path") != "" {
+				var names []string
+				for name := range stored {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+
+				found := []map[string]string{}
+				for _, name := range names {
+					found = append(found, map[string]string{"name": name, "version_created_at": TIMESTAMP})
+				}
+				json.NewEncoder(w).Encode(map[string]interface{}{"credentials": found})
+				return
+			}
+			w.Write([]byte(`{"data":[` + stored[r.URL.Query().Get("name")] + `]}`))
+		})
+	})
+
+	ItRequiresAuthentication("duplicates", "-p", "/team")
+	ItRequiresAnAPIToBeSet("duplicates", "-p", "/team")
+
+	ItBehavesLikeHelp("duplicates", "duplicates", func(session *Session) {
+		Expect(session.Err).To(Say("path"))
+		Expect(session.Err).To(Say("concurrency"))
+	})
+
+	It("reports the groups of credentials sharing secret material", func() {
+		session := runCommand("duplicates", "-p", "/team", "--concurrency", "3")
+
+		Eventually(session).Should(Exit(0))
+		Expect(session.Out).To(Say(`Found 4 group\(s\) of credentials sharing secret material under /team:\n`))
+		Expect(session.Out).To(Say(`json shared by 2 credential\(s\):\n  /team/config-a \[json\]\n  /team/config-b \[json\]\n`))
+		Expect(session.Out).To(Say(`password shared by 2 credential\(s\):\n  /team/admin \[user\]\n  /team/db-password \[password\]\n`))
+		Expect(session.Out).To(Say(`private key shared by 2 credential\(s\):\n  /team/jwt \[rsa\]\n  /team/tls \[certificate\]\n`))
+		Expect(session.Out).To(Say(`private key shared by 2 credential\(s\):\n  /team/other-tls \[certificate\]\n  /team/ssh \[ssh\]\n`))
+
+		output := string(session.Out.Contents())
+		for _, secret := range []string{"shared-secret", "PRIVATE KEY", "unique", "/team/url"} {
+			Expect(output).NotTo(ContainSubstring(secret))
+		}
+	})
+
+	It("returns the groups in JSON format", func() {
+		session := runCommand("duplicates", "-p", "/team", "--output", "json")
+
+		Eventually(session).Should(Exit(0))
+
+		var output struct {
+			Duplicates []struct {
+				Secret      string              `json:"secret"`
+				Credentials []map[string]string `json:"credentials"`
+			} `json:"duplicates"`
+		}
+		Expect(json.Unmarshal(session.Out.Contents(), &output)).To(Succeed())
+		Expect(output.Duplicates).To(HaveLen(4))
+		Expect(output.Duplicates[1].Secret).To(Equal("password"))
+		Expect(output.Duplicates[1].Credentials).To(Equal([]map[string]string{
+			{"name": "/team/admin", "type": "user"},
+			{"name": "/team/db-password", "type": "password"},
+		}))
+		Expect(string(session.Out.Contents())).NotTo(ContainSubstring("shared-secret"))
+	})
+
+	It("compares the other credentials and fails when one cannot be fetched", func() {
+		broken = "/team/broken"
+		stored[broken] = ""
+
+		session := runCommand("duplicates", "-p", "/team")
+
+		Eventually(session).Should(Exit(1))
+		Expect(session.Out).To(Say(`Found 4 group\(s\) of credentials sharing secret material under /team:\n`))
+		Expect(session.Err).To(Say(`Warning: /team/broken could not be fetched: something went wrong\n`))
+		Expect(session.Err).To(Say(`1 credential\(s\) could not be fetched and were not compared.`))
+	})
+
+	It("reports when no credentials share secret material", func() {
+		stored = map[string]string{"/team/unique": stored["/team/unique"]}
+
+		session := runCommand("duplicates", "-p", "/team")
+
+		Eventually(session).Should(Exit(0))
+		Expect(session.Out).To(Say(`No credentials under /team share secret material.`))
+	})
+})
@@ -1,32 +1,63 @@
 package commands
 
 import (
+	"bytes"
 	"fmt"
 	"io/ioutil"
 
 	"code.cloudfoundry.org/credhub-cli/config"
 	"code.cloudfoundry.org/credhub-cli/credhub/credentials"
+	"code.cloudfoundry.org/credhub-cli/errors"
 	"code.cloudfoundry.org/credhub-cli/models"
 )
 
 type ExportCommand struct {
-	Path string `short:"p" long:"path" description:"Path of credentials to export" required:"false"`
-	File string `short:"f" long:"file" description:"File in which to write credentials" required:"false"`
+	Path   string `short:"p" long:"path" description:"Path of credentials to export" required:"false"`
+	File   string `short:"f" long:"file" description:"File in which to write credentials" required:"false"`
+	Format string `long:"format" default:"credhub" description:"Format of the file: 'credhub' for a list of credentials under the key 'credentials', or 'vars-store' for a BOSH vars-store file named relative to the path"`
+	CurrentPathCommand
+}
+
+func (cmd *ExportCommand) ResolveNames() {
+	cmd.Path = cmd.resolvePath(cmd.Path)
 }
 
 func (cmd ExportCommand) Execute([]string) error {
+	if cmd.Format != "credhub" && cmd.Format != "vars-store" {
+		return errors.NewInvalidFileFormatError(cmd.Format)
+	}
+
 	allCredentials, err := getAllCredentialsForPath(cmd.Path)
 
 	if err != nil {
 		return err
 	}
 
-	exportCreds, err := models.ExportCredentials(allCredentials)
+	var (
+		exportCreds *models.CredentialBulkExport
+		exported    interface{}
+	)
+
+	if cmd.Format == "vars-store" {
+		exportCreds, err = models.ExportVarsStore(allCredentials, cmd.Path)
+		exported = models.ExportedVarsStore(allCredentials, cmd.Path)
+	} else {
+		exportCreds, err = models.ExportCredentials(allCredentials)
+		exported = models.ExportedCredentials(allCredentials)
+	}
 
 	if err != nil {
 		return err
 	}
 
+	if CredHub.Output.Name != "" {
+		var buf bytes.Buffer
+		if err := CredHub.Output.Print(&buf, exported); err != nil {
+			return err
+		}
+		exportCreds.Bytes = buf.Bytes()
+	}
+
 	if cmd.File == "" {
 		fmt.Printf("%s", exportCreds)
 
@@ -120,6 +120,63 @@ var _ = Describe("Export", func() {
 			})
 		})
 
+		Context("when given the vars-store format", func() {
+			It("exports the credentials as a BOSH vars-store file named relative to the path", func() {
+				server.AppendHandlers(
+					CombineHandlers(
+						VerifyRequest("GET", "/api/v1/data", "path=/deployment"),
+						RespondWith(http.StatusOK, `{"credentials": [{"version_created_at": "idc", "name": "/deployment/tls"}, {"version_created_at": "idc", "name": "/deployment/password"}]}`),
+					),
+					CombineHandlers(
+						VerifyRequest("GET", "/api/v1/data", "name=/deployment/tls&current=true"),
+						RespondWith(http.StatusOK, `{"data": [{"type":"certificate","id":"some_uuid","name":"/deployment/tls","version_created_at":"idc","value": {"ca_name":"/deployment/ca","ca":"some-ca","certificate":"some-certificate","private_key":"some-private-key"}}]}`),
+					),
+					CombineHandlers(
+						VerifyRequest("GET", "/api/v1/data", "name=/deployment/password&current=true"),
+						RespondWith(http.StatusOK, `{"data": [{"type":"password","id":"some_uuid","name":"/deployment/password","version_created_at":"idc","value": "some-password"}]}`),
+					),
+				)
+
+				session := runCommand("export", "-p", "/deployment", "--format", "vars-store")
+
+				Eventually(session).Should(Exit(0))
+				Expect(string(session.Out.Contents())).To(MatchYAML(`
+password: some-password
+tls:
+  ca: some-ca
+  certificate: some-certificate
+  private_key: some-private-key
+`))
+			})
+
+			It("returns an error for an unknown format", func() {
+				session := runCommand("export", "--format", "vars")
+
+				Eventually(session).Should(Exit(1))
+				Expect(session.Err).To(Say(`The format "vars" is not supported. Valid formats are 'credhub' and 'vars-store'.`))
+			})
+		})
+
+		Context("when given an output format", func() {
+			It("exports the credentials in that format", func() {
+				server.AppendHandlers(
+					CombineHandlers(
+						VerifyRequest("GET", "/api/v1/data", "path="),
+						RespondWith(http.StatusOK, `{"credentials": [{"version_created_at": "idc", "name": "/path/to/cred"}]}`),
+					),
+					CombineHandlers(
+						VerifyRequest("GET", "/api/v1/data", "name=/path/to/cred&current=true"),
+						RespondWith(http.StatusOK, `{"data": [{"type":"value","id":"some_uuid","name":"/path/to/cred","version_created_at":"idc","value": "foo"}]}`),
+					),
+				)
+
+				session := runCommand("export", "--output", "json")
+
+				Eventually(session).Should(Exit(0))
+				Expect(string(session.Out.Contents())).To(MatchJSON(`{"credentials": [{"name": "/path/to/cred", "type": "value", "value": "foo"}]}`))
+			})
+		})
+
 		Context("when given a file", func() {
 			It("writes the YAML to that file", func() {
 				withTemporaryFile(func(filename string) {
@@ -1,16 +1,23 @@
 package commands
 
 import (
+	"strings"
+
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials"
 	"code.cloudfoundry.org/credhub-cli/errors"
 )
 
 type FindCommand struct {
-	PartialCredentialIdentifier string `short:"n" long:"name-like" description:"Find credentials whose name contains the query string"`
+	PartialCredentialIdentifier string `short:"n" long:"name-like" description:"Find credentials whose name contains the query string, under the current path if one is set"`
 	PathIdentifier              string `short:"p" long:"path" description:"Find credentials that exist under the provided path"`
 	OutputJSON                  bool   `short:"j" long:"output-json" description:"Return response in JSON format"`
 	ClientCommand
 }
 
+func (c *FindCommand) ResolveNames() {
+	c.PathIdentifier = c.resolvePath(c.PathIdentifier)
+}
+
 func (c *FindCommand) Execute([]string) error {
 
 	if c.PartialCredentialIdentifier != "" {
@@ -19,19 +26,34 @@ func (c *FindCommand) Execute([]string) error {
 			return err
 		}
 
+		if c.currentPath != "" {
+			results.Credentials = credentialsUnder(results.Credentials, c.currentPath)
+		}
+
 		if len(results.Credentials) == 0 {
 			return errors.NewNoMatchingCredentialsFoundError()
 		}
 
-		printCredential(c.OutputJSON, results)
-	} else {
-		output, err := c.client.FindByPath(c.PathIdentifier)
-		if err != nil {
-			return err
-		}
+		return printCredential(c.OutputJSON, results)
+	}
 
-		printCredential(c.OutputJSON, output)
+	output, err := c.client.FindByPath(c.PathIdentifier)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	return printCredential(c.OutputJSON, output)
+}
+
+// credentialsUnder returns the credentials whose names are under the path
+func credentialsUnder(found []credentials.Base, p string) []credentials.Base {
+	prefix := strings.TrimSuffix(absoluteName(p), "/") + "/"
+
+	under := []credentials.Base{}
+	for _, credential := range found {
+		if strings.HasPrefix(absoluteName(credential.Name), prefix) {
+			under = append(under, credential)
+		}
+	}
+	return under
 }
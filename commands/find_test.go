@@ -139,6 +139,25 @@ var _ = Describe("Find", func() {
 		})
 	})
 
+	Describe("with table output", func() {
+		It("prints a row for each credential", func() {
+			server.RouteToHandler("GET", "/api/v1/data",
+				RespondWith(http.StatusOK, `{"credentials": [
+					{"name": "deploy123/dan.password", "version_created_at": "2016-09-06T23:26:58Z"},
+					{"name": "deploy123/dan/id.key", "version_created_at": "2016-09-07T23:26:58Z"}
+				]}`),
+			)
+
+			session := runCommand("find", "-p", "deploy123", "--output", "table")
+
+			Eventually(session).Should(Exit(0))
+			Expect(string(session.Out.Contents())).To(Equal(
+				"NAME                     VERSION CREATED AT\n" +
+					"deploy123/dan.password   2016-09-06T23:26:58Z\n" +
+					"deploy123/dan/id.key     2016-09-07T23:26:58Z\n"))
+		})
+	})
+
 	Describe("when an error is received from the server", func() {
 		It("shows the error name and description", func() {
 			server.AppendHandlers(
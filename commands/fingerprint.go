@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials"
+)
+
+// valueHasher hashes secret values with HMAC-SHA256 under a key that is random
+// for each run, so that values can be compared within one output while the
+// hashes can neither be brute-forced nor matched with those of other runs
+type valueHasher struct {
+	key []byte
+}
+
+func newValueHasher() (*valueHasher, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return &valueHasher{key: key}, nil
+}
+
+// hash returns a short hash that identifies a value within the run
+func (h *valueHasher) hash(value string) string {
+	mac := hmac.New(sha256.New, h.key)
+	mac.Write([]byte(value))
+	return "hmac:" + hex.EncodeToString(mac.Sum(nil))[:12]
+}
+
+// fingerprint returns a short, non-secret summary of a credential value
+//
+// Certificates are summarised by serial number and expiry, RSA and SSH keys by
+// the SHA-256 fingerprint of their public key and anything else by a keyed hash.
+func (h *valueHasher) fingerprint(credential credentials.Credential) string {
+	value, _ := credential.Value.(map[string]interface{})
+
+	switch credential.Type {
+	case "certificate":
+		certificate, _ := value["certificate"].(string)
+		if info, err := parseCertificateInfo(certificate); err == nil {
+			return "serial " + info.Serial + ", expires " + info.NotAfter.Format(time.RFC3339)
+		}
+	case "ssh":
+		if fingerprint, ok := value["public_key_fingerprint"].(string); ok && fingerprint != "" {
+			return "SHA256:" + fingerprint
+		}
+		publicKey, _ := value["public_key"].(string)
+		if fields := strings.Fields(publicKey); len(fields) >= 2 {
+			if key, err := base64.StdEncoding.DecodeString(fields[1]); err == nil {
+				return publicKeyFingerprint(key)
+			}
+		}
+	case "rsa":
+		publicKey, _ := value["public_key"].(string)
+		if block, _ := pem.Decode([]byte(publicKey)); block != nil {
+			return publicKeyFingerprint(block.Bytes)
+		}
+	case "user":
+		username, _ := value["username"].(string)
+		password, _ := value["password"].(string)
+		return username + " " + h.hash(password)
+	}
+
+	return h.hash(formatScalar(credential.Value))
+}
+
+func publicKeyFingerprint(key []byte) string {
+	sum := sha256.Sum256(key)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
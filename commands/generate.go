@@ -35,9 +35,15 @@ type GenerateCommand struct {
 	Ca                   string   `long:"ca" description:"[Certificate] Name of CA used to sign the generated certificate"`
 	IsCA                 bool     `long:"is-ca" description:"[Certificate] The generated certificate is a certificate authority"`
 	SelfSign             bool     `long:"self-sign" description:"[Certificate] The generated certificate will be self-signed"`
+	Policy               string   `long:"policy" description:"Policy file the generated credential must comply with before it is generated"`
 	ClientCommand
 }
 
+func (c *GenerateCommand) ResolveNames() {
+	c.CredentialIdentifier = c.resolveName(c.CredentialIdentifier)
+	c.Ca = c.resolveName(c.Ca)
+}
+
 func (c GenerateCommand) Execute([]string) error {
 	if c.CredentialType == "" {
 		return errors.NewGenerateEmptyTypeError()
@@ -51,6 +57,30 @@ func (c GenerateCommand) Execute([]string) error {
 		return errors.NewUserNameOnlyValidForUserType()
 	}
 
+	generationParameters := models.GenerationParameters{
+		IncludeSpecial:   c.IncludeSpecial,
+		ExcludeNumber:    c.ExcludeNumber,
+		ExcludeUpper:     c.ExcludeUpper,
+		ExcludeLower:     c.ExcludeLower,
+		Length:           c.Length,
+		CommonName:       c.CommonName,
+		Organization:     c.Organization,
+		OrganizationUnit: c.OrganizationUnit,
+		Locality:         c.Locality,
+		State:            c.State,
+		Country:          c.Country,
+		AlternativeName:  c.AlternativeName,
+		ExtendedKeyUsage: c.ExtendedKeyUsage,
+		KeyUsage:         c.KeyUsage,
+		KeyLength:        c.KeyLength,
+		Duration:         c.Duration,
+		Ca:               c.Ca,
+		SelfSign:         c.SelfSign,
+		IsCA:             c.IsCA,
+		SSHComment:       c.SSHComment,
+		Username:         c.Username,
+	}
+
 	if len(c.Username) > 0 {
 		parameters = generate.User{
 			Username:       c.Username,
@@ -61,29 +91,14 @@ func (c GenerateCommand) Execute([]string) error {
 			ExcludeLower:   c.ExcludeLower,
 		}
 	} else {
-		parameters = models.GenerationParameters{
-			IncludeSpecial:   c.IncludeSpecial,
-			ExcludeNumber:    c.ExcludeNumber,
-			ExcludeUpper:     c.ExcludeUpper,
-			ExcludeLower:     c.ExcludeLower,
-			Length:           c.Length,
-			CommonName:       c.CommonName,
-			Organization:     c.Organization,
-			OrganizationUnit: c.OrganizationUnit,
-			Locality:         c.Locality,
-			State:            c.State,
-			Country:          c.Country,
-			AlternativeName:  c.AlternativeName,
-			ExtendedKeyUsage: c.ExtendedKeyUsage,
-			KeyUsage:         c.KeyUsage,
-			KeyLength:        c.KeyLength,
-			Duration:         c.Duration,
-			Ca:               c.Ca,
-			SelfSign:         c.SelfSign,
-			IsCA:             c.IsCA,
-			SSHComment:       c.SSHComment,
-			Username:         c.Username,
-		}
+		parameters = generationParameters
+	}
+
+	err := enforcePolicy(c.Policy, func(policy *models.Policy) []models.PolicyViolation {
+		return policy.CheckGeneration(c.CredentialIdentifier, c.CredentialType, generationParameters)
+	})
+	if err != nil {
+		return err
 	}
 
 	mode := credhub.Overwrite
@@ -99,7 +114,5 @@ func (c GenerateCommand) Execute([]string) error {
 	}
 
 	credential.Value = "<redacted>"
-	printCredential(c.OutputJSON, credential)
-
-	return nil
+	return printCredential(c.OutputJSON, credential)
 }
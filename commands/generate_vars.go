@@ -0,0 +1,143 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"code.cloudfoundry.org/credhub-cli/credhub"
+	"code.cloudfoundry.org/credhub-cli/errors"
+	"code.cloudfoundry.org/credhub-cli/models"
+)
+
+type GenerateVarsCommand struct {
+	Manifest string `short:"m" long:"manifest" required:"yes" description:"BOSH deployment manifest declaring the variables to generate"`
+	Prefix   string `long:"prefix" required:"yes" description:"Path the variables are stored under, e.g. /director/deployment"`
+	DryRun   bool   `long:"dry-run" description:"Validate the variables and show the order they would be generated in without generating them"`
+	ClientCommand
+}
+
+type generatedVariable struct {
+	Name             string `json:"name" yaml:"name"`
+	Type             string `json:"type" yaml:"type"`
+	Ca               string `json:"ca,omitempty" yaml:"ca,omitempty"`
+	Action           string `json:"action" yaml:"action"`
+	Id               string `json:"id,omitempty" yaml:"id,omitempty"`
+	VersionCreatedAt string `json:"version_created_at,omitempty" yaml:"version_created_at,omitempty"`
+	Reason           string `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+const (
+	generateVarsPlanned   = "generate"
+	generateVarsGenerated = "generated"
+	generateVarsSkipped   = "skipped"
+	generateVarsFailed    = "failed"
+)
+
+func (c *GenerateVarsCommand) ResolveNames() {
+	c.Prefix = c.resolvePath(c.Prefix)
+}
+
+func (c *GenerateVarsCommand) Execute([]string) error {
+	var manifest models.BoshManifest
+	if err := manifest.ReadFile(c.Manifest); err != nil {
+		return err
+	}
+
+	variables, err := manifest.Ordered()
+	if err != nil {
+		return err
+	}
+
+	prefix := "/" + strings.Trim(c.Prefix, "/")
+
+	results := []*generatedVariable{}
+	unavailable := map[string]bool{}
+	failed := 0
+
+	for _, variable := range variables {
+		result := &generatedVariable{
+			Name:   models.CredentialName(prefix, variable.Name),
+			Type:   variable.Type,
+			Action: generateVarsPlanned,
+		}
+		results = append(results, result)
+
+		parameters, err := variable.GenerationParameters(prefix)
+		if err != nil {
+			return err
+		}
+		if p, ok := parameters.(models.GenerationParameters); ok {
+			result.Ca = p.Ca
+		}
+
+		if c.DryRun {
+			continue
+		}
+
+		if unavailable[result.Ca] {
+			result.Action = generateVarsSkipped
+			result.Reason = "the CA " + result.Ca + " could not be generated"
+			unavailable[result.Name] = true
+			failed++
+			continue
+		}
+
+		credential, err := c.client.GenerateCredential(result.Name, result.Type, parameters, credhub.Converge)
+		if err != nil {
+			result.Action = generateVarsFailed
+			result.Reason = err.Error()
+			unavailable[result.Name] = true
+			failed++
+			continue
+		}
+
+		result.Action = generateVarsGenerated
+		result.Id = credential.Id
+		result.VersionCreatedAt = credential.VersionCreatedAt
+	}
+
+	if structuredOutput(false) {
+		if err := printCredential(false, map[string][]*generatedVariable{"variables": results}); err != nil {
+			return err
+		}
+	} else {
+		printGeneratedVariables(results, prefix, c.DryRun)
+	}
+
+	if failed > 0 {
+		return errors.NewGenerateVarsFailuresError(failed)
+	}
+
+	return nil
+}
+
+func printGeneratedVariables(results []*generatedVariable, prefix string, dryRun bool) {
+	counts := map[string]int{}
+
+	for _, result := range results {
+		counts[result.Action]++
+		line := result.Name + " [" + result.Type + "]"
+		if result.Ca != "" {
+			line += " signed by " + result.Ca
+		}
+
+		switch result.Action {
+		case generateVarsPlanned:
+			fmt.Println("  " + line)
+		case generateVarsGenerated:
+			fmt.Println("+ " + line + " " + result.VersionCreatedAt)
+		case generateVarsSkipped:
+			fmt.Println("! " + line + " skipped: " + result.Reason)
+		case generateVarsFailed:
+			fmt.Println("x " + line + " failed: " + result.Reason)
+		}
+	}
+
+	if dryRun {
+		fmt.Printf("Dry run under %s: %d variable(s) would be generated\n", prefix, counts[generateVarsPlanned])
+		return
+	}
+
+	fmt.Printf("Generated variables under %s: %d generated, %d skipped, %d failed\n",
+		prefix, counts[generateVarsGenerated], counts[generateVarsSkipped], counts[generateVarsFailed])
+}
@@ -0,0 +1,183 @@
+package commands_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("Generate-Vars", func() {
+	var (
+		lock         sync.Mutex
+		requests     []map[string]interface{}
+		failing      map[string]bool
+		manifestFile string
+	)
+
+	const manifest = `
+name: deployment
+instance_groups:
+- name: web
+  properties:
+    tls: ((tls))
+variables:
+- name: tls
+  type: certificate
+  options:
+    ca: ca
+    common_name: web.example.com
+    alternative_names: [web.example.com]
+    extended_key_usage: [server_auth]
+- name: admin_password
+  type: password
+- name: admin
+  type: user
+  options:
+    username: admin
+- name: ssh_key
+  type: ssh
+- name: /shared/jwt
+  type: rsa
+- name: ca
+  type: certificate
+  options:
+    is_ca: true
+    common_name: deployment-ca
+`
+
+	BeforeEach(func() {
+		login()
+
+		manifestFile = filepath.Join(homeDir, "manifest.yml")
+		Expect(ioutil.WriteFile(manifestFile, []byte(manifest), 0600)).To(Succeed())
+
+		requests = nil
+		failing = map[string]bool{}
+
+		server.RouteToHandler("POST", "/api/v1/data", func(w http.ResponseWriter, r *http.Request) {
+			lock.Lock()
+			defer lock.Unlock()
+
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			requests = append(requests, body)
+
+			name := body["name"].(string)
+			if failing[name] {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"The request could not be completed."}`))
+				return
+			}
+			w.Write([]byte(`{"type":"` + body["type"].(string) + `","id":"` + UUID + `","name":"` + name + `","version_created_at":"` + TIMESTAMP + `","value":"some-secret"}`))
+		})
+	})
+
+	requestedNames := func() []string {
+		var names []string
+		for _, request := range requests {
+			names = append(names, request["name"].(string))
+		}
+		return names
+	}
+
+	ItRequiresAuthentication("generate-vars", "-m", "manifest.yml", "--prefix", "/director/deployment")
+	ItRequiresAnAPIToBeSet("generate-vars", "-m", "manifest.yml", "--prefix", "/director/deployment")
+
+	ItBehavesLikeHelp("generate-vars", "generate-vars", func(session *Session) {
+		Expect(session.Err).To(Say("manifest"))
+		Expect(session.Err).To(Say("prefix"))
+		Expect(session.Err).To(Say("dry-run"))
+	})
+
+	It("generates the variables in converge mode with CAs first", func() {
+		session := runCommand("generate-vars", "-m", manifestFile, "--prefix", "/director/deployment/")
+
+		Eventually(session).Should(Exit(0))
+		Expect(requestedNames()).To(Equal([]string{
+			"/director/deployment/ca",
+			"/director/deployment/tls",
+			"/director/deployment/admin_password",
+			"/director/deployment/admin",
+			"/director/deployment/ssh_key",
+			"/shared/jwt",
+		}))
+
+		Expect(requests[0]).To(Equal(map[string]interface{}{
+			"name":       "/director/deployment/ca",
+			"type":       "certificate",
+			"mode":       "converge",
+			"parameters": map[string]interface{}{"common_name": "deployment-ca", "is_ca": true},
+		}))
+		Expect(requests[1]["parameters"]).To(Equal(map[string]interface{}{
+			"ca":                 "/director/deployment/ca",
+			"common_name":        "web.example.com",
+			"alternative_names":  []interface{}{"web.example.com"},
+			"extended_key_usage": []interface{}{"server_auth"},
+		}))
+		Expect(requests[3]["value"]).To(Equal(map[string]interface{}{"username": "admin"}))
+
+		Expect(session.Out).To(Say(`\+ /director/deployment/ca \[certificate\] ` + TIMESTAMP))
+		Expect(session.Out).To(Say(`\+ /director/deployment/tls \[certificate\] signed by /director/deployment/ca ` + TIMESTAMP))
+		Expect(session.Out).To(Say(`Generated variables under /director/deployment: 6 generated, 0 skipped, 0 failed`))
+		Expect(string(session.Out.Contents())).NotTo(ContainSubstring("some-secret"))
+	})
+
+	It("shows the order without generating anything with --dry-run", func() {
+		session := runCommand("generate-vars", "-m", manifestFile, "--prefix", "/director/deployment", "--dry-run")
+
+		Eventually(session).Should(Exit(0))
+		Expect(session.Out).To(Say(`  /director/deployment/ca \[certificate\]\n`))
+		Expect(session.Out).To(Say(`  /director/deployment/tls \[certificate\] signed by /director/deployment/ca\n`))
+		Expect(session.Out).To(Say(`Dry run under /director/deployment: 6 variable\(s\) would be generated`))
+		Expect(requests).To(BeEmpty())
+	})
+
+	It("skips the certificates signed by a CA that could not be generated", func() {
+		failing["/director/deployment/ca"] = true
+
+		session := runCommand("generate-vars", "-m", manifestFile, "--prefix", "/director/deployment")
+
+		Eventually(session).Should(Exit(1))
+		Expect(session.Out).To(Say(`x /director/deployment/ca \[certificate\] failed: The request could not be completed.`))
+		Expect(session.Out).To(Say(`! /director/deployment/tls \[certificate\] signed by /director/deployment/ca skipped: the CA /director/deployment/ca could not be generated`))
+		Expect(session.Out).To(Say(`4 generated, 1 skipped, 1 failed`))
+		Expect(session.Err).To(Say(`2 variable\(s\) could not be generated.`))
+		Expect(requestedNames()).NotTo(ContainElement("/director/deployment/tls"))
+	})
+
+	It("returns the results in JSON format", func() {
+		session := runCommand("generate-vars", "-m", manifestFile, "--prefix", "/director/deployment", "--output", "json")
+
+		Eventually(session).Should(Exit(0))
+
+		var output struct {
+			Variables []map[string]string `json:"variables"`
+		}
+		Expect(json.Unmarshal(session.Out.Contents(), &output)).To(Succeed())
+		Expect(output.Variables).To(HaveLen(6))
+		Expect(output.Variables[0]).To(Equal(map[string]string{
+			"name":               "/director/deployment/ca",
+			"type":               "certificate",
+			"action":             "generated",
+			"id":                 UUID,
+			"version_created_at": TIMESTAMP,
+		}))
+	})
+
+	It("returns an error for an invalid manifest", func() {
+		Expect(ioutil.WriteFile(manifestFile, []byte("variables:\n- name: a\n  type: json\n"), 0600)).To(Succeed())
+
+		session := runCommand("generate-vars", "-m", manifestFile, "--prefix", "/director/deployment")
+
+		Eventually(session).Should(Exit(1))
+		Expect(session.Err).To(Say(`The manifest is not valid: the variable "a" has the type "json".`))
+		Expect(requests).To(BeEmpty())
+	})
+})
@@ -5,6 +5,7 @@ import (
 
 	"code.cloudfoundry.org/credhub-cli/credhub/credentials"
 	"code.cloudfoundry.org/credhub-cli/errors"
+	"code.cloudfoundry.org/credhub-cli/util"
 )
 
 type GetCommand struct {
@@ -12,10 +13,14 @@ type GetCommand struct {
 	ID               string `long:"id" description:"ID of the credential to retrieve"`
 	NumberOfVersions int    `long:"versions" description:"Number of versions of the credential to retrieve"`
 	OutputJSON       bool   `short:"j" long:"output-json" description:"Return response in JSON format"`
-	Key              string `short:"k" long:"key" description:"Return only the specified field of the requested credential"`
+	Key              string `short:"k" long:"key" description:"Return only the specified field of the requested credential, e.g. 'ca' or 'db.hosts[0]'"`
 	ClientCommand
 }
 
+func (c *GetCommand) ResolveNames() {
+	c.Name = c.resolveName(c.Name)
+}
+
 func (c *GetCommand) Execute([]string) error {
 	var (
 		credential credentials.Credential
@@ -24,11 +29,15 @@ func (c *GetCommand) Execute([]string) error {
 
 	var arrayOfCredentials []credentials.Credential
 
+	var selector *util.JSONPath
+	if c.Key != "" {
+		if selector, err = util.ParseJSONPath(c.Key); err != nil {
+			return errors.NewInvalidKeySelectorError(err)
+		}
+	}
+
 	if c.Name != "" {
 		if c.NumberOfVersions != 0 {
-			if c.Key != "" {
-				return errors.NewGetVersionAndKeyError()
-			}
 			arrayOfCredentials, err = c.client.GetNVersions(c.Name, c.NumberOfVersions)
 		} else {
 			credential, err = c.client.GetLatestVersion(c.Name)
@@ -44,31 +53,80 @@ func (c *GetCommand) Execute([]string) error {
 	}
 
 	if arrayOfCredentials != nil {
+		if selector != nil {
+			return c.printSelectedVersions(selector, arrayOfCredentials)
+		}
+
 		output := map[string][]credentials.Credential{
 			"versions": arrayOfCredentials,
 		}
-		printCredential(c.OutputJSON, output)
-	} else {
-		if c.Key != "" {
-			cred, ok := credential.Value.(map[string]interface{})
-			if !ok {
-				return nil
-			}
-
-			if cred[c.Key] == nil {
-				return nil
-			}
-			switch cred[c.Key].(type) {
-			case string:
-				fmt.Println(cred[c.Key])
-
-			default:
-				printCredential(c.OutputJSON, cred[c.Key])
-			}
-		} else {
-			printCredential(c.OutputJSON, credential)
+		return printCredential(c.OutputJSON, output)
+	}
+
+	if selector != nil {
+		value, err := c.selectKey(selector, credential)
+		if err != nil {
+			return err
+		}
+
+		if s, ok := value.(string); ok {
+			fmt.Println(s)
+			return nil
+		}
+		return printCredential(c.OutputJSON, value)
+	}
+
+	return printCredential(c.OutputJSON, credential)
+}
+
+func (c *GetCommand) printSelectedVersions(selector *util.JSONPath, versions []credentials.Credential) error {
+	values := make([]interface{}, len(versions))
+	allStrings := true
+
+	for i, version := range versions {
+		value, err := c.selectKey(selector, version)
+		if err != nil {
+			return err
+		}
+		if _, ok := value.(string); !ok {
+			allStrings = false
+		}
+		values[i] = value
+	}
+
+	if allStrings {
+		for _, value := range values {
+			fmt.Println(value)
+		}
+		return nil
+	}
+
+	return printCredential(c.OutputJSON, map[string][]interface{}{"versions": values})
+}
+
+// selectKey returns the part of the credential value selected by --key
+//
+// A top-level field whose name matches the key exactly is preferred, so that
+// keys containing dots or brackets keep working. If the selector matches more
+// than one value, the matches are returned as a list.
+func (c *GetCommand) selectKey(selector *util.JSONPath, credential credentials.Credential) (interface{}, error) {
+	if value, ok := credential.Value.(map[string]interface{}); ok {
+		if field, ok := value[c.Key]; ok && field != nil {
+			return field, nil
+		}
+	}
+
+	matches := selector.Find(credential.Value)
+
+	switch len(matches) {
+	case 0:
+		return nil, errors.NewKeyNotFoundError(c.Key)
+	case 1:
+		if matches[0] == nil {
+			return nil, errors.NewKeyNotFoundError(c.Key)
 		}
+		return matches[0], nil
 	}
 
-	return nil
+	return matches, nil
 }
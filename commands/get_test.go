@@ -233,6 +233,81 @@ var _ = Describe("Get", func() {
 
 	})
 
+	Context("when an output format is specified", func() {
+		BeforeEach(func() {
+			responseJson := `{"data":[{"type":"password","id":"` + UUID + `","name":"my-password","version_created_at":"` + TIMESTAMP + `","value":"old-password"},{"type":"password","id":"` + UUID + `","name":"my-password","version_created_at":"` + TIMESTAMP + `","value":"new-password"}]}`
+
+			server.RouteToHandler("GET", "/api/v1/data",
+				CombineHandlers(
+					VerifyRequest("GET", "/api/v1/data", "name=my-password&versions=2"),
+					RespondWith(http.StatusOK, responseJson),
+				),
+			)
+		})
+
+		It("prints one JSON object per line with jsonl", func() {
+			session := runCommand("get", "-n", "my-password", "--versions", "2", "--output", "jsonl")
+
+			Eventually(session).Should(Exit(0))
+			Expect(string(session.Out.Contents())).To(Equal(
+				`{"id":"` + UUID + `","name":"my-password","type":"password","value":"old-password","version_created_at":"` + TIMESTAMP + `"}` + "\n" +
+					`{"id":"` + UUID + `","name":"my-password","type":"password","value":"new-password","version_created_at":"` + TIMESTAMP + `"}` + "\n"))
+		})
+
+		It("prints a table", func() {
+			session := runCommand("get", "-n", "my-password", "--versions", "2", "--output", "table")
+
+			Eventually(session).Should(Exit(0))
+			Expect(string(session.Out.Contents())).To(Equal(
+				"NAME          TYPE       ID                                     VERSION CREATED AT     VALUE\n" +
+					"my-password   password   " + UUID + "   " + TIMESTAMP + "   old-password\n" +
+					"my-password   password   " + UUID + "   " + TIMESTAMP + "   new-password\n"))
+		})
+
+		It("executes a Go template against the JSON output", func() {
+			session := runCommand("get", "-n", "my-password", "--versions", "2", "--output", `template={{range .versions}}{{.value}} {{end}}`)
+
+			Eventually(session).Should(Exit(0))
+			Expect(string(session.Out.Contents())).To(Equal("old-password new-password \n"))
+		})
+
+		It("prints the values matched by a JSONPath expression", func() {
+			session := runCommand("get", "-n", "my-password", "--versions", "2", "--output", "jsonpath={.versions[*].value}")
+
+			Eventually(session).Should(Exit(0))
+			Expect(string(session.Out.Contents())).To(Equal("old-password\nnew-password\n"))
+		})
+
+		It("accepts the option before the command", func() {
+			session := runCommand("--output", "jsonpath=.versions[-1].value", "get", "-n", "my-password", "--versions", "2")
+
+			Eventually(session).Should(Exit(0))
+			Expect(string(session.Out.Contents())).To(Equal("new-password\n"))
+		})
+
+		It("returns an error when the JSONPath expression does not match", func() {
+			session := runCommand("get", "-n", "my-password", "--versions", "2", "--output", "jsonpath=.missing")
+
+			Eventually(session).Should(Exit(1))
+			Expect(session.Err).To(Say(`The JSONPath expression ".missing" did not match any values.`))
+		})
+
+		It("prefers --output over --output-json", func() {
+			session := runCommand("get", "-n", "my-password", "--versions", "2", "--output-json", "--output", "jsonl")
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Out.Contents()).To(HavePrefix(`{"id":"`))
+		})
+
+		It("rejects unsupported formats without making a request", func() {
+			session := runCommand("get", "-n", "my-password", "--versions", "2", "--output", "xml")
+
+			Eventually(session).Should(Exit(1))
+			Expect(session.Err).To(Say(`The output format "xml" is not supported.`))
+			Expect(server.ReceivedRequests()).To(HaveLen(0))
+		})
+	})
+
 	Context("when a key is specified", func() {
 		Context("when the key is valid", func() {
 			It("only returns the request field from the value object", func() {
@@ -275,8 +350,49 @@ my-ca
 			})
 		})
 
+		Context("when the key is a nested selector", func() {
+			BeforeEach(func() {
+				responseJson := fmt.Sprintf(JSON_CREDENTIAL_ARRAY_RESPONSE_JSON, "json-secret", `{"db":{"hosts":["10.0.0.1","10.0.0.2"],"port":5432},"dotted.key":"dotted-value"}`)
+
+				server.RouteToHandler("GET", "/api/v1/data",
+					CombineHandlers(
+						VerifyRequest("GET", "/api/v1/data", "current=true&name=json-secret"),
+						RespondWith(http.StatusOK, responseJson),
+					),
+				)
+			})
+
+			It("returns the field selected by a dotted path", func() {
+				session := runCommand("get", "-n", "json-secret", "-k", "db.hosts[0]")
+
+				Eventually(session).Should(Exit(0))
+				Expect(string(session.Out.Contents())).To(Equal("10.0.0.1\n"))
+			})
+
+			It("returns the field selected by a JSONPath expression", func() {
+				session := runCommand("get", "-n", "json-secret", "-k", "$.db['hosts'][-1]")
+
+				Eventually(session).Should(Exit(0))
+				Expect(string(session.Out.Contents())).To(Equal("10.0.0.2\n"))
+			})
+
+			It("prints non-string fields in the output format", func() {
+				session := runCommand("get", "-n", "json-secret", "-k", "db.hosts", "-j")
+
+				Eventually(session).Should(Exit(0))
+				Expect(session.Out.Contents()).To(MatchJSON(`["10.0.0.1","10.0.0.2"]`))
+			})
+
+			It("prefers a top-level field whose name matches the key exactly", func() {
+				session := runCommand("get", "-n", "json-secret", "-k", "dotted.key")
+
+				Eventually(session).Should(Exit(0))
+				Expect(string(session.Out.Contents())).To(Equal("dotted-value\n"))
+			})
+		})
+
 		Context("when the key is invalid", func() {
-			It("returns nothing", func() {
+			It("returns an error", func() {
 				responseJson := fmt.Sprintf(CERTIFICATE_CREDENTIAL_ARRAY_RESPONSE_JSON, "my-secret", "my-ca", "my-cert", "my-priv")
 
 				server.RouteToHandler("GET", "/api/v1/data",
@@ -288,14 +404,74 @@ my-ca
 
 				session := runCommand("get", "-n", "my-secret", "-k", "invalidkey")
 
-				Eventually(session).Should(Exit(0))
-				Eventually(string(session.Out.Contents())).Should(Equal(``))
+				Eventually(session).Should(Exit(1))
+				Expect(session.Err).To(Say(`The key "invalidkey" was not found in the credential value.`))
+				Expect(string(session.Out.Contents())).To(Equal(``))
+			})
+
+			It("returns an error when the value is not an object", func() {
+				responseJson := fmt.Sprintf(STRING_CREDENTIAL_ARRAY_RESPONSE_JSON, "password", "my-password", "some-password")
 
+				server.RouteToHandler("GET", "/api/v1/data",
+					CombineHandlers(
+						VerifyRequest("GET", "/api/v1/data", "current=true&name=my-password"),
+						RespondWith(http.StatusOK, responseJson),
+					),
+				)
+
+				session := runCommand("get", "-n", "my-password", "-k", "db.hosts[0]")
+
+				Eventually(session).Should(Exit(1))
+				Expect(session.Err).To(Say(`The key "db.hosts\[0\]" was not found in the credential value.`))
+			})
+
+			It("returns an error without making a request when the selector cannot be parsed", func() {
+				session := runCommand("get", "-n", "my-secret", "-k", "db.hosts[0")
+
+				Eventually(session).Should(Exit(1))
+				Expect(session.Err).To(Say(`The --key selector could not be used: invalid JSONPath expression "db.hosts\[0": unterminated '\['`))
+				Expect(server.ReceivedRequests()).To(HaveLen(0))
 			})
 		})
 
 		Context("when there are a specified number of versions", func() {
-			It("returns an error", func() {
+			It("returns the selected field of each version", func() {
+				responseJson := fmt.Sprintf(`{"data":[%s,%s]}`,
+					fmt.Sprintf(JSON_CREDENTIAL_RESPONSE_JSON, "json-secret", `{"db":{"password":"new-password"}}`),
+					fmt.Sprintf(JSON_CREDENTIAL_RESPONSE_JSON, "json-secret", `{"db":{"password":"old-password"}}`))
+
+				server.RouteToHandler("GET", "/api/v1/data",
+					CombineHandlers(
+						VerifyRequest("GET", "/api/v1/data", "name=json-secret&versions=2"),
+						RespondWith(http.StatusOK, responseJson),
+					),
+				)
+
+				session := runCommand("get", "-n", "json-secret", "--versions", "2", "-k", "db.password")
+
+				Eventually(session).Should(Exit(0))
+				Expect(string(session.Out.Contents())).To(Equal("new-password\nold-password\n"))
+			})
+
+			It("lists non-string fields under versions", func() {
+				responseJson := fmt.Sprintf(`{"data":[%s,%s]}`,
+					fmt.Sprintf(JSON_CREDENTIAL_RESPONSE_JSON, "json-secret", `{"db":{"port":5433}}`),
+					fmt.Sprintf(JSON_CREDENTIAL_RESPONSE_JSON, "json-secret", `{"db":{"port":5432}}`))
+
+				server.RouteToHandler("GET", "/api/v1/data",
+					CombineHandlers(
+						VerifyRequest("GET", "/api/v1/data", "name=json-secret&versions=2"),
+						RespondWith(http.StatusOK, responseJson),
+					),
+				)
+
+				session := runCommand("get", "-n", "json-secret", "--versions", "2", "-k", "db.port", "-j")
+
+				Eventually(session).Should(Exit(0))
+				Expect(session.Out.Contents()).To(MatchJSON(`{"versions":[5433,5432]}`))
+			})
+
+			It("returns an error when a version does not contain the key", func() {
 				responseJson := `{"data":[{"type":"password","id":"` + UUID + `","name":"my-password","version_created_at":"` + TIMESTAMP + `","value":"old-password"},{"type":"password","id":"` + UUID + `","name":"my-password","version_created_at":"` + TIMESTAMP + `","value":"new-password"}]}`
 
 				server.RouteToHandler("GET", "/api/v1/data",
@@ -307,7 +483,7 @@ my-ca
 
 				session := runCommand("get", "-n", "my-password", "--versions", "2", "-k", "someflag")
 				Eventually(session).Should(Exit(1))
-				Eventually(session.Err).Should(Say("The --version flag and --key flag are incompatible"))
+				Eventually(session.Err).Should(Say(`The key "someflag" was not found in the credential value.`))
 			})
 		})
 	})
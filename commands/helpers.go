@@ -1,17 +1,15 @@
 package commands
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
 	"net/http"
 	"os"
+	"sync"
 
 	"code.cloudfoundry.org/credhub-cli/config"
 	"code.cloudfoundry.org/credhub-cli/credhub"
 	"code.cloudfoundry.org/credhub-cli/credhub/auth"
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials"
 	"code.cloudfoundry.org/credhub-cli/errors"
-	"gopkg.in/yaml.v2"
 )
 
 func initializeCredhubClient(cfg config.Config) (*credhub.CredHub, error) {
@@ -35,21 +33,6 @@ func initializeCredhubClient(cfg config.Config) (*credhub.CredHub, error) {
 	return credhubClient, err
 }
 
-func printCredential(outputJson bool, v interface{}) {
-	if outputJson {
-
-		s, _ := json.MarshalIndent(v, "", "\t")
-		a := bytes.Replace(s, []byte("\\u003c"), []byte("<"), -1)
-		b := bytes.Replace(a, []byte("\\u003e"), []byte(">"), -1)
-
-		fmt.Println(string(b))
-
-	} else {
-		s, _ := yaml.Marshal(v)
-		fmt.Println(string(s))
-	}
-}
-
 func readConfigFromEnvironmentVariables(cfg *config.Config) error {
 	if cfg.CaCerts == nil && os.Getenv("CREDHUB_CA_CERT") != "" {
 		caCerts, err := ReadOrGetCaCerts([]string{os.Getenv("CREDHUB_CA_CERT")})
@@ -77,6 +60,30 @@ func readConfigFromEnvironmentVariables(cfg *config.Config) error {
 }
 
 func newCredhubClient(cfg *config.Config, clientId string, clientSecret string, usingClientCredentials bool) (*credhub.CredHub, error) {
+	if cfg.BearerToken != "" {
+		return credhub.New(cfg.ApiURL,
+			credhub.CaCerts(cfg.CaCerts...),
+			credhub.SkipTLSValidation(cfg.InsecureSkipVerify),
+			credhub.Auth(auth.StaticToken(cfg.BearerToken)),
+			credhub.AuthURL(cfg.AuthURL))
+	}
+
+	if cfg.UsesClientCertificate() {
+		return credhub.New(cfg.ApiURL,
+			credhub.CaCerts(cfg.CaCerts...),
+			credhub.SkipTLSValidation(cfg.InsecureSkipVerify),
+			credhub.ClientCert(cfg.ClientCertPath, cfg.ClientKeyPath),
+			credhub.AuthURL(cfg.AuthURL))
+	}
+
+	if cfg.CredentialProcess != "" {
+		return credhub.New(cfg.ApiURL,
+			credhub.CaCerts(cfg.CaCerts...),
+			credhub.SkipTLSValidation(cfg.InsecureSkipVerify),
+			credhub.Auth(auth.CredentialProcess(cfg.CredentialProcess)),
+			credhub.AuthURL(cfg.AuthURL))
+	}
+
 	credhubClient, err := credhub.New(cfg.ApiURL, credhub.CaCerts(cfg.CaCerts...), credhub.SkipTLSValidation(cfg.InsecureSkipVerify), credhub.Auth(auth.Uaa(
 		clientId,
 		clientSecret,
@@ -90,6 +97,96 @@ func newCredhubClient(cfg *config.Config, clientId string, clientSecret string,
 	return credhubClient, err
 }
 
+// newTargetClient returns a client for a target other than the current one,
+// authenticating with the tokens or client credentials saved in its config
+func newTargetClient(cfg config.Config) (*credhub.CredHub, error) {
+	if err := config.ValidateConfig(cfg); err != nil {
+		return nil, err
+	}
+
+	if cfg.ClientID != "" {
+		return newCredhubClient(&cfg, cfg.ClientID, cfg.ClientSecret, true)
+	}
+
+	return newCredhubClient(&cfg, config.AuthClient, config.AuthPassword, false)
+}
+
+// forEachConcurrently calls fn with each index from 0 to count-1, with up to
+// concurrency calls at the same time, and returns when every call has returned
+func forEachConcurrently(count, concurrency int, fn func(index int)) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var (
+		wg    sync.WaitGroup
+		queue = make(chan int)
+	)
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range queue {
+				fn(index)
+			}
+		}()
+	}
+
+	for i := 0; i < count; i++ {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+}
+
+// firstError returns the first error that is not nil, if any
+func firstError(errs []error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fetchLatestVersions gets the latest version of the credentials, in the same
+// order, with up to concurrency requests at the same time
+func fetchLatestVersions(client *credhub.CredHub, names []string, concurrency int) ([]credentials.Credential, error) {
+	creds := make([]credentials.Credential, len(names))
+	errs := make([]error, len(names))
+
+	forEachConcurrently(len(names), concurrency, func(index int) {
+		creds[index], errs[index] = client.GetLatestVersion(names[index])
+	})
+
+	return creds, firstError(errs)
+}
+
+// fetchEachLatestVersion gets the latest version of the credentials like
+// fetchLatestVersions, but carries on when one cannot be fetched. The fetched
+// credentials are returned in the same order, with the errors of the others.
+func fetchEachLatestVersion(client *credhub.CredHub, names []string, concurrency int) ([]credentials.Credential, map[string]error) {
+	creds := make([]credentials.Credential, len(names))
+	errs := make([]error, len(names))
+
+	forEachConcurrently(len(names), concurrency, func(index int) {
+		creds[index], errs[index] = client.GetLatestVersion(names[index])
+	})
+
+	fetched := []credentials.Credential{}
+	failures := map[string]error{}
+	for i, err := range errs {
+		if err != nil {
+			failures[names[i]] = err
+			continue
+		}
+		fetched = append(fetched, creds[i])
+	}
+
+	return fetched, failures
+}
+
 func clientCredentialsInEnvironment() bool {
 	return os.Getenv("CREDHUB_CLIENT") != "" || os.Getenv("CREDHUB_SECRET") != ""
 }
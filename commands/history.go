@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"code.cloudfoundry.org/credhub-cli/errors"
+)
+
+type HistoryCommand struct {
+	CredentialIdentifier string `short:"n" long:"name" description:"Name of the credential to show the history of"`
+	PathIdentifier       string `short:"p" long:"path" description:"Show the history of every credential under the provided path"`
+	ClientCommand
+}
+
+type historyEntry struct {
+	Name             string `json:"name" yaml:"name"`
+	Id               string `json:"id" yaml:"id"`
+	VersionCreatedAt string `json:"version_created_at" yaml:"version_created_at"`
+	Type             string `json:"type" yaml:"type"`
+	Fingerprint      string `json:"fingerprint" yaml:"fingerprint"`
+}
+
+func (c *HistoryCommand) ResolveNames() {
+	c.CredentialIdentifier = c.resolveName(c.CredentialIdentifier)
+	c.PathIdentifier = c.resolveName(c.PathIdentifier)
+}
+
+func (c *HistoryCommand) Execute([]string) error {
+	if (c.CredentialIdentifier == "") == (c.PathIdentifier == "") {
+		return errors.NewHistoryParametersError()
+	}
+
+	names := []string{c.CredentialIdentifier}
+
+	if c.PathIdentifier != "" {
+		results, err := c.client.FindByPath(c.PathIdentifier)
+		if err != nil {
+			return err
+		}
+
+		if len(results.Credentials) == 0 {
+			return errors.NewNoMatchingCredentialsFoundError()
+		}
+
+		names = nil
+		for _, credential := range results.Credentials {
+			names = append(names, credential.Name)
+		}
+	}
+
+	hasher, err := newValueHasher()
+	if err != nil {
+		return err
+	}
+
+	entries := []historyEntry{}
+	for _, name := range names {
+		versions, err := c.client.GetAllVersions(name)
+		if err != nil {
+			return err
+		}
+
+		for _, version := range versions {
+			entries = append(entries, historyEntry{
+				Name:             version.Name,
+				Id:               version.Id,
+				VersionCreatedAt: version.VersionCreatedAt,
+				Type:             version.Type,
+				Fingerprint:      hasher.fingerprint(version),
+			})
+		}
+	}
+
+	return printCredential(false, map[string][]historyEntry{"versions": entries})
+}
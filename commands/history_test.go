@@ -0,0 +1,192 @@
+package commands_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+	. "github.com/onsi/gomega/ghttp"
+)
+
+type historyVersion struct {
+	Name             string `json:"name"`
+	Id               string `json:"id"`
+	VersionCreatedAt string `json:"version_created_at"`
+	Type             string `json:"type"`
+	Fingerprint      string `json:"fingerprint"`
+}
+
+var _ = Describe("History", func() {
+	BeforeEach(func() {
+		login()
+	})
+
+	ItRequiresAuthentication("history", "-n", "test-credential")
+	ItRequiresAnAPIToBeSet("history", "-n", "test-credential")
+	ItAutomaticallyLogsIn("GET", "get_response.json", "/api/v1/data", "history", "-n", "test-credential")
+
+	ItBehavesLikeHelp("history", "history", func(session *Session) {
+		Expect(session.Err).To(Say("history"))
+		Expect(session.Err).To(Say("name"))
+		Expect(session.Err).To(Say("path"))
+	})
+
+	versionJSON := func(name, id, credentialType string, value interface{}) string {
+		encoded, _ := json.Marshal(value)
+		return `{"type":"` + credentialType + `","id":"` + id + `","name":"` + name + `","version_created_at":"` + TIMESTAMP + `","value":` + string(encoded) + `}`
+	}
+
+	respondWithVersions := func(name string, versions ...string) {
+		data := ""
+		for i, version := range versions {
+			if i > 0 {
+				data += ","
+			}
+			data += version
+		}
+
+		server.RouteToHandler("GET", "/api/v1/data",
+			CombineHandlers(
+				VerifyRequest("GET", "/api/v1/data", "name="+name),
+				RespondWith(http.StatusOK, `{"data":[`+data+`]}`),
+			),
+		)
+	}
+
+	It("lists every version with a hash of secret values keyed for the run", func() {
+		respondWithVersions("my-password",
+			versionJSON("/my-password", "new-id", "password", "new-password"),
+			versionJSON("/my-password", "rolled-back-id", "password", "old-password"),
+			versionJSON("/my-password", "old-id", "password", "old-password"))
+
+		session := runCommand("history", "-n", "my-password", "--output", "json")
+
+		Eventually(session).Should(Exit(0))
+
+		var output struct {
+			Versions []historyVersion `json:"versions"`
+		}
+		Expect(json.Unmarshal(session.Out.Contents(), &output)).To(Succeed())
+		Expect(output.Versions).To(HaveLen(3))
+		Expect(output.Versions[0]).To(Equal(historyVersion{Name: "/my-password", Id: "new-id", VersionCreatedAt: TIMESTAMP, Type: "password", Fingerprint: output.Versions[0].Fingerprint}))
+		Expect(output.Versions[0].Fingerprint).To(MatchRegexp(`^hmac:[0-9a-f]{12}$`))
+		Expect(output.Versions[0].Fingerprint).NotTo(Equal(output.Versions[1].Fingerprint))
+		Expect(output.Versions[1].Fingerprint).To(Equal(output.Versions[2].Fingerprint))
+		Expect(string(session.Out.Contents())).NotTo(ContainSubstring(`"new-password"`))
+		Expect(string(session.Out.Contents())).NotTo(ContainSubstring(shortHash("new-password")))
+
+		again := runCommand("history", "-n", "my-password", "--output", "json")
+		Eventually(again).Should(Exit(0))
+		Expect(string(again.Out.Contents())).NotTo(ContainSubstring(output.Versions[0].Fingerprint))
+	})
+
+	It("fingerprints certificates by serial and expiry", func() {
+		certificate := selfSignedCertificate("example.com", nil, time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+		block, _ := pem.Decode([]byte(certificate))
+		parsed, _ := x509.ParseCertificate(block.Bytes)
+
+		respondWithVersions("my-certificate",
+			versionJSON("/my-certificate", "cert-id", "certificate", map[string]string{"certificate": certificate, "private_key": "some-private-key"}))
+
+		session := runCommand("history", "-n", "my-certificate", "--output", "jsonpath=.versions[0].fingerprint")
+
+		Eventually(session).Should(Exit(0))
+		Expect(string(session.Out.Contents())).To(Equal("serial " + parsed.SerialNumber.Text(16) + ", expires 2030-01-01T00:00:00Z\n"))
+	})
+
+	It("fingerprints RSA and SSH keys by their public key", func() {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).NotTo(HaveOccurred())
+		der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		Expect(err).NotTo(HaveOccurred())
+		publicKey := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+		rsaSum := sha256.Sum256(der)
+
+		sshKey := []byte("some-ssh-public-key")
+		sshSum := sha256.Sum256(sshKey)
+
+		respondWithVersions("my-key",
+			versionJSON("/my-key", "rsa-id", "rsa", map[string]string{"public_key": publicKey, "private_key": "some-private-key"}),
+			versionJSON("/my-key", "ssh-id", "ssh", map[string]string{"public_key": "ssh-rsa " + base64.StdEncoding.EncodeToString(sshKey) + " comment", "private_key": "some-private-key"}),
+			versionJSON("/my-key", "ssh-with-fingerprint-id", "ssh", map[string]string{"public_key": "ssh-rsa AAAA", "private_key": "some-private-key", "public_key_fingerprint": "server-fingerprint"}))
+
+		session := runCommand("history", "-n", "my-key", "--output", "jsonpath=.versions[*].fingerprint")
+
+		Eventually(session).Should(Exit(0))
+		Expect(string(session.Out.Contents())).To(Equal(
+			"SHA256:" + base64.RawStdEncoding.EncodeToString(rsaSum[:]) + "\n" +
+				"SHA256:" + base64.RawStdEncoding.EncodeToString(sshSum[:]) + "\n" +
+				"SHA256:server-fingerprint\n"))
+		Expect(string(session.Out.Contents())).NotTo(ContainSubstring("some-private-key"))
+	})
+
+	It("shows the username of user credentials", func() {
+		respondWithVersions("my-user",
+			versionJSON("/my-user", "user-id", "user", map[string]string{"username": "admin", "password": "some-password", "password_hash": "some-hash"}))
+
+		session := runCommand("history", "-n", "my-user", "--output", "jsonpath=.versions[0].fingerprint")
+
+		Eventually(session).Should(Exit(0))
+		Expect(string(session.Out.Contents())).To(MatchRegexp(`^admin hmac:[0-9a-f]{12}\n$`))
+	})
+
+	It("lists the versions of every credential under a path", func() {
+		server.RouteToHandler("GET", "/api/v1/data",
+			func(w http.ResponseWriter, r *http.Request) {
+				query := r.URL.Query()
+				switch {
+				case query.Get("path") == "/deployment":
+					w.Write([]byte(`{"credentials":[{"name":"/deployment/a","version_created_at":"` + TIMESTAMP + `"},{"name":"/deployment/b","version_created_at":"` + TIMESTAMP + `"}]}`))
+				case query.Get("name") == "/deployment/a":
+					w.Write([]byte(`{"data":[` + versionJSON("/deployment/a", "a-id", "value", "a") + `]}`))
+				case query.Get("name") == "/deployment/b":
+					w.Write([]byte(`{"data":[` + versionJSON("/deployment/b", "b2-id", "value", "b2") + `,` + versionJSON("/deployment/b", "b1-id", "value", "b1") + `]}`))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			},
+		)
+
+		session := runCommand("history", "-p", "/deployment", "--output", "table")
+
+		Eventually(session).Should(Exit(0))
+		Expect(session.Out).To(Say(`NAME\s+TYPE\s+ID\s+VERSION CREATED AT\s+FINGERPRINT\n`))
+		Expect(session.Out).To(Say(`/deployment/a\s+value\s+a-id\s+` + TIMESTAMP + `\s+hmac:[0-9a-f]{12}\n`))
+		Expect(session.Out).To(Say(`/deployment/b\s+value\s+b2-id\s+` + TIMESTAMP + `\s+hmac:[0-9a-f]{12}\n`))
+		Expect(session.Out).To(Say(`/deployment/b\s+value\s+b1-id\s+` + TIMESTAMP + `\s+hmac:[0-9a-f]{12}\n`))
+	})
+
+	It("returns an error when the path contains no credentials", func() {
+		server.RouteToHandler("GET", "/api/v1/data",
+			CombineHandlers(
+				VerifyRequest("GET", "/api/v1/data", "path=/empty"),
+				RespondWith(http.StatusOK, `{"credentials":[]}`),
+			),
+		)
+
+		session := runCommand("history", "-p", "/empty")
+
+		Eventually(session).Should(Exit(1))
+		Expect(session.Err).To(Say("No credentials exist which match the provided parameters."))
+	})
+
+	It("requires exactly one of a name or a path", func() {
+		session := runCommand("history")
+		Eventually(session).Should(Exit(1))
+		Expect(session.Err).To(Say("Either a name or a path must be provided. Please update and retry your request."))
+
+		session = runCommand("history", "-n", "some-name", "-p", "/some-path")
+		Eventually(session).Should(Exit(1))
+		Expect(session.Err).To(Say("Either a name or a path must be provided. Please update and retry your request."))
+	})
+})
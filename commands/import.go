@@ -2,24 +2,71 @@ package commands
 
 import (
 	"fmt"
+	"io/ioutil"
 
 	"os"
 
 	"reflect"
 
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials"
 	"code.cloudfoundry.org/credhub-cli/errors"
 	"code.cloudfoundry.org/credhub-cli/models"
 )
 
 type ImportCommand struct {
-	File string `short:"f" long:"file" description:"File containing credentials to import" required:"true"`
+	File   string `short:"f" long:"file" description:"File containing credentials to import" required:"true"`
+	Format string `long:"format" default:"credhub" description:"Format of the file: 'credhub' for a list of credentials under the key 'credentials', or 'vars-store' for a BOSH vars-store file"`
+	Path   string `short:"p" long:"path" description:"[vars-store] Path to set the variables under"`
+	Policy string `long:"policy" description:"Policy file every credential must comply with before any are set"`
 	ClientCommand
 }
 
+func (c *ImportCommand) ResolveNames() {
+	c.Path = c.resolvePath(c.Path)
+}
+
 func (c *ImportCommand) Execute([]string) error {
+	data, err := ioutil.ReadFile(c.File)
+	if err != nil {
+		return err
+	}
+
+	if models.IsEncryptedBackup(data) {
+		passphrase, err := readBackupPassphrase(false)
+		if err != nil {
+			return err
+		}
+
+		data, err = models.DecryptBackup(data, passphrase)
+		if err != nil {
+			return errors.NewBackupDecryptError(err)
+		}
+	}
+
 	var bulkImport models.CredentialBulkImport
-	err := bulkImport.ReadFile(c.File)
 
+	switch c.Format {
+	case "credhub":
+		err = bulkImport.ReadBytes(data)
+	case "vars-store":
+		bulkImport, err = models.ReadVarsStore(data, c.Path)
+	default:
+		return errors.NewInvalidFileFormatError(c.Format)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	err = enforcePolicy(c.Policy, func(policy *models.Policy) []models.PolicyViolation {
+		var creds []credentials.Credential
+		for _, credential := range bulkImport.Credentials {
+			name, _ := credential["name"].(string)
+			credType, _ := credential["type"].(string)
+			creds = append(creds, credentials.Credential{Name: name, Type: credType, Value: credential["value"]})
+		}
+		return policy.CheckCredentials(creds)
+	})
 	if err != nil {
 		return err
 	}
@@ -59,7 +106,9 @@ func (c *ImportCommand) setCredentials(bulkImport models.CredentialBulkImport) e
 		} else {
 			successful++
 		}
-		printCredential(false, result)
+		if err := printCredential(false, result); err != nil {
+			return err
+		}
 	}
 
 	fmt.Println("Import complete.")
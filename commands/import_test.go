@@ -1,7 +1,12 @@
 package commands_test
 
 import (
+	"io/ioutil"
 	"net/http"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/credhub-cli/models"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -237,6 +242,82 @@ Failed to set: 2
 		})
 	})
 
+	Describe("when importing an encrypted backup", func() {
+		var backupFile string
+
+		BeforeEach(func() {
+			plaintext, err := ioutil.ReadFile("../test/test_import_file.yml")
+			Expect(err).NotTo(HaveOccurred())
+			backup, err := models.EncryptBackup(plaintext, "some-passphrase")
+			Expect(err).NotTo(HaveOccurred())
+
+			file, err := ioutil.TempFile("", "credhub-backup")
+			Expect(err).NotTo(HaveOccurred())
+			file.Write(backup)
+			file.Close()
+			backupFile = file.Name()
+		})
+
+		AfterEach(func() {
+			os.Remove(backupFile)
+		})
+
+		It("decrypts the backup with the passphrase and sets all the credentials", func() {
+			setUpImportRequests()
+
+			session := runCommandWithEnv([]string{"CREDHUB_BACKUP_PASSPHRASE=some-passphrase"}, "import", "-f", backupFile)
+
+			Eventually(session).Should(Exit(0))
+			Eventually(session.Out).Should(Say(`name: /test/password`))
+			Eventually(session.Out).Should(Say("Import complete."))
+		})
+
+		It("returns an error for the wrong passphrase", func() {
+			session := runCommandWithEnv([]string{"CREDHUB_BACKUP_PASSPHRASE=wrong-passphrase"}, "import", "-f", backupFile)
+
+			Eventually(session).Should(Exit(1))
+			Expect(session.Err).To(Say("The encrypted backup could not be read: the encrypted backup could not be decrypted: the passphrase is incorrect or the file is corrupt"))
+			Expect(server.ReceivedRequests()).To(BeEmpty())
+		})
+	})
+
+	Describe("when importing a BOSH vars-store file", func() {
+		It("sets each variable under the path with the type inferred from its value", func() {
+			file := filepath.Join(homeDir, "vars-store.yml")
+			Expect(ioutil.WriteFile(file, []byte("admin_password: test-password-value\njwt:\n  public_key: public-key\n  private_key: private-key\n"), 0600)).To(Succeed())
+
+			SetupPutValueServer("/deployment/admin_password", "password", "test-password-value")
+			SetupPutRsaServer("/deployment/jwt", "rsa", "public-key", "private-key")
+
+			session := runCommand("import", "-f", file, "--format", "vars-store", "-p", "/deployment")
+
+			Eventually(session).Should(Exit(0))
+			Eventually(session.Out).Should(Say(`name: /deployment/admin_password`))
+			Eventually(session.Out).Should(Say(`name: /deployment/jwt`))
+			Eventually(session.Out).Should(Say("Successfully set: 2"))
+		})
+
+		It("sets the variables under / when no path is provided", func() {
+			file := filepath.Join(homeDir, "vars-store.yml")
+			Expect(ioutil.WriteFile(file, []byte("admin_password: test-password-value\n"), 0600)).To(Succeed())
+
+			SetupPutValueServer("/admin_password", "password", "test-password-value")
+
+			session := runCommand("import", "-f", file, "--format", "vars-store")
+
+			Eventually(session).Should(Exit(0))
+			Eventually(session.Out).Should(Say(`name: /admin_password`))
+			Eventually(session.Out).Should(Say("Successfully set: 1"))
+		})
+
+		It("returns an error for an unknown format", func() {
+			session := runCommand("import", "-f", "../test/test_import_file.yml", "--format", "vars")
+
+			Eventually(session).Should(Exit(1))
+			Expect(session.Err).To(Say(`The format "vars" is not supported. Valid formats are 'credhub' and 'vars-store'.`))
+		})
+	})
+
 	Describe("when no credential tag present in import file", func() {
 		It("prints correct error message", func() {
 			session := runCommand("import", "-f", "../test/test_import_incorrect_format.yml")
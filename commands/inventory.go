@@ -0,0 +1,350 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials"
+	"code.cloudfoundry.org/credhub-cli/errors"
+)
+
+type InventoryCommand struct {
+	Path        string `short:"p" long:"path" description:"Path of the credentials to report on, defaults to the current path"`
+	OlderThan   string `long:"older-than" default:"90d" description:"Age after which a credential that has not been rotated is listed as stale, e.g. 90d, 12w or 36h"`
+	Depth       int    `long:"depth" default:"1" description:"Number of path segments under the path to count the credentials by"`
+	Concurrency int    `long:"concurrency" default:"4" description:"Number of credentials to fetch at the same time"`
+	Prometheus  bool   `long:"prometheus" description:"Return the report in the Prometheus textfile collector format"`
+	ClientCommand
+}
+
+type inventoryReport struct {
+	Path      string                `json:"path" yaml:"path"`
+	Total     int                   `json:"total" yaml:"total"`
+	OlderThan string                `json:"older_than" yaml:"older_than"`
+	Types     []inventoryCount      `json:"types" yaml:"types"`
+	Paths     []inventoryCount      `json:"paths" yaml:"paths"`
+	Ages      []inventoryCount      `json:"ages" yaml:"ages"`
+	Versions  []inventoryCredential `json:"versions" yaml:"versions"`
+	Stale     []inventoryCredential `json:"stale" yaml:"stale"`
+
+	ageSeconds []int64
+	threshold  time.Duration
+}
+
+// inventoryCount is the number of credentials of a type, under a path or in an
+// age bucket; only one of Type, Path and Age is set
+type inventoryCount struct {
+	Type        string `json:"type,omitempty" yaml:"type,omitempty"`
+	Path        string `json:"path,omitempty" yaml:"path,omitempty"`
+	Age         string `json:"age,omitempty" yaml:"age,omitempty"`
+	Credentials int    `json:"credentials" yaml:"credentials"`
+}
+
+type inventoryCredential struct {
+	Name             string `json:"name" yaml:"name"`
+	Type             string `json:"type" yaml:"type"`
+	VersionCreatedAt string `json:"version_created_at" yaml:"version_created_at"`
+	AgeDays          int    `json:"age_days" yaml:"age_days"`
+	Versions         int    `json:"versions" yaml:"versions"`
+
+	age   time.Duration
+	known bool
+}
+
+// inventoryAgeBuckets are the upper bounds, in days, of the age histogram.
+// Each bucket holds the credentials older than the previous bound and at most
+// as old as its own, and a last bucket holds everything older.
+var inventoryAgeBuckets = []int{7, 30, 90, 180, 365}
+
+func (c *InventoryCommand) ResolveNames() {
+	c.Path = c.resolvePath(c.Path)
+}
+
+func (c *InventoryCommand) Execute([]string) error {
+	threshold, err := parseAge(c.OlderThan)
+	if err != nil {
+		return err
+	}
+
+	prefix := path.Clean("/" + c.Path)
+
+	found, err := c.client.FindByPath(prefix)
+	if err != nil {
+		return err
+	}
+
+	if len(found.Credentials) == 0 {
+		return errors.NewNoMatchingCredentialsFoundError()
+	}
+
+	var names []string
+	for _, credential := range found.Credentials {
+		names = append(names, credential.Name)
+	}
+
+	versions, err := c.fetchAll(names)
+	if err != nil {
+		return err
+	}
+
+	report := newInventoryReport(prefix, c.OlderThan, threshold, c.Depth, versions, time.Now())
+
+	switch {
+	case c.Prometheus:
+		return printInventoryPrometheus(os.Stdout, report)
+	case structuredOutput(false):
+		return printCredential(false, report)
+	default:
+		return printInventoryTables(report)
+	}
+}
+
+// fetchAll gets every version of the credentials concurrently
+func (c *InventoryCommand) fetchAll(names []string) ([][]credentials.Credential, error) {
+	versions := make([][]credentials.Credential, len(names))
+	errs := make([]error, len(names))
+
+	forEachConcurrently(len(names), c.Concurrency, func(index int) {
+		versions[index], errs[index] = c.client.GetAllVersions(names[index])
+	})
+
+	return versions, firstError(errs)
+}
+
+// newInventoryReport summarizes the versions of each credential, newest first,
+// as of now. Credentials are counted by the first depth path segments under
+// the prefix, and credentials whose latest version is at least threshold old
+// are stale.
+func newInventoryReport(prefix, olderThan string, threshold time.Duration, depth int, all [][]credentials.Credential, now time.Time) inventoryReport {
+	report := inventoryReport{
+		Path:      prefix,
+		OlderThan: olderThan,
+		Versions:  []inventoryCredential{},
+		Stale:     []inventoryCredential{},
+		threshold: threshold,
+	}
+
+	byType := map[string]int{}
+	byPath := map[string]int{}
+	byAge := make([]int, len(inventoryAgeBuckets)+1)
+	unknownAge := 0
+
+	for _, versions := range all {
+		if len(versions) == 0 {
+			continue
+		}
+		latest := versions[0]
+
+		credential := inventoryCredential{
+			Name:             absoluteName(latest.Name),
+			Type:             latest.Type,
+			VersionCreatedAt: latest.VersionCreatedAt,
+			Versions:         len(versions),
+		}
+
+		if createdAt, err := time.Parse(time.RFC3339, latest.VersionCreatedAt); err == nil {
+			credential.age = now.Sub(createdAt)
+			if credential.age < 0 {
+				credential.age = 0
+			}
+			credential.AgeDays = int(credential.age / (24 * time.Hour))
+			credential.known = true
+		}
+
+		report.Total++
+		report.Versions = append(report.Versions, credential)
+		byType[credential.Type]++
+		byPath[inventoryPrefix(prefix, credential.Name, depth)]++
+
+		if !credential.known {
+			unknownAge++
+			continue
+		}
+
+		byAge[inventoryAgeBucket(credential.age)]++
+		report.ageSeconds = append(report.ageSeconds, int64(credential.age/time.Second))
+		if credential.age >= threshold {
+			report.Stale = append(report.Stale, credential)
+		}
+	}
+
+	sort.Slice(report.Versions, func(i, j int) bool { return report.Versions[i].Name < report.Versions[j].Name })
+	sort.Slice(report.Stale, func(i, j int) bool {
+		if report.Stale[i].age != report.Stale[j].age {
+			return report.Stale[i].age > report.Stale[j].age
+		}
+		return report.Stale[i].Name < report.Stale[j].Name
+	})
+
+	for _, credType := range sortedKeys(byType) {
+		report.Types = append(report.Types, inventoryCount{Type: credType, Credentials: byType[credType]})
+	}
+	for _, p := range sortedKeys(byPath) {
+		report.Paths = append(report.Paths, inventoryCount{Path: p, Credentials: byPath[p]})
+	}
+	for i, count := range byAge {
+		report.Ages = append(report.Ages, inventoryCount{Age: inventoryAgeLabel(i), Credentials: count})
+	}
+	if unknownAge > 0 {
+		report.Ages = append(report.Ages, inventoryCount{Age: "unknown", Credentials: unknownAge})
+	}
+
+	return report
+}
+
+// inventoryPrefix returns the path made of the prefix and the first depth
+// segments of the path of the name under it
+func inventoryPrefix(prefix, name string, depth int) string {
+	root := strings.TrimSuffix(prefix, "/") + "/"
+	relative := strings.TrimPrefix(name, root)
+
+	segments := strings.Split(relative, "/")
+	segments = segments[:len(segments)-1]
+	if depth >= 0 && len(segments) > depth {
+		segments = segments[:depth]
+	}
+	if len(segments) == 0 {
+		return root
+	}
+
+	return root + strings.Join(segments, "/") + "/"
+}
+
+func inventoryAgeBucket(age time.Duration) int {
+	for i, days := range inventoryAgeBuckets {
+		if age <= time.Duration(days)*24*time.Hour {
+			return i
+		}
+	}
+	return len(inventoryAgeBuckets)
+}
+
+func inventoryAgeLabel(bucket int) string {
+	switch bucket {
+	case 0:
+		return fmt.Sprintf("up to %d days", inventoryAgeBuckets[0])
+	case len(inventoryAgeBuckets):
+		return fmt.Sprintf("over %d days", inventoryAgeBuckets[bucket-1])
+	}
+	return fmt.Sprintf("%d to %d days", inventoryAgeBuckets[bucket-1], inventoryAgeBuckets[bucket])
+}
+
+func sortedKeys(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// parseAge parses an age given as a number of days such as 90d, weeks such as
+// 12w, or any duration accepted by time.ParseDuration such as 36h
+func parseAge(value string) (time.Duration, error) {
+	units := map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour}
+
+	if len(value) > 1 {
+		if unit, ok := units[value[len(value)-1:]]; ok {
+			n, err := strconv.Atoi(value[:len(value)-1])
+			if err != nil || n < 0 {
+				return 0, errors.NewInvalidAgeError(value)
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+
+	age, err := time.ParseDuration(value)
+	if err != nil || age < 0 {
+		return 0, errors.NewInvalidAgeError(value)
+	}
+	return age, nil
+}
+
+func printInventoryTables(report inventoryReport) error {
+	fmt.Printf("%d credential(s) under %s\n", report.Total, report.Path)
+
+	sections := []struct {
+		title string
+		key   string
+		items interface{}
+	}{
+		{"By type", "types", report.Types},
+		{"By path", "paths", report.Paths},
+		{"By age of the latest version", "ages", report.Ages},
+		{"Versions", "versions", report.Versions},
+		{fmt.Sprintf("Not rotated in %s (%d)", report.OlderThan, len(report.Stale)), "stale", report.Stale},
+	}
+
+	for _, section := range sections {
+		fmt.Printf("\n%s:\n", section.title)
+		if section.key == "stale" && len(report.Stale) == 0 {
+			continue
+		}
+		if err := (OutputFormat{Name: "table"}).Print(os.Stdout, map[string]interface{}{section.key: section.items}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// printInventoryPrometheus writes the report as metrics for the textfile
+// collector of the Prometheus node exporter. Ages are in seconds, as
+// Prometheus expects.
+func printInventoryPrometheus(w io.Writer, report inventoryReport) error {
+	pathLabel := `path="` + prometheusLabelValue(report.Path) + `"`
+
+	fmt.Fprintln(w, "# HELP credhub_credentials Number of credentials by type.")
+	fmt.Fprintln(w, "# TYPE credhub_credentials gauge")
+	for _, count := range report.Types {
+		fmt.Fprintf(w, "credhub_credentials{%s,type=\"%s\"} %d\n", pathLabel, prometheusLabelValue(count.Type), count.Credentials)
+	}
+
+	fmt.Fprintln(w, "# HELP credhub_credentials_by_path Number of credentials by path prefix.")
+	fmt.Fprintln(w, "# TYPE credhub_credentials_by_path gauge")
+	for _, count := range report.Paths {
+		fmt.Fprintf(w, "credhub_credentials_by_path{path=\"%s\"} %d\n", prometheusLabelValue(count.Path), count.Credentials)
+	}
+
+	fmt.Fprintln(w, "# HELP credhub_credential_age_seconds Age of the latest version of the credentials.")
+	fmt.Fprintln(w, "# TYPE credhub_credential_age_seconds histogram")
+	var sum int64
+	for _, seconds := range report.ageSeconds {
+		sum += seconds
+	}
+	for _, days := range inventoryAgeBuckets {
+		bound := int64(days) * 24 * 60 * 60
+		cumulative := 0
+		for _, seconds := range report.ageSeconds {
+			if seconds <= bound {
+				cumulative++
+			}
+		}
+		fmt.Fprintf(w, "credhub_credential_age_seconds_bucket{%s,le=\"%d\"} %d\n", pathLabel, bound, cumulative)
+	}
+	fmt.Fprintf(w, "credhub_credential_age_seconds_bucket{%s,le=\"+Inf\"} %d\n", pathLabel, len(report.ageSeconds))
+	fmt.Fprintf(w, "credhub_credential_age_seconds_sum{%s} %d\n", pathLabel, sum)
+	fmt.Fprintf(w, "credhub_credential_age_seconds_count{%s} %d\n", pathLabel, len(report.ageSeconds))
+
+	fmt.Fprintln(w, "# HELP credhub_credential_versions Number of versions of each credential.")
+	fmt.Fprintln(w, "# TYPE credhub_credential_versions gauge")
+	for _, credential := range report.Versions {
+		fmt.Fprintf(w, "credhub_credential_versions{name=\"%s\",type=\"%s\"} %d\n", prometheusLabelValue(credential.Name), prometheusLabelValue(credential.Type), credential.Versions)
+	}
+
+	fmt.Fprintln(w, "# HELP credhub_credentials_stale Number of credentials not rotated within the threshold.")
+	fmt.Fprintln(w, "# TYPE credhub_credentials_stale gauge")
+	fmt.Fprintf(w, "credhub_credentials_stale{%s,threshold_seconds=\"%d\"} %d\n", pathLabel, int64(report.threshold/time.Second), len(report.Stale))
+
+	return nil
+}
+
+func prometheusLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,159 @@
+package commands_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("Inventory", func() {
+	var stored map[string][]string
+
+	daysAgo := func(days int) string {
+		return time.Now().Add(-time.Duration(days)*24*time.Hour - time.Hour).UTC().Format(time.RFC3339)
+	}
+
+	credentialJSON := func(name, credType, createdAt string) string {
+		return `{"type":"` + credType + `","id":"` + UUID + `","name":"` + name + `","version_created_at":"` + createdAt + `","value":"some-value"}`
+	}
+
+	BeforeEach(func() {
+		login()
+
+		stored = map[string][]string{
+			"/team/db/password": {
+				credentialJSON("/team/db/password", "password", daysAgo(120)),
+				credentialJSON("/team/db/password", "password", daysAgo(300)),
+				credentialJSON("/team/db/password", "password", daysAgo(400)),
+			},
+			"/team/db/admin": {
+				credentialJSON("/team/db/admin", "user", daysAgo(3)),
+			},
+			"/team/web/tls": {
+				credentialJSON("/team/web/tls", "certificate", daysAgo(500)),
+				credentialJSON("/team/web/tls", "certificate", daysAgo(900)),
+			},
+			"/team/web/nested/key": {
+				credentialJSON("/team/web/nested/key", "rsa", daysAgo(45)),
+			},
+			"/team/url": {
+				credentialJSON("/team/url", "value", daysAgo(10)),
+			},
+		}
+
+		server.RouteToHandler("GET", "/api/v1/data", func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("path") != "" {
+				var names []string
+				for name := range stored {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+
+				found := []map[string]string{}
+				for _, name := range names {
+					found = append(found, map[string]string{"name": name, "version_created_at": TIMESTAMP})
+				}
+				json.NewEncoder(w).Encode(map[string]interface{}{"credentials": found})
+				return
+			}
+			w.Write([]byte(`{"data":[` + strings.Join(stored[r.URL.Query().Get("name")], ",") + `]}`))
+		})
+	})
+
+	ItRequiresAuthentication("inventory", "-p", "/team")
+	ItRequiresAnAPIToBeSet("inventory", "-p", "/team")
+
+	ItBehavesLikeHelp("inventory", "inventory", func(session *Session) {
+		Expect(session.Err).To(Say("older-than"))
+		Expect(session.Err).To(Say("prometheus"))
+	})
+
+	It("reports the credentials by type, path and age", func() {
+		session := runCommand("inventory", "-p", "/team", "--concurrency", "2")
+
+		Eventually(session).Should(Exit(0))
+		Expect(session.Out).To(Say(`5 credential\(s\) under /team\n`))
+		Expect(session.Out).To(Say(`By type:\nTYPE\s+CREDENTIALS\ncertificate\s+1\npassword\s+1\nrsa\s+1\nuser\s+1\nvalue\s+1\n`))
+		Expect(session.Out).To(Say(`By path:\nPATH\s+CREDENTIALS\n/team/\s+1\n/team/db/\s+2\n/team/web/\s+2\n`))
+		Expect(session.Out).To(Say(`By age of the latest version:\nAGE\s+CREDENTIALS\nup to 7 days\s+1\n7 to 30 days\s+1\n30 to 90 days\s+1\n90 to 180 days\s+1\n180 to 365 days\s+0\nover 365 days\s+1\n`))
+		Expect(session.Out).To(Say(`Versions:\nNAME\s+TYPE\s+VERSION CREATED AT\s+AGE DAYS\s+VERSIONS\n`))
+		Expect(session.Out).To(Say(`/team/db/admin\s+user\s+\S+\s+3\s+1\n`))
+		Expect(session.Out).To(Say(`/team/db/password\s+password\s+\S+\s+120\s+3\n`))
+		Expect(session.Out).To(Say(`/team/web/tls\s+certificate\s+\S+\s+500\s+2\n`))
+		Expect(session.Out).To(Say(`Not rotated in 90d \(2\):\nNAME\s+TYPE\s+VERSION CREATED AT\s+AGE DAYS\s+VERSIONS\n/team/web/tls\s+.*\n/team/db/password\s+.*\n$`))
+	})
+
+	It("counts by more path segments with --depth", func() {
+		session := runCommand("inventory", "-p", "/team", "--depth", "2")
+
+		Eventually(session).Should(Exit(0))
+		Expect(session.Out).To(Say(`PATH\s+CREDENTIALS\n/team/\s+1\n/team/db/\s+2\n/team/web/\s+1\n/team/web/nested/\s+1\n`))
+	})
+
+	It("lists the credentials not rotated within --older-than", func() {
+		session := runCommand("inventory", "-p", "/team", "--older-than", "6w", "--output", "json")
+
+		Eventually(session).Should(Exit(0))
+
+		var report struct {
+			Total     int                      `json:"total"`
+			OlderThan string                   `json:"older_than"`
+			Types     []map[string]interface{} `json:"types"`
+			Stale     []map[string]interface{} `json:"stale"`
+			Versions  []map[string]interface{} `json:"versions"`
+		}
+		Expect(json.Unmarshal(session.Out.Contents(), &report)).To(Succeed())
+		Expect(report.Total).To(Equal(5))
+		Expect(report.OlderThan).To(Equal("6w"))
+		Expect(report.Types[0]).To(Equal(map[string]interface{}{"type": "certificate", "credentials": float64(1)}))
+
+		var stale []interface{}
+		for _, credential := range report.Stale {
+			stale = append(stale, credential["name"])
+		}
+		Expect(stale).To(Equal([]interface{}{"/team/web/tls", "/team/db/password", "/team/web/nested/key"}))
+		Expect(report.Versions[1]["versions"]).To(Equal(float64(3)))
+	})
+
+	It("returns the report in the Prometheus textfile collector format", func() {
+		session := runCommand("inventory", "-p", "/team", "--prometheus")
+
+		Eventually(session).Should(Exit(0))
+		Expect(session.Out).To(Say(`# TYPE credhub_credentials gauge\n`))
+		Expect(session.Out).To(Say(`credhub_credentials{path="/team",type="certificate"} 1\n`))
+		Expect(session.Out).To(Say(`credhub_credentials_by_path{path="/team/db/"} 2\n`))
+		Expect(session.Out).To(Say(`# TYPE credhub_credential_age_seconds histogram\n`))
+		Expect(session.Out).To(Say(`credhub_credential_age_seconds_bucket{path="/team",le="604800"} 1\n`))
+		Expect(session.Out).To(Say(`credhub_credential_age_seconds_bucket{path="/team",le="2592000"} 2\n`))
+		Expect(session.Out).To(Say(`credhub_credential_age_seconds_bucket{path="/team",le="31536000"} 4\n`))
+		Expect(session.Out).To(Say(`credhub_credential_age_seconds_bucket{path="/team",le="\+Inf"} 5\n`))
+		Expect(session.Out).To(Say(`credhub_credential_age_seconds_count{path="/team"} 5\n`))
+		Expect(session.Out).To(Say(`credhub_credential_versions{name="/team/db/password",type="password"} 3\n`))
+		Expect(session.Out).To(Say(`credhub_credentials_stale{path="/team",threshold_seconds="7776000"} 2\n`))
+	})
+
+	It("returns an error when the age is not valid", func() {
+		session := runCommand("inventory", "-p", "/team", "--older-than", "90 days")
+
+		Eventually(session).Should(Exit(1))
+		Expect(session.Err).To(Say(`The age "90 days" is not valid.`))
+	})
+
+	It("returns an error when there are no credentials under the path", func() {
+		server.RouteToHandler("GET", "/api/v1/data", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"credentials":[]}`))
+		})
+
+		session := runCommand("inventory", "-p", "/empty")
+
+		Eventually(session).Should(Exit(1))
+		Expect(session.Err).To(Say("No credentials exist which match the provided parameters."))
+	})
+})
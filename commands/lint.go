@@ -0,0 +1,175 @@
+package commands
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+
+	"code.cloudfoundry.org/credhub-cli/errors"
+	"code.cloudfoundry.org/credhub-cli/models"
+)
+
+type LintCommand struct {
+	Path        string `short:"p" long:"path" required:"yes" description:"Path of the credentials to lint"`
+	Policy      string `long:"policy" required:"yes" description:"Policy file with the rules to check the credentials against"`
+	JUnit       bool   `long:"junit" description:"Return the violations as JUnit XML"`
+	Concurrency int    `long:"concurrency" default:"4" description:"Number of credentials to fetch at the same time"`
+	ClientCommand
+}
+
+type lintResult struct {
+	Path       string                   `json:"path" yaml:"path"`
+	Checked    int                      `json:"checked" yaml:"checked"`
+	Violations []models.PolicyViolation `json:"violations" yaml:"violations"`
+}
+
+func (c *LintCommand) ResolveNames() {
+	c.Path = c.resolvePath(c.Path)
+}
+
+func (c *LintCommand) Execute([]string) error {
+	var policy models.Policy
+	if err := policy.ReadFile(c.Policy); err != nil {
+		return err
+	}
+
+	prefix := c.Path
+
+	found, err := c.client.FindByPath(prefix)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, credential := range found.Credentials {
+		names = append(names, credential.Name)
+	}
+
+	creds, failures := fetchEachLatestVersion(c.client, names, c.Concurrency)
+
+	result := lintResult{Path: prefix, Checked: len(names), Violations: policy.CheckCredentials(creds)}
+	for _, name := range names {
+		if fetchErr, ok := failures[name]; ok {
+			result.Violations = append(result.Violations, models.PolicyViolation{
+				Name:     absoluteName(name),
+				Rule:     "fetch",
+				Severity: models.SeverityError,
+				Message:  "the credential could not be fetched: " + fetchErr.Error(),
+			})
+		}
+	}
+
+	switch {
+	case c.JUnit:
+		err = printLintJUnit(result, names)
+	case structuredOutput(false):
+		err = printCredential(false, result)
+	default:
+		err = printLintTable(result)
+	}
+	if err != nil {
+		return err
+	}
+
+	if failed := models.PolicyErrors(result.Violations); failed > 0 {
+		return errors.NewLintFailedError(failed)
+	}
+
+	return nil
+}
+
+func printLintTable(result lintResult) error {
+	if len(result.Violations) > 0 {
+		if err := (OutputFormat{Name: "table"}).Print(os.Stdout, map[string][]models.PolicyViolation{"violations": result.Violations}); err != nil {
+			return err
+		}
+	}
+
+	failed := models.PolicyErrors(result.Violations)
+	fmt.Printf("%d error(s) and %d warning(s) in %d credential(s) under %s\n",
+		failed, len(result.Violations)-failed, result.Checked, result.Path)
+	return nil
+}
+
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string         `xml:"classname,attr"`
+	Name      string         `xml:"name,attr"`
+	Failures  []junitFailure `xml:"failure,omitempty"`
+	SystemOut string         `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Type    string `xml:"type,attr"`
+	Message string `xml:"message,attr"`
+}
+
+// printLintJUnit prints a test case for each credential, failed by its error
+// violations. Warnings are listed in the output of the test case.
+func printLintJUnit(result lintResult, names []string) error {
+	suite := junitTestSuite{Name: "credhub lint " + result.Path, Tests: len(names)}
+
+	for _, name := range names {
+		testCase := junitTestCase{ClassName: result.Path, Name: absoluteName(name)}
+
+		for _, violation := range result.Violations {
+			if violation.Name != testCase.Name {
+				continue
+			}
+			if violation.Severity == models.SeverityError {
+				testCase.Failures = append(testCase.Failures, junitFailure{Type: violation.Rule, Message: violation.Message})
+			} else {
+				testCase.SystemOut += "warning: [" + violation.Rule + "] " + violation.Message + "\n"
+			}
+		}
+
+		if len(testCase.Failures) > 0 {
+			suite.Failures++
+		}
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	output, err := xml.MarshalIndent(suite, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(xml.Header + string(output))
+	return nil
+}
+
+// enforcePolicy checks what is about to be written against the policy file, if
+// one is given, printing warnings and returning an error for error violations
+func enforcePolicy(file string, check func(*models.Policy) []models.PolicyViolation) error {
+	if file == "" {
+		return nil
+	}
+
+	var policy models.Policy
+	if err := policy.ReadFile(file); err != nil {
+		return err
+	}
+
+	var failures []string
+	for _, violation := range check(&policy) {
+		line := fmt.Sprintf("%s [%s] %s", violation.Name, violation.Rule, violation.Message)
+		if violation.Severity == models.SeverityError {
+			failures = append(failures, " - "+line)
+		} else {
+			fmt.Fprintln(os.Stderr, "Warning: "+line)
+		}
+	}
+
+	if len(failures) > 0 {
+		return errors.NewPolicyViolationsError(failures)
+	}
+
+	return nil
+}
@@ -0,0 +1,210 @@
+package commands_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("Lint", func() {
+	var (
+		policyFile string
+		stored     map[string]string
+		written    []string
+	)
+
+	credentialJSON := func(name, credType string, value interface{}) string {
+		encoded, _ := json.Marshal(value)
+		return `{"type":"` + credType + `","id":"` + UUID + `","name":"` + name + `","version_created_at":"` + TIMESTAMP + `","value":` + string(encoded) + `}`
+	}
+
+	BeforeEach(func() {
+		login()
+
+		policyFile = filepath.Join(homeDir, "policy.yml")
+		Expect(ioutil.WriteFile(policyFile, []byte(`
+password:
+  min_length: 20
+certificate:
+  max_validity_days: 100
+names:
+  deny: ['tmp']
+  severity: warning
+`), 0600)).To(Succeed())
+
+		stored = map[string]string{
+			"/cf/short":    credentialJSON("/cf/short", "password", "short"),
+			"/cf/long":     credentialJSON("/cf/long", "password", "a-password-that-is-long-enough"),
+			"/cf/tmp-cert": credentialJSON("/cf/tmp-cert", "certificate", map[string]string{"certificate": selfSignedCertificate("example", nil, time.Now().AddDate(1, 0, 0))}),
+		}
+		written = nil
+
+		server.RouteToHandler("GET", "/api/v1/data", func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("path") != "" {
+				w.Write([]byte(`{"credentials":[{"name":"/cf/short","version_created_at":"` + TIMESTAMP + `"},{"name":"/cf/long","version_created_at":"` + TIMESTAMP + `"},{"name":"/cf/tmp-cert","version_created_at":"` + TIMESTAMP + `"}]}`))
+				return
+			}
+			w.Write([]byte(`{"data":[` + stored[r.URL.Query().Get("name")] + `]}`))
+		})
+
+		record := func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			written = append(written, body["name"].(string))
+			w.Write([]byte(credentialJSON(body["name"].(string), body["type"].(string), "<redacted>")))
+		}
+		server.RouteToHandler("PUT", "/api/v1/data", record)
+		server.RouteToHandler("POST", "/api/v1/data", record)
+	})
+
+	ItRequiresAuthentication("lint", "-p", "/cf", "--policy", "policy.yml")
+	ItRequiresAnAPIToBeSet("lint", "-p", "/cf", "--policy", "policy.yml")
+
+	ItBehavesLikeHelp("lint", "lint", func(session *Session) {
+		Expect(session.Err).To(Say("path"))
+		Expect(session.Err).To(Say("policy"))
+		Expect(session.Err).To(Say("junit"))
+	})
+
+	It("prints the violations as a table and fails for errors", func() {
+		session := runCommand("lint", "-p", "/cf", "--policy", policyFile, "--concurrency", "2")
+
+		Eventually(session).Should(Exit(1))
+		Expect(session.Out).To(Say(`NAME\s+TYPE\s+MESSAGE\s+RULE\s+SEVERITY`))
+		Expect(session.Out).To(Say(`/cf/short\s+password\s+the password is 5 characters, shorter than the minimum of 20\s+password-length\s+error`))
+		Expect(session.Out).To(Say(`/cf/tmp-cert\s+certificate\s+the name matches the denied pattern "tmp"\s+name-pattern\s+warning`))
+		Expect(session.Out).To(Say(`/cf/tmp-cert\s+certificate\s+the certificate is valid for 36\d days, longer than the maximum of 100\s+certificate-validity\s+error`))
+		Expect(session.Out).To(Say(`2 error\(s\) and 1 warning\(s\) in 3 credential\(s\) under /cf`))
+		Expect(session.Err).To(Say(`2 policy violation\(s\) with the error severity were found.`))
+		Expect(string(session.Out.Contents())).NotTo(ContainSubstring("a-password-that-is-long-enough"))
+	})
+
+	It("succeeds when there are only warnings", func() {
+		Expect(ioutil.WriteFile(policyFile, []byte("names: {deny: ['tmp'], severity: warning}"), 0600)).To(Succeed())
+
+		session := runCommand("lint", "-p", "/cf", "--policy", policyFile)
+
+		Eventually(session).Should(Exit(0))
+		Expect(session.Out).To(Say(`0 error\(s\) and 1 warning\(s\) in 3 credential\(s\) under /cf`))
+	})
+
+	It("returns the violations in JSON format", func() {
+		session := runCommand("lint", "-p", "/cf", "--policy", policyFile, "--output", "json")
+
+		Eventually(session).Should(Exit(1))
+
+		var result struct {
+			Path       string              `json:"path"`
+			Checked    int                 `json:"checked"`
+			Violations []map[string]string `json:"violations"`
+		}
+		Expect(json.Unmarshal(session.Out.Contents(), &result)).To(Succeed())
+		Expect(result.Path).To(Equal("/cf"))
+		Expect(result.Checked).To(Equal(3))
+		Expect(result.Violations).To(HaveLen(3))
+		Expect(result.Violations[0]).To(Equal(map[string]string{
+			"name":     "/cf/short",
+			"type":     "password",
+			"rule":     "password-length",
+			"severity": "error",
+			"message":  "the password is 5 characters, shorter than the minimum of 20",
+		}))
+	})
+
+	It("returns a test case for each credential in JUnit XML", func() {
+		session := runCommand("lint", "-p", "/cf", "--policy", policyFile, "--junit")
+
+		Eventually(session).Should(Exit(1))
+
+		var suite struct {
+			Tests     int `xml:"tests,attr"`
+			Failures  int `xml:"failures,attr"`
+			TestCases []struct {
+				Name      string `xml:"name,attr"`
+				SystemOut string `xml:"system-out"`
+				Failures  []struct {
+					Type    string `xml:"type,attr"`
+					Message string `xml:"message,attr"`
+				} `xml:"failure"`
+			} `xml:"testcase"`
+		}
+		Expect(xml.Unmarshal(session.Out.Contents(), &suite)).To(Succeed())
+		Expect(suite.Tests).To(Equal(3))
+		Expect(suite.Failures).To(Equal(2))
+		Expect(suite.TestCases[0].Name).To(Equal("/cf/short"))
+		Expect(suite.TestCases[0].Failures[0].Type).To(Equal("password-length"))
+		Expect(suite.TestCases[1].Failures).To(BeEmpty())
+		Expect(suite.TestCases[2].Failures[0].Type).To(Equal("certificate-validity"))
+		Expect(suite.TestCases[2].SystemOut).To(ContainSubstring("warning: [name-pattern]"))
+	})
+
+	It("reports credentials that cannot be fetched as errors and checks the others", func() {
+		delete(stored, "/cf/long")
+
+		session := runCommand("lint", "-p", "/cf", "--policy", policyFile)
+
+		Eventually(session).Should(Exit(1))
+		Expect(session.Out).To(Say(`/cf/long\s+the credential could not be fetched: .+\s+fetch\s+error`))
+		Expect(session.Out).To(Say(`3 error\(s\) and 1 warning\(s\) in 3 credential\(s\) under /cf`))
+		Expect(session.Err).To(Say(`3 policy violation\(s\) with the error severity were found.`))
+	})
+
+	It("returns an error for an invalid policy", func() {
+		Expect(ioutil.WriteFile(policyFile, []byte("passwords: {}"), 0600)).To(Succeed())
+
+		session := runCommand("lint", "-p", "/cf", "--policy", policyFile)
+
+		Eventually(session).Should(Exit(1))
+		Expect(session.Err).To(Say("The policy file is not valid: "))
+	})
+
+	Describe("enforcing the policy before writing", func() {
+		It("does not set a credential that violates it", func() {
+			session := runCommand("set", "-n", "/cf/new", "-t", "password", "-w", "short", "--policy", policyFile)
+
+			Eventually(session).Should(Exit(1))
+			Expect(session.Err).To(Say(`The request does not comply with the policy. Nothing was written.\n - /cf/new \[password-length\] the password is 5 characters, shorter than the minimum of 20`))
+			Expect(written).To(BeEmpty())
+		})
+
+		It("sets a credential with only warnings and prints them", func() {
+			session := runCommand("set", "-n", "/cf/tmp", "-t", "password", "-w", "a-password-that-is-long-enough", "--policy", policyFile)
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Err).To(Say(`Warning: /cf/tmp \[name-pattern\] the name matches the denied pattern "tmp"`))
+			Expect(written).To(Equal([]string{"/cf/tmp"}))
+		})
+
+		It("does not generate a credential whose parameters violate it", func() {
+			session := runCommand("generate", "-n", "/cf/new", "-t", "password", "-l", "10", "--policy", policyFile)
+
+			Eventually(session).Should(Exit(1))
+			Expect(session.Err).To(Say(`/cf/new \[password-length\] the password is 10 characters, shorter than the minimum of 20`))
+			Expect(written).To(BeEmpty())
+
+			session = runCommand("generate", "-n", "/cf/new", "-t", "password", "--policy", policyFile)
+
+			Eventually(session).Should(Exit(0))
+			Expect(written).To(Equal([]string{"/cf/new"}))
+		})
+
+		It("does not import any credentials when one violates it", func() {
+			importFile := filepath.Join(homeDir, "import.yml")
+			Expect(ioutil.WriteFile(importFile, []byte("credentials:\n- name: /cf/a\n  type: password\n  value: a-password-that-is-long-enough\n- name: /cf/b\n  type: password\n  value: short\n"), 0600)).To(Succeed())
+
+			session := runCommand("import", "-f", importFile, "--policy", policyFile)
+
+			Eventually(session).Should(Exit(1))
+			Expect(session.Err).To(Say(`/cf/b \[password-length\]`))
+			Expect(written).To(BeEmpty())
+		})
+	})
+})
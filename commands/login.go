@@ -1,12 +1,15 @@
 package commands
 
 import (
+	"crypto/tls"
 	"fmt"
+	"path/filepath"
 
 	"os"
 
 	"code.cloudfoundry.org/credhub-cli/config"
 	"code.cloudfoundry.org/credhub-cli/credhub"
+	"code.cloudfoundry.org/credhub-cli/credhub/auth"
 	"code.cloudfoundry.org/credhub-cli/credhub/auth/uaa"
 	"code.cloudfoundry.org/credhub-cli/errors"
 	"code.cloudfoundry.org/credhub-cli/util"
@@ -23,6 +26,11 @@ type LoginCommand struct {
 	SkipTlsValidation bool     `long:"skip-tls-validation" description:"Skip certificate validation of the API endpoint. Not recommended!"`
 	SSO               bool     `long:"sso" description:"Prompt for a one-time passcode to login"`
 	SSOPasscode       string   `long:"sso-passcode" description:"One-time passcode"`
+	Browser           bool     `long:"browser" description:"Log in using a web browser"`
+	Device            bool     `long:"device" description:"Log in by approving a code from a browser on another device"`
+	ClientCert        string   `long:"client-cert" description:"Path to a client certificate for mutual TLS authentication" env:"CREDHUB_CLIENT_CERT"`
+	ClientKey         string   `long:"client-key" description:"Path to the private key of the mutual TLS client certificate" env:"CREDHUB_CLIENT_KEY"`
+	CredentialProcess string   `long:"credential-process" description:"Command that prints a JSON token, used to authenticate each session" env:"CREDHUB_CREDENTIAL_PROCESS"`
 	ConfigCommand
 }
 
@@ -48,17 +56,22 @@ func (c *LoginCommand) Execute([]string) error {
 			return err
 		}
 
-		credhubInfo, err := GetApiInfo(serverUrl, c.config.CaCerts, c.SkipTlsValidation)
-		if err != nil {
-			return errors.NewNetworkError(err)
-		}
-		c.config.AuthURL = credhubInfo.AuthServer.URL
+		// Client certificate and credential process logins do not use the auth server
+		if c.ClientCert != "" || c.ClientKey != "" || c.CredentialProcess != "" {
+			c.config.AuthURL = ""
+		} else {
+			credhubInfo, err := GetApiInfo(serverUrl, c.config.CaCerts, c.SkipTlsValidation)
+			if err != nil {
+				return errors.NewNetworkError(err)
+			}
+			c.config.AuthURL = credhubInfo.AuthServer.URL
 
-		c.config.ServerVersion = credhubInfo.App.Version
+			c.config.ServerVersion = credhubInfo.App.Version
 
-		err = verifyAuthServerConnection(c.config, c.SkipTlsValidation)
-		if err != nil {
-			return errors.NewNetworkError(err)
+			err = verifyAuthServerConnection(c.config, c.SkipTlsValidation)
+			if err != nil {
+				return errors.NewNetworkError(err)
+			}
 		}
 	}
 
@@ -67,6 +80,15 @@ func (c *LoginCommand) Execute([]string) error {
 	if err != nil {
 		return err
 	}
+
+	if c.ClientCert != "" {
+		return clientCertificateLogin(c)
+	}
+
+	if c.CredentialProcess != "" {
+		return credentialProcessLogin(c)
+	}
+
 	credhubClient, err := credhub.New(c.config.ApiURL, credhub.CaCerts(c.config.CaCerts...), credhub.SkipTLSValidation(c.config.InsecureSkipVerify))
 	if err != nil {
 		return err
@@ -79,6 +101,10 @@ func (c *LoginCommand) Execute([]string) error {
 
 	if c.ClientName != "" || c.ClientSecret != "" {
 		accessToken, err = uaaClient.ClientCredentialGrant(c.ClientName, c.ClientSecret)
+	} else if c.Browser {
+		accessToken, refreshToken, err = browserLogin(&uaaClient)
+	} else if c.Device {
+		accessToken, refreshToken, err = deviceLogin(&uaaClient)
 	} else {
 		err = promptForMissingCredentials(c, &uaaClient)
 		if err == nil {
@@ -104,6 +130,9 @@ func (c *LoginCommand) Execute([]string) error {
 	}
 
 	c.config.RefreshToken = refreshToken
+	c.config.ClientCertPath = ""
+	c.config.ClientKeyPath = ""
+	c.config.CredentialProcess = ""
 
 	if err := config.WriteConfig(c.config); err != nil {
 		return err
@@ -121,10 +150,33 @@ func (c *LoginCommand) Execute([]string) error {
 
 func validateParameters(cmd *LoginCommand) error {
 	switch {
+	// Intent is an external credential process
+	case cmd.CredentialProcess != "":
+		// Make sure nothing else is specified
+		if cmd.ClientName != "" || cmd.ClientSecret != "" || cmd.Username != "" || cmd.Password != "" || cmd.SSO || cmd.SSOPasscode != "" || cmd.Browser || cmd.Device || cmd.ClientCert != "" || cmd.ClientKey != "" {
+			return errors.NewMixedAuthorizationParametersError()
+		}
+
+		return nil
+
+	// Intent is mutual TLS
+	case cmd.ClientCert != "" || cmd.ClientKey != "":
+		// Make sure nothing else is specified
+		if cmd.ClientName != "" || cmd.ClientSecret != "" || cmd.Username != "" || cmd.Password != "" || cmd.SSO || cmd.SSOPasscode != "" || cmd.Browser || cmd.Device {
+			return errors.NewMixedAuthorizationParametersError()
+		}
+
+		// Make sure all required fields are specified
+		if cmd.ClientCert == "" || cmd.ClientKey == "" {
+			return errors.NewClientCertificateParametersError()
+		}
+
+		return nil
+
 	// Intent is client credentials
 	case cmd.ClientName != "" || cmd.ClientSecret != "":
 		// Make sure nothing else is specified
-		if cmd.Username != "" || cmd.Password != "" || cmd.SSO || cmd.SSOPasscode != "" {
+		if cmd.Username != "" || cmd.Password != "" || cmd.SSO || cmd.SSOPasscode != "" || cmd.Browser || cmd.Device {
 			return errors.NewMixedAuthorizationParametersError()
 		}
 
@@ -138,7 +190,7 @@ func validateParameters(cmd *LoginCommand) error {
 	// Intent is SSO passcode
 	case cmd.SSOPasscode != "":
 		// Make sure nothing else is specified
-		if cmd.ClientName != "" || cmd.ClientSecret != "" || cmd.Username != "" || cmd.Password != "" || cmd.SSO {
+		if cmd.ClientName != "" || cmd.ClientSecret != "" || cmd.Username != "" || cmd.Password != "" || cmd.SSO || cmd.Browser || cmd.Device {
 			return errors.NewMixedAuthorizationParametersError()
 		}
 
@@ -147,7 +199,16 @@ func validateParameters(cmd *LoginCommand) error {
 	// Intent is to be prompted for token
 	case cmd.SSO:
 		// Make sure nothing else is specified
-		if cmd.ClientName != "" || cmd.ClientSecret != "" || cmd.Username != "" || cmd.Password != "" || cmd.SSOPasscode != "" {
+		if cmd.ClientName != "" || cmd.ClientSecret != "" || cmd.Username != "" || cmd.Password != "" || cmd.SSOPasscode != "" || cmd.Browser || cmd.Device {
+			return errors.NewMixedAuthorizationParametersError()
+		}
+
+		return nil
+
+	// Intent is browser or device login
+	case cmd.Browser || cmd.Device:
+		// Make sure nothing else is specified
+		if cmd.ClientName != "" || cmd.ClientSecret != "" || cmd.Username != "" || cmd.Password != "" || (cmd.Browser && cmd.Device) {
 			return errors.NewMixedAuthorizationParametersError()
 		}
 
@@ -169,6 +230,71 @@ func validateParameters(cmd *LoginCommand) error {
 	}
 }
 
+func clientCertificateLogin(cmd *LoginCommand) error {
+	certPath, err := filepath.Abs(cmd.ClientCert)
+	if err != nil {
+		return err
+	}
+	keyPath, err := filepath.Abs(cmd.ClientKey)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tls.LoadX509KeyPair(certPath, keyPath); err != nil {
+		return err
+	}
+
+	RevokeTokenIfNecessary(cmd.config)
+	MarkTokensAsRevokedInConfig(&cmd.config)
+	cmd.config.ClientCertPath = certPath
+	cmd.config.ClientKeyPath = keyPath
+	cmd.config.CredentialProcess = ""
+
+	if err := config.WriteConfig(cmd.config); err != nil {
+		return err
+	}
+
+	if cmd.ServerUrl != "" {
+		PrintWarnings(cmd.ServerUrl, cmd.SkipTlsValidation)
+		fmt.Println("Setting the target url:", cmd.config.ApiURL)
+	}
+
+	fmt.Println("Login Successful")
+
+	return nil
+}
+
+func credentialProcessLogin(cmd *LoginCommand) error {
+	builder := auth.CredentialProcess(cmd.CredentialProcess)
+	credhubClient, err := credhub.New(cmd.config.ApiURL, credhub.Auth(builder))
+	if err != nil {
+		return err
+	}
+
+	if _, err := credhubClient.Auth.(*auth.ExecStrategy).AccessToken(); err != nil {
+		return errors.NewCredentialProcessError(err)
+	}
+
+	RevokeTokenIfNecessary(cmd.config)
+	MarkTokensAsRevokedInConfig(&cmd.config)
+	cmd.config.ClientCertPath = ""
+	cmd.config.ClientKeyPath = ""
+	cmd.config.CredentialProcess = cmd.CredentialProcess
+
+	if err := config.WriteConfig(cmd.config); err != nil {
+		return err
+	}
+
+	if cmd.ServerUrl != "" {
+		PrintWarnings(cmd.ServerUrl, cmd.SkipTlsValidation)
+		fmt.Println("Setting the target url:", cmd.config.ApiURL)
+	}
+
+	fmt.Println("Login Successful")
+
+	return nil
+}
+
 func promptForMissingCredentials(cmd *LoginCommand, uaa *uaa.Client) error {
 	if cmd.SSO || cmd.SSOPasscode != "" {
 		if cmd.SSOPasscode == "" {
@@ -196,3 +322,28 @@ func promptForMissingCredentials(cmd *LoginCommand, uaa *uaa.Client) error {
 	}
 	return nil
 }
+
+func browserLogin(uaaClient *uaa.Client) (string, string, error) {
+	return uaaClient.BrowserLogin(config.AuthClient, config.AuthPassword, func(authorizeURL string) error {
+		fmt.Printf("Opening the following URL in your browser to log in:\n\n  %s\n\n", authorizeURL)
+		if err := util.OpenBrowser(authorizeURL); err != nil {
+			fmt.Println("Unable to open a browser. Please visit the URL above to continue.")
+		}
+		return nil
+	})
+}
+
+func deviceLogin(uaaClient *uaa.Client) (string, string, error) {
+	authorization, err := uaaClient.DeviceAuthorization(config.AuthClient, config.AuthPassword)
+	if err != nil {
+		return "", "", err
+	}
+
+	if authorization.VerificationURIComplete != "" {
+		fmt.Printf("To log in, visit %s\nand confirm the code %s\n\n", authorization.VerificationURIComplete, authorization.UserCode)
+	} else {
+		fmt.Printf("To log in, visit %s\nand enter the code %s\n\n", authorization.VerificationURI, authorization.UserCode)
+	}
+
+	return uaaClient.DeviceCodeGrant(config.AuthClient, config.AuthPassword, authorization)
+}
@@ -1,13 +1,16 @@
 package commands_test
 
 import (
+	"crypto/tls"
 	"net/http"
+	"path/filepath"
 
 	"fmt"
 
 	"strings"
 
 	"io/ioutil"
+	"os"
 
 	"code.cloudfoundry.org/credhub-cli/config"
 	. "github.com/onsi/ginkgo"
@@ -46,7 +49,7 @@ var _ = Describe("Login", func() {
 
 				Expect(uaaServer.ReceivedRequests()).Should(HaveLen(0))
 				Eventually(session).Should(Exit(1))
-				Eventually(session.Err).Should(Say("Client, password, SSO and/or SSO passcode credentials may not be combined. Please update and retry your request with a single login method."))
+				Eventually(session.Err).Should(Say("Client, password, SSO, SSO passcode, browser, device, client certificate and/or credential process login methods may not be combined. Please update and retry your request with a single login method."))
 			})
 		})
 
@@ -56,7 +59,7 @@ var _ = Describe("Login", func() {
 
 				Expect(uaaServer.ReceivedRequests()).Should(HaveLen(0))
 				Eventually(session).Should(Exit(1))
-				Eventually(session.Err).Should(Say("Client, password, SSO and/or SSO passcode credentials may not be combined. Please update and retry your request with a single login method."))
+				Eventually(session.Err).Should(Say("Client, password, SSO, SSO passcode, browser, device, client certificate and/or credential process login methods may not be combined. Please update and retry your request with a single login method."))
 			})
 		})
 
@@ -66,7 +69,7 @@ var _ = Describe("Login", func() {
 
 				Expect(uaaServer.ReceivedRequests()).Should(HaveLen(0))
 				Eventually(session).Should(Exit(1))
-				Eventually(session.Err).Should(Say("Client, password, SSO and/or SSO passcode credentials may not be combined. Please update and retry your request with a single login method."))
+				Eventually(session.Err).Should(Say("Client, password, SSO, SSO passcode, browser, device, client certificate and/or credential process login methods may not be combined. Please update and retry your request with a single login method."))
 			})
 		})
 
@@ -76,7 +79,7 @@ var _ = Describe("Login", func() {
 
 				Expect(uaaServer.ReceivedRequests()).Should(HaveLen(0))
 				Eventually(session).Should(Exit(1))
-				Eventually(session.Err).Should(Say("Client, password, SSO and/or SSO passcode credentials may not be combined. Please update and retry your request with a single login method."))
+				Eventually(session.Err).Should(Say("Client, password, SSO, SSO passcode, browser, device, client certificate and/or credential process login methods may not be combined. Please update and retry your request with a single login method."))
 			})
 		})
 
@@ -86,7 +89,7 @@ var _ = Describe("Login", func() {
 
 				Expect(uaaServer.ReceivedRequests()).Should(HaveLen(0))
 				Eventually(session).Should(Exit(1))
-				Eventually(session.Err).Should(Say("Client, password, SSO and/or SSO passcode credentials may not be combined. Please update and retry your request with a single login method."))
+				Eventually(session.Err).Should(Say("Client, password, SSO, SSO passcode, browser, device, client certificate and/or credential process login methods may not be combined. Please update and retry your request with a single login method."))
 			})
 		})
 
@@ -96,7 +99,7 @@ var _ = Describe("Login", func() {
 
 				Expect(uaaServer.ReceivedRequests()).Should(HaveLen(0))
 				Eventually(session).Should(Exit(1))
-				Eventually(session.Err).Should(Say("Client, password, SSO and/or SSO passcode credentials may not be combined. Please update and retry your request with a single login method."))
+				Eventually(session.Err).Should(Say("Client, password, SSO, SSO passcode, browser, device, client certificate and/or credential process login methods may not be combined. Please update and retry your request with a single login method."))
 			})
 		})
 	})
@@ -327,11 +330,280 @@ var _ = Describe("Login", func() {
 				session := runCommand("login", "--sso", "--sso-passcode", "passcode")
 
 				Eventually(session).Should(Exit(1))
-				Eventually(session.Err).Should(Say("Client, password, SSO and/or SSO passcode credentials may not be combined. Please update and retry your request with a single login method."))
+				Eventually(session.Err).Should(Say("Client, password, SSO, SSO passcode, browser, device, client certificate and/or credential process login methods may not be combined. Please update and retry your request with a single login method."))
 			})
 		})
 	})
 
+	Describe("device flow", func() {
+		BeforeEach(func() {
+			uaaServer.RouteToHandler("POST", "/oauth/device_authorization",
+				CombineHandlers(
+					VerifyBody([]byte(`client_id=`+config.AuthClient+`&client_secret=`+config.AuthPassword)),
+					RespondWith(http.StatusOK, `{
+						"device_code":"some-device-code",
+						"user_code":"ABCD-EFGH",
+						"verification_uri":"https://login.example.com/device",
+						"expires_in":60,
+						"interval":1}`),
+				),
+			)
+			uaaServer.RouteToHandler("POST", "/oauth/token",
+				CombineHandlers(
+					VerifyBody([]byte(`client_id=`+config.AuthClient+`&client_secret=`+config.AuthPassword+`&device_code=some-device-code&grant_type=urn%3Aietf%3Aparams%3Aoauth%3Agrant-type%3Adevice_code&response_type=token`)),
+					RespondWith(http.StatusOK, `{
+						"access_token":"2YotnFZFEjr1zCsicMWpAA",
+						"refresh_token":"erousflkajqwer",
+						"token_type":"bearer",
+						"expires_in":3600}`),
+				),
+			)
+
+			setConfigAuthUrl(uaaServer.URL())
+		})
+
+		It("prints the user code and saves a token once approved", func() {
+			session := runCommand("login", "--device")
+
+			Eventually(session).Should(Exit(0))
+			Eventually(session.Out).Should(Say("To log in, visit https://login.example.com/device"))
+			Eventually(session.Out).Should(Say("and enter the code ABCD-EFGH"))
+			Eventually(session.Out).Should(Say("Login Successful"))
+			cfg := config.ReadConfig()
+			Expect(cfg.AccessToken).To(Equal("2YotnFZFEjr1zCsicMWpAA"))
+			Expect(cfg.RefreshToken).To(Equal("erousflkajqwer"))
+		})
+
+		Context("with browser login also specified", func() {
+			It("fails with an error message", func() {
+				session := runCommand("login", "--device", "--browser")
+
+				Expect(uaaServer.ReceivedRequests()).Should(HaveLen(0))
+				Eventually(session).Should(Exit(1))
+				Eventually(session.Err).Should(Say("Client, password, SSO, SSO passcode, browser, device, client certificate and/or credential process login methods may not be combined. Please update and retry your request with a single login method."))
+			})
+		})
+
+		Context("with a username", func() {
+			It("fails with an error message", func() {
+				session := runCommand("login", "--device", "--username", "test-username")
+
+				Expect(uaaServer.ReceivedRequests()).Should(HaveLen(0))
+				Eventually(session).Should(Exit(1))
+				Eventually(session.Err).Should(Say("Client, password, SSO, SSO passcode, browser, device, client certificate and/or credential process login methods may not be combined. Please update and retry your request with a single login method."))
+			})
+		})
+	})
+
+	Describe("client certificate flow", func() {
+		It("saves the certificate paths and revokes existing tokens", func() {
+			setupUAAConfig(http.StatusOK)
+
+			session := runCommand("login", "--client-cert", "../test/auth-tls-cert.pem", "--client-key", "../test/auth-tls-key.pem")
+
+			Eventually(session).Should(Exit(0))
+			Eventually(session.Out).Should(Say("Login Successful"))
+			certPath, _ := filepath.Abs("../test/auth-tls-cert.pem")
+			keyPath, _ := filepath.Abs("../test/auth-tls-key.pem")
+			cfg := config.ReadConfig()
+			Expect(cfg.ClientCertPath).To(Equal(certPath))
+			Expect(cfg.ClientKeyPath).To(Equal(keyPath))
+			Expect(cfg.AccessToken).To(Equal("revoked"))
+			Expect(cfg.RefreshToken).To(Equal("revoked"))
+		})
+
+		It("accepts the certificate paths through the environment", func() {
+			session := runCommandWithEnv([]string{"CREDHUB_CLIENT_CERT=../test/auth-tls-cert.pem", "CREDHUB_CLIENT_KEY=../test/auth-tls-key.pem"}, "login")
+
+			Eventually(session).Should(Exit(0))
+			Expect(uaaServer.ReceivedRequests()).Should(HaveLen(0))
+			keyPath, _ := filepath.Abs("../test/auth-tls-key.pem")
+			Expect(config.ReadConfig().ClientKeyPath).To(Equal(keyPath))
+		})
+
+		It("presents the certificate to CredHub instead of an access token", func() {
+			mtlsServer := NewUnstartedServer()
+			cert, err := tls.LoadX509KeyPair("../test/server-tls-cert.pem", "../test/server-tls-key.pem")
+			Expect(err).NotTo(HaveOccurred())
+			mtlsServer.HTTPTestServer.TLS = &tls.Config{
+				Certificates: []tls.Certificate{cert},
+				ClientAuth:   tls.RequireAnyClientCert,
+			}
+			mtlsServer.HTTPTestServer.StartTLS()
+			defer mtlsServer.Close()
+			SetupServers(mtlsServer, authServer)
+
+			cfg := config.ReadConfig()
+			cfg.ApiURL = mtlsServer.URL()
+			config.WriteConfig(cfg)
+
+			mtlsServer.RouteToHandler("GET", "/api/v1/data",
+				CombineHandlers(
+					VerifyRequest("GET", "/api/v1/data", "current=true&name=my-value"),
+					func(w http.ResponseWriter, r *http.Request) {
+						Expect(r.Header.Get("Authorization")).To(BeEmpty())
+						Expect(r.TLS.PeerCertificates).To(HaveLen(1))
+					},
+					RespondWith(http.StatusOK, fmt.Sprintf(STRING_CREDENTIAL_ARRAY_RESPONSE_JSON, "value", "my-value", "potatoes")),
+				),
+			)
+
+			session := runCommand("login", "--client-cert", "../test/auth-tls-cert.pem", "--client-key", "../test/auth-tls-key.pem")
+			Eventually(session).Should(Exit(0))
+
+			session = runCommand("get", "-n", "my-value")
+
+			Eventually(session).Should(Exit(0))
+			Eventually(session.Out).Should(Say("value: potatoes"))
+		})
+
+		It("clears the certificate paths when logging in with a password", func() {
+			session := runCommand("login", "--client-cert", "../test/auth-tls-cert.pem", "--client-key", "../test/auth-tls-key.pem")
+			Eventually(session).Should(Exit(0))
+
+			uaaServer.RouteToHandler("POST", "/oauth/token",
+				RespondWith(http.StatusOK, `{"access_token":"2YotnFZFEjr1zCsicMWpAA","refresh_token":"erousflkajqwer","token_type":"bearer"}`),
+			)
+			setConfigAuthUrl(uaaServer.URL())
+
+			session = runCommand("login", "-u", "user", "-p", "pass")
+
+			Eventually(session).Should(Exit(0))
+			cfg := config.ReadConfig()
+			Expect(cfg.ClientCertPath).To(BeEmpty())
+			Expect(cfg.ClientKeyPath).To(BeEmpty())
+			Expect(cfg.AccessToken).To(Equal("2YotnFZFEjr1zCsicMWpAA"))
+		})
+
+		Context("with a certificate and no key", func() {
+			It("fails with an error message", func() {
+				session := runCommand("login", "--client-cert", "../test/auth-tls-cert.pem")
+
+				Eventually(session).Should(Exit(1))
+				Eventually(session.Err).Should(Say("Both client certificate and client key must be provided to authenticate with mutual TLS. Please update and retry your request."))
+			})
+		})
+
+		Context("with a username", func() {
+			It("fails with an error message", func() {
+				session := runCommand("login", "--client-cert", "../test/auth-tls-cert.pem", "--client-key", "../test/auth-tls-key.pem", "-u", "test-username")
+
+				Eventually(session).Should(Exit(1))
+				Eventually(session.Err).Should(Say("Client, password, SSO, SSO passcode, browser, device, client certificate and/or credential process login methods may not be combined. Please update and retry your request with a single login method."))
+			})
+		})
+
+		Context("when the key does not match the certificate", func() {
+			It("fails without changing the config", func() {
+				session := runCommand("login", "--client-cert", "../test/auth-tls-cert.pem", "--client-key", "../test/server-tls-key.pem")
+
+				Eventually(session).Should(Exit(1))
+				Eventually(session.Err).Should(Say("private key does not match public key"))
+				Expect(config.ReadConfig().ClientCertPath).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("credential process flow", func() {
+		var scriptPath, processCommand string
+
+		BeforeEach(func() {
+			script, err := ioutil.TempFile("", "credential-process")
+			Expect(err).NotTo(HaveOccurred())
+			script.WriteString("#!/bin/sh\necho '{\"token\":\"some-process-token\"}'\n")
+			script.Close()
+			scriptPath = script.Name()
+			Expect(os.Chmod(scriptPath, 0700)).To(Succeed())
+			processCommand = scriptPath + " --audience credhub"
+		})
+
+		AfterEach(func() {
+			os.Remove(scriptPath)
+		})
+
+		It("saves the command and uses its token for requests", func() {
+			setupUAAConfig(http.StatusOK)
+
+			session := runCommand("login", "--credential-process", processCommand)
+
+			Eventually(session).Should(Exit(0))
+			Eventually(session.Out).Should(Say("Login Successful"))
+			cfg := config.ReadConfig()
+			Expect(cfg.CredentialProcess).To(Equal(processCommand))
+			Expect(cfg.AccessToken).To(Equal("revoked"))
+
+			server.RouteToHandler("GET", "/api/v1/data",
+				CombineHandlers(
+					VerifyRequest("GET", "/api/v1/data", "current=true&name=my-value"),
+					VerifyHeader(http.Header{"Authorization": []string{"Bearer some-process-token"}}),
+					RespondWith(http.StatusOK, fmt.Sprintf(STRING_CREDENTIAL_ARRAY_RESPONSE_JSON, "value", "my-value", "potatoes")),
+				),
+			)
+
+			session = runCommand("get", "-n", "my-value")
+
+			Eventually(session).Should(Exit(0))
+			Eventually(session.Out).Should(Say("value: potatoes"))
+		})
+
+		It("uses the command from the environment without logging in", func() {
+			server.RouteToHandler("GET", "/api/v1/data",
+				CombineHandlers(
+					VerifyHeader(http.Header{"Authorization": []string{"Bearer some-process-token"}}),
+					RespondWith(http.StatusOK, fmt.Sprintf(STRING_CREDENTIAL_ARRAY_RESPONSE_JSON, "value", "my-value", "potatoes")),
+				),
+			)
+
+			session := runCommandWithEnv([]string{"CREDHUB_CREDENTIAL_PROCESS=" + processCommand}, "get", "-n", "my-value")
+
+			Eventually(session).Should(Exit(0))
+			Eventually(session.Out).Should(Say("value: potatoes"))
+		})
+
+		Context("when the command fails", func() {
+			It("fails without changing the config", func() {
+				session := runCommand("login", "--credential-process", "false")
+
+				Eventually(session).Should(Exit(1))
+				Eventually(session.Err).Should(Say("Unable to get a token from the credential process: credential process failed: exit status 1"))
+				Expect(config.ReadConfig().CredentialProcess).To(BeEmpty())
+			})
+		})
+
+		Context("with a username", func() {
+			It("fails with an error message", func() {
+				session := runCommand("login", "--credential-process", processCommand, "-u", "test-username")
+
+				Eventually(session).Should(Exit(1))
+				Eventually(session.Err).Should(Say("Client, password, SSO, SSO passcode, browser, device, client certificate and/or credential process login methods may not be combined. Please update and retry your request with a single login method."))
+			})
+		})
+	})
+
+	Describe("logout", func() {
+		It("clears the credential process", func() {
+			session := runCommand("login", "--credential-process", `echo '{"token":"some-process-token"}'`)
+			Eventually(session).Should(Exit(0))
+
+			session = runCommand("logout")
+
+			Eventually(session).Should(Exit(0))
+			Expect(config.ReadConfig().CredentialProcess).To(BeEmpty())
+		})
+
+		It("clears the client certificate paths", func() {
+			session := runCommand("login", "--client-cert", "../test/auth-tls-cert.pem", "--client-key", "../test/auth-tls-key.pem")
+			Eventually(session).Should(Exit(0))
+
+			session = runCommand("logout")
+
+			Eventually(session).Should(Exit(0))
+			cfg := config.ReadConfig()
+			Expect(cfg.ClientCertPath).To(BeEmpty())
+			Expect(cfg.ClientKeyPath).To(BeEmpty())
+		})
+	})
+
 	Describe("sso flow with server that doesn't give prompt", func() {
 		BeforeEach(func() {
 			uaaServer.RouteToHandler("POST", "/oauth/token",
@@ -407,6 +679,28 @@ var _ = Describe("Login", func() {
 			Expect(cfg.AuthURL).To(Equal(uaaServer.URL()))
 		})
 
+		It("does not contact the auth server when logging in with a client certificate", func() {
+			session := runCommand("login", "-s", apiServer.URL(), "--client-cert", "../test/auth-tls-cert.pem", "--client-key", "../test/auth-tls-key.pem")
+
+			Eventually(session).Should(Exit(0))
+			Eventually(session.Out).Should(Say("Login Successful"))
+			Expect(apiServer.ReceivedRequests()).Should(HaveLen(0))
+			Expect(uaaServer.ReceivedRequests()).Should(HaveLen(0))
+			cfg := config.ReadConfig()
+			Expect(cfg.ApiURL).To(Equal(apiServer.URL()))
+			Expect(cfg.AuthURL).To(BeEmpty())
+		})
+
+		It("does not contact the auth server when logging in with a credential process", func() {
+			session := runCommand("login", "-s", apiServer.URL(), "--credential-process", `echo '{"token":"some-process-token"}'`)
+
+			Eventually(session).Should(Exit(0))
+			Eventually(session.Out).Should(Say("Login Successful"))
+			Expect(apiServer.ReceivedRequests()).Should(HaveLen(0))
+			Expect(uaaServer.ReceivedRequests()).Should(HaveLen(0))
+			Expect(config.ReadConfig().AuthURL).To(BeEmpty())
+		})
+
 		Context("when the provided server url does not have a scheme specified", func() {
 			It("sets a default scheme", func() {
 				server := NewTLSServer()
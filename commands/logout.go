@@ -17,6 +17,9 @@ func (c *LogoutCommand) Execute([]string) error {
 		return err
 	}
 	MarkTokensAsRevokedInConfig(&c.config)
+	c.config.ClientCertPath = ""
+	c.config.ClientKeyPath = ""
+	c.config.CredentialProcess = ""
 	if err := config.WriteConfig(c.config); err != nil {
 		return err
 	}
@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"os"
+)
+
+type LsCommand struct {
+	Args        PathPositionalArgs `positional-args:"yes"`
+	Concurrency int                `long:"concurrency" default:"4" description:"Number of credentials to look up the type of at the same time"`
+	ClientCommand
+}
+
+type lsEntry struct {
+	Name        string `json:"name" yaml:"name"`
+	Type        string `json:"type" yaml:"type"`
+	Credentials int    `json:"credentials,omitempty" yaml:"credentials,omitempty"`
+}
+
+func (c *LsCommand) ResolveNames() {
+	c.Args.Path = c.resolvePath(c.Args.Path)
+}
+
+func (c *LsCommand) Execute([]string) error {
+	root, err := buildPathTree(c.ClientCommand, c.Args.Path, false, c.Concurrency)
+	if err != nil {
+		return err
+	}
+
+	entries := []lsEntry{}
+	for _, child := range root.Children {
+		entries = append(entries, lsEntry{Name: child.name(), Type: "path", Credentials: child.Count})
+	}
+	for _, credential := range root.Credentials {
+		entries = append(entries, lsEntry{Name: credential.Name[len(root.Path):], Type: credential.Type})
+	}
+
+	output := outputFormat(false)
+	if !structuredOutput(false) {
+		output = OutputFormat{Name: "table"}
+	}
+
+	return output.Print(os.Stdout, map[string][]lsEntry{"entries": entries})
+}
@@ -0,0 +1,74 @@
+package commands_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("Ls", func() {
+	BeforeEach(func() {
+		login()
+
+		servePathTree(map[string]string{
+			"/deploy/cf/admin":      "password",
+			"/deploy/cf/ca":         "certificate",
+			"/deploy/cf/diego/cert": "certificate",
+			"/deploy/db-password":   "password",
+			"/deploy/ssh-key":       "ssh",
+		})
+	})
+
+	ItRequiresAuthentication("ls", "/deploy")
+	ItRequiresAnAPIToBeSet("ls", "/deploy")
+
+	ItBehavesLikeHelp("ls", "ls", func(session *Session) {
+		Expect(session.Err).To(Say("ls"))
+		Expect(session.Err).To(Say("PATH"))
+	})
+
+	It("lists the paths and credentials directly under the path", func() {
+		session := runCommand("ls", "/deploy")
+
+		Eventually(session).Should(Exit(0))
+		Expect(session.Out).To(Say(`NAME\s+TYPE\s+CREDENTIALS`))
+		Expect(session.Out).To(Say(`cf/\s+path\s+3`))
+		Expect(session.Out).To(Say(`db-password\s+password`))
+		Expect(session.Out).To(Say(`ssh-key\s+ssh`))
+	})
+
+	It("only looks up the types of the credentials directly under the path", func() {
+		session := runCommand("ls", "/deploy")
+
+		Eventually(session).Should(Exit(0))
+		for _, request := range server.ReceivedRequests() {
+			Expect(request.URL.Query().Get("name")).NotTo(HavePrefix("/deploy/cf/"))
+		}
+	})
+
+	It("lists the paths under / when no path is provided", func() {
+		session := runCommand("ls")
+
+		Eventually(session).Should(Exit(0))
+		Expect(session.Out).To(Say(`deploy/\s+path\s+5`))
+	})
+
+	It("returns the entries in JSON format", func() {
+		session := runCommand("ls", "/deploy/", "--output", "json")
+
+		Eventually(session).Should(Exit(0))
+		Expect(session.Out.Contents()).To(MatchJSON(`{"entries":[
+			{"name":"cf/","type":"path","credentials":3},
+			{"name":"db-password","type":"password"},
+			{"name":"ssh-key","type":"ssh"}
+		]}`))
+	})
+
+	It("returns an error when there are no credentials under the path", func() {
+		session := runCommand("ls", "/missing")
+
+		Eventually(session).Should(Exit(1))
+		Expect(session.Err).To(Say("No credentials exist which match the provided parameters."))
+	})
+})
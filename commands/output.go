@@ -0,0 +1,300 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"code.cloudfoundry.org/credhub-cli/errors"
+	"code.cloudfoundry.org/credhub-cli/util"
+	"gopkg.in/yaml.v2"
+)
+
+// OutputFormat is the value of the global --output option
+//
+// Supported formats are yaml, json, jsonl, table, template=<go template> and
+// jsonpath=<expression>. Templates and JSONPath expressions are evaluated
+// against the JSON representation of the output.
+type OutputFormat struct {
+	Name string
+	Arg  string
+
+	template *template.Template
+	jsonPath *util.JSONPath
+}
+
+var tableColumnOrder = []string{"name", "type", "id", "version_created_at", "path"}
+
+// UnmarshalFlag parses and validates the --output option
+func (o *OutputFormat) UnmarshalFlag(value string) error {
+	name, arg := value, ""
+	if i := strings.Index(value, "="); i >= 0 {
+		name, arg = value[:i], value[i+1:]
+	}
+
+	format := OutputFormat{Name: name, Arg: arg}
+
+	switch name {
+	case "yaml", "json", "jsonl", "table":
+		if arg != "" {
+			return errors.NewInvalidOutputFormatError(value)
+		}
+	case "template":
+		tmpl, err := template.New("output").Parse(arg)
+		if err != nil {
+			return errors.NewInvalidOutputTemplateError(err)
+		}
+		format.template = tmpl
+	case "jsonpath":
+		path, err := util.ParseJSONPath(arg)
+		if err != nil {
+			return errors.NewInvalidOutputTemplateError(err)
+		}
+		format.jsonPath = path
+	default:
+		return errors.NewInvalidOutputFormatError(value)
+	}
+
+	*o = format
+
+	return nil
+}
+
+// outputFormat returns the format selected by --output, falling back to the
+// command's --output-json flag and then YAML
+func outputFormat(outputJSON bool) OutputFormat {
+	if CredHub.Output.Name != "" {
+		return CredHub.Output
+	}
+	if outputJSON {
+		return OutputFormat{Name: "json"}
+	}
+	return OutputFormat{Name: "yaml"}
+}
+
+// structuredOutput returns true when the output is printed in the format
+// selected by --output or the command's --output-json flag rather than as text
+func structuredOutput(outputJSON bool) bool {
+	return outputJSON || CredHub.Output.Name != ""
+}
+
+func printCredential(outputJSON bool, v interface{}) error {
+	return outputFormat(outputJSON).Print(os.Stdout, v)
+}
+
+// Print writes v to w in the output format
+func (o OutputFormat) Print(w io.Writer, v interface{}) error {
+	switch o.Name {
+	case "", "yaml":
+		s, err := yaml.Marshal(v)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(s))
+		return nil
+	case "json":
+		s, err := json.MarshalIndent(v, "", "\t")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(unescapeHTML(s)))
+		return nil
+	}
+
+	generic, err := toGenericValue(v)
+	if err != nil {
+		return err
+	}
+
+	switch o.Name {
+	case "jsonl":
+		items, _ := outputItems(generic)
+		for _, item := range items {
+			line, err := marshalCompact(item)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(w, string(line))
+		}
+		return nil
+	case "table":
+		return printTable(w, generic)
+	case "template":
+		var buf bytes.Buffer
+		if err := o.template.Execute(&buf, generic); err != nil {
+			return errors.NewInvalidOutputTemplateError(err)
+		}
+		if buf.Len() > 0 && !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+			buf.WriteString("\n")
+		}
+		_, err := w.Write(buf.Bytes())
+		return err
+	case "jsonpath":
+		results := o.jsonPath.Find(generic)
+		if len(results) == 0 {
+			return errors.NewJSONPathNoMatchError(o.jsonPath.String())
+		}
+		for _, result := range results {
+			fmt.Fprintln(w, formatScalar(result))
+		}
+		return nil
+	}
+
+	return errors.NewInvalidOutputFormatError(o.Name)
+}
+
+// toGenericValue converts v to the maps, slices and scalars it would be decoded to from JSON
+func toGenericValue(v interface{}) (interface{}, error) {
+	s, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(s))
+	decoder.UseNumber()
+
+	var generic interface{}
+	err = decoder.Decode(&generic)
+
+	return generic, err
+}
+
+// outputItems splits v into the items listed by the jsonl and table formats
+//
+// Lists are split into their elements, as are single-key objects wrapping a list
+// such as {"credentials": [...]}. Anything else is a single item.
+func outputItems(v interface{}) ([]interface{}, string) {
+	switch t := v.(type) {
+	case []interface{}:
+		return t, ""
+	case map[string]interface{}:
+		if len(t) == 1 {
+			for key, inner := range t {
+				if list, ok := inner.([]interface{}); ok {
+					return list, key
+				}
+			}
+		}
+	}
+
+	return []interface{}{v}, ""
+}
+
+func printTable(w io.Writer, v interface{}) error {
+	items, key := outputItems(v)
+
+	var columns []string
+	seen := map[string]bool{}
+	for _, item := range items {
+		if m, ok := item.(map[string]interface{}); ok {
+			for column := range m {
+				if !seen[column] {
+					seen[column] = true
+					columns = append(columns, column)
+				}
+			}
+		}
+	}
+	sort.Sort(tableColumns(columns))
+
+	tw := tabwriter.NewWriter(w, 0, 4, 3, ' ', 0)
+
+	if len(columns) == 0 {
+		header := "VALUE"
+		if key != "" {
+			header = tableHeader(key)
+		}
+		fmt.Fprintln(tw, header)
+		for _, item := range items {
+			fmt.Fprintln(tw, tableCell(item))
+		}
+		return tw.Flush()
+	}
+
+	headers := make([]string, len(columns))
+	for i, column := range columns {
+		headers[i] = tableHeader(column)
+	}
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+
+	for _, item := range items {
+		m, _ := item.(map[string]interface{})
+		cells := make([]string, len(columns))
+		for i, column := range columns {
+			cells[i] = tableCell(m[column])
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+func tableHeader(column string) string {
+	return strings.ToUpper(strings.Replace(column, "_", " ", -1))
+}
+
+func tableCell(v interface{}) string {
+	return strings.Replace(formatScalar(v), "\n", `\n`, -1)
+}
+
+// formatScalar renders strings and numbers as-is and anything else as compact JSON
+func formatScalar(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case json.Number:
+		return t.String()
+	case bool:
+		return fmt.Sprint(t)
+	}
+
+	s, _ := marshalCompact(v)
+	return string(s)
+}
+
+func marshalCompact(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func unescapeHTML(s []byte) []byte {
+	s = bytes.Replace(s, []byte("\\u003c"), []byte("<"), -1)
+	return bytes.Replace(s, []byte("\\u003e"), []byte(">"), -1)
+}
+
+// tableColumns sorts the well-known credential columns first, then the rest alphabetically
+type tableColumns []string
+
+func (c tableColumns) Len() int      { return len(c) }
+func (c tableColumns) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
+func (c tableColumns) Less(i, j int) bool {
+	ri, rj := columnRank(c[i]), columnRank(c[j])
+	if ri != rj {
+		return ri < rj
+	}
+	return c[i] < c[j]
+}
+
+func columnRank(column string) int {
+	for i, known := range tableColumnOrder {
+		if column == known {
+			return i
+		}
+	}
+	return len(tableColumnOrder)
+}
@@ -6,6 +6,10 @@ type RegenerateCommand struct {
 	ClientCommand
 }
 
+func (c *RegenerateCommand) ResolveNames() {
+	c.CredentialIdentifier = c.resolveName(c.CredentialIdentifier)
+}
+
 func (c *RegenerateCommand) Execute([]string) error {
 	credential, err := c.client.Regenerate(c.CredentialIdentifier)
 
@@ -14,7 +18,5 @@ func (c *RegenerateCommand) Execute([]string) error {
 	}
 
 	credential.Value = "<redacted>"
-	printCredential(c.OutputJSON, credential)
-
-	return nil
+	return printCredential(c.OutputJSON, credential)
 }
@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials"
+	"code.cloudfoundry.org/credhub-cli/errors"
+)
+
+type RollbackCommand struct {
+	CredentialIdentifier string `short:"n" required:"yes" long:"name" description:"Name of the credential to roll back"`
+	VersionId            string `long:"to" description:"ID of the version to roll back to"`
+	Steps                int    `long:"steps" description:"Number of versions to roll back"`
+	NoConfirm            bool   `long:"no-confirm" description:"Roll back without asking for confirmation"`
+	ClientCommand
+}
+
+func (c *RollbackCommand) ResolveNames() {
+	c.CredentialIdentifier = c.resolveName(c.CredentialIdentifier)
+}
+
+func (c *RollbackCommand) Execute([]string) error {
+	if (c.VersionId == "") == (c.Steps == 0) || c.Steps < 0 {
+		return errors.NewRollbackParametersError()
+	}
+
+	version, err := c.version()
+	if err != nil {
+		return err
+	}
+
+	if !c.NoConfirm {
+		prompt := fmt.Sprintf("Roll back %s to version %s created at %s? [y/N]: ", version.Name, version.Id, version.VersionCreatedAt)
+		if !promptForConfirmation(prompt) {
+			return errors.NewRollbackCancelledError()
+		}
+	}
+
+	credential, err := c.client.Rollback(c.CredentialIdentifier, version.Id)
+	if err != nil {
+		return err
+	}
+
+	credential.Value = "<redacted>"
+	return printCredential(false, credential)
+}
+
+// version returns the version selected by --to or --steps
+func (c *RollbackCommand) version() (credentials.Credential, error) {
+	if c.VersionId != "" {
+		version, err := c.client.GetById(c.VersionId)
+		if err != nil {
+			return version, err
+		}
+
+		if strings.TrimPrefix(version.Name, "/") != strings.TrimPrefix(c.CredentialIdentifier, "/") {
+			return version, errors.NewVersionNameMismatchError(c.VersionId, c.CredentialIdentifier)
+		}
+
+		return version, nil
+	}
+
+	versions, err := c.client.GetNVersions(c.CredentialIdentifier, c.Steps+1)
+	if err != nil {
+		return credentials.Credential{}, err
+	}
+
+	if len(versions) <= c.Steps {
+		return credentials.Credential{}, errors.NewRollbackTooManyStepsError(c.CredentialIdentifier, len(versions))
+	}
+
+	return versions[c.Steps], nil
+}
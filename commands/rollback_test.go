@@ -0,0 +1,145 @@
+package commands_test
+
+import (
+	"net/http"
+	"runtime"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+	. "github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Rollback", func() {
+	BeforeEach(func() {
+		login()
+	})
+
+	ItRequiresAuthentication("rollback", "-n", "test-credential", "--to", "some-id")
+	ItRequiresAnAPIToBeSet("rollback", "-n", "test-credential", "--to", "some-id")
+
+	ItBehavesLikeHelp("rollback", "rollback", func(session *Session) {
+		Expect(session.Err).To(Say("rollback"))
+		Expect(session.Err).To(Say("name"))
+		Expect(session.Err).To(Say("steps"))
+	})
+
+	It("displays missing required parameter", func() {
+		session := runCommand("rollback", "--to", "some-id")
+
+		Eventually(session).Should(Exit(1))
+
+		if runtime.GOOS == "windows" {
+			Expect(session.Err).To(Say("the required flag `/n, /name' was not specified"))
+		} else {
+			Expect(session.Err).To(Say("the required flag `-n, --name' was not specified"))
+		}
+	})
+
+	const oldVersion = `{"type":"certificate","id":"old-id","name":"/my-certificate","version_created_at":"2017-01-01T00:00:00Z","value":{"ca":"some-ca","certificate":"old-certificate","private_key":"old-private-key"}}`
+	const newVersion = `{"type":"certificate","id":"new-id","name":"/my-certificate","version_created_at":"` + TIMESTAMP + `","value":{"ca":"some-ca","certificate":"old-certificate","private_key":"old-private-key"}}`
+
+	expectRollback := func() {
+		server.RouteToHandler("PUT", "/api/v1/data",
+			CombineHandlers(
+				VerifyJSON(`{"name":"my-certificate","type":"certificate","value":{"ca":"some-ca","certificate":"old-certificate","private_key":"old-private-key"}}`),
+				RespondWith(http.StatusOK, newVersion),
+			),
+		)
+	}
+
+	Context("with a version ID", func() {
+		BeforeEach(func() {
+			server.RouteToHandler("GET", "/api/v1/data/old-id",
+				RespondWith(http.StatusOK, oldVersion),
+			)
+		})
+
+		It("sets the credential to the value of the version after confirmation", func() {
+			expectRollback()
+
+			session := runCommandWithStdin(strings.NewReader("y\n"), "rollback", "-n", "my-certificate", "--to", "old-id")
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Out).To(Say(`Roll back /my-certificate to version old-id created at 2017-01-01T00:00:00Z\? \[y/N\]: `))
+			Expect(session.Out).To(Say("id: new-id"))
+			Expect(session.Out).To(Say("type: certificate"))
+			Expect(session.Out).To(Say("value: <redacted>"))
+			Expect(string(session.Out.Contents())).NotTo(ContainSubstring("old-private-key"))
+		})
+
+		It("does not ask for confirmation with --no-confirm", func() {
+			expectRollback()
+
+			session := runCommand("rollback", "-n", "my-certificate", "--to", "old-id", "--no-confirm", "--output", "json")
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Out.Contents()).To(MatchJSON(`{"id":"new-id","name":"/my-certificate","type":"certificate","value":"<redacted>","version_created_at":"` + TIMESTAMP + `"}`))
+		})
+
+		It("does not set the credential when the rollback is not confirmed", func() {
+			session := runCommandWithStdin(strings.NewReader("n\n"), "rollback", "-n", "my-certificate", "--to", "old-id")
+
+			Eventually(session).Should(Exit(1))
+			Expect(session.Err).To(Say("The rollback was cancelled."))
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+		})
+
+		It("returns an error when the version belongs to another credential", func() {
+			session := runCommand("rollback", "-n", "other-credential", "--to", "old-id", "--no-confirm")
+
+			Eventually(session).Should(Exit(1))
+			Expect(session.Err).To(Say(`The version "old-id" does not belong to the credential "other-credential".`))
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+		})
+	})
+
+	Context("with a number of steps", func() {
+		BeforeEach(func() {
+			server.RouteToHandler("GET", "/api/v1/data",
+				CombineHandlers(
+					VerifyRequest("GET", "/api/v1/data", "name=my-certificate&versions=2"),
+					RespondWith(http.StatusOK, `{"data":[`+strings.Replace(oldVersion, "old-id", "current-id", 1)+`,`+oldVersion+`]}`),
+				),
+			)
+			server.RouteToHandler("GET", "/api/v1/data/old-id",
+				RespondWith(http.StatusOK, oldVersion),
+			)
+		})
+
+		It("rolls back to the version that many steps before the latest", func() {
+			expectRollback()
+
+			session := runCommand("rollback", "-n", "my-certificate", "--steps", "1", "--no-confirm")
+
+			Eventually(session).Should(Exit(0))
+			Expect(session.Out).To(Say("id: new-id"))
+		})
+
+		It("returns an error when there are not enough versions", func() {
+			server.RouteToHandler("GET", "/api/v1/data",
+				CombineHandlers(
+					VerifyRequest("GET", "/api/v1/data", "name=my-certificate&versions=4"),
+					RespondWith(http.StatusOK, `{"data":[`+oldVersion+`]}`),
+				),
+			)
+
+			session := runCommand("rollback", "-n", "my-certificate", "--steps", "3", "--no-confirm")
+
+			Eventually(session).Should(Exit(1))
+			Expect(session.Err).To(Say(`The credential "my-certificate" does not have enough versions to roll back. Only 1 version\(s\) exist.`))
+		})
+	})
+
+	It("requires exactly one of a version ID or a number of steps", func() {
+		session := runCommand("rollback", "-n", "my-certificate")
+		Eventually(session).Should(Exit(1))
+		Expect(session.Err).To(Say(`Either a version ID \(--to\) or a number of steps \(--steps\) must be provided.`))
+
+		session = runCommand("rollback", "-n", "my-certificate", "--to", "old-id", "--steps", "1")
+		Eventually(session).Should(Exit(1))
+		Expect(session.Err).To(Say(`Either a version ID \(--to\) or a number of steps \(--steps\) must be provided.`))
+	})
+})
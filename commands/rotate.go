@@ -0,0 +1,185 @@
+package commands
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/credhub-cli/credhub"
+	"code.cloudfoundry.org/credhub-cli/errors"
+)
+
+type RotateCommand struct {
+	Path        string `short:"p" long:"path" required:"yes" description:"Path of the credentials to rotate"`
+	OlderThan   string `long:"older-than" required:"yes" description:"Rotate credentials whose latest version is older than this age, e.g. 90d, 12w or 36h"`
+	Types       string `long:"type" default:"password,user,ssh,rsa" description:"Comma-separated types of the credentials to rotate. Certificates are only rotated if included"`
+	Concurrency int    `long:"concurrency" default:"4" description:"Number of credentials to rotate at the same time"`
+	DryRun      bool   `long:"dry-run" description:"Show the credentials that would be rotated without rotating them"`
+	ClientCommand
+}
+
+type rotatedCredential struct {
+	Name             string `json:"name" yaml:"name"`
+	Type             string `json:"type" yaml:"type"`
+	LastRotated      string `json:"last_rotated" yaml:"last_rotated"`
+	Action           string `json:"action" yaml:"action"`
+	Id               string `json:"id,omitempty" yaml:"id,omitempty"`
+	VersionCreatedAt string `json:"version_created_at,omitempty" yaml:"version_created_at,omitempty"`
+	Reason           string `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+const (
+	rotatePlanned = "rotate"
+	rotateRotated = "rotated"
+	rotateSkipped = "skipped"
+	rotateFailed  = "failed"
+)
+
+var rotatableTypes = []string{"password", "user", "ssh", "rsa", "certificate"}
+
+func (c *RotateCommand) ResolveNames() {
+	c.Path = c.resolvePath(c.Path)
+}
+
+func (c *RotateCommand) Execute([]string) error {
+	threshold, err := parseAge(c.OlderThan)
+	if err != nil {
+		return err
+	}
+
+	types := map[string]bool{}
+	for _, credType := range strings.Split(c.Types, ",") {
+		credType = strings.ToLower(strings.TrimSpace(credType))
+		if !containsString(rotatableTypes, credType) {
+			return errors.NewInvalidRotateTypeError(credType)
+		}
+		types[credType] = true
+	}
+
+	prefix := path.Clean("/" + c.Path)
+
+	found, err := c.client.FindByPath(prefix)
+	if err != nil {
+		return err
+	}
+
+	if len(found.Credentials) == 0 {
+		return errors.NewNoMatchingCredentialsFoundError()
+	}
+
+	now := time.Now()
+	results := []*rotatedCredential{}
+	for _, credential := range found.Credentials {
+		createdAt, err := time.Parse(time.RFC3339, credential.VersionCreatedAt)
+		if err != nil || now.Sub(createdAt) < threshold {
+			continue
+		}
+		results = append(results, &rotatedCredential{Name: absoluteName(credential.Name), LastRotated: credential.VersionCreatedAt})
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+
+	c.rotateAll(results, types)
+
+	if structuredOutput(false) {
+		if err := printCredential(false, map[string][]*rotatedCredential{"credentials": results}); err != nil {
+			return err
+		}
+	} else {
+		printRotatedCredentials(results, prefix, c.OlderThan, len(found.Credentials), c.DryRun)
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Action == rotateFailed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return errors.NewRotateFailuresError(failed)
+	}
+
+	return nil
+}
+
+// rotateAll looks up the type of each credential and, unless it is a dry run,
+// regenerates those of the selected types concurrently, recording the outcome
+// in each result
+func (c *RotateCommand) rotateAll(results []*rotatedCredential, types map[string]bool) {
+	forEachConcurrently(len(results), c.Concurrency, func(index int) {
+		c.rotate(results[index], types)
+	})
+}
+
+func (c *RotateCommand) rotate(result *rotatedCredential, types map[string]bool) {
+	latest, err := c.client.GetLatestVersion(result.Name)
+	if err != nil {
+		result.Action = rotateFailed
+		if serverErr, ok := err.(*credhub.Error); ok && strings.Contains(serverErr.Name, "statically set") {
+			result.Action = rotateSkipped
+		}
+		result.Reason = err.Error()
+		return
+	}
+	result.Type = latest.Type
+
+	switch {
+	case !containsString(rotatableTypes, result.Type):
+		result.Action = rotateSkipped
+		result.Reason = result.Type + " credentials are not generated"
+		return
+	case !types[result.Type]:
+		result.Action = rotateSkipped
+		result.Reason = "the type " + result.Type + " is not included in --type"
+		return
+	case c.DryRun:
+		result.Action = rotatePlanned
+		return
+	}
+
+	credential, err := c.client.Regenerate(result.Name)
+	if err != nil {
+		result.Action = rotateFailed
+		if serverErr, ok := err.(*credhub.Error); ok && strings.Contains(serverErr.Name, "statically set") {
+			result.Action = rotateSkipped
+		}
+		result.Reason = err.Error()
+		return
+	}
+
+	result.Action = rotateRotated
+	result.Id = credential.Id
+	result.VersionCreatedAt = credential.VersionCreatedAt
+}
+
+func printRotatedCredentials(results []*rotatedCredential, prefix, olderThan string, total int, dryRun bool) {
+	counts := map[string]int{}
+
+	for _, result := range results {
+		counts[result.Action]++
+		line := result.Name + " [" + result.Type + "]"
+
+		switch result.Action {
+		case rotatePlanned:
+			fmt.Println("  " + line + " last rotated " + result.LastRotated)
+		case rotateRotated:
+			fmt.Println("~ " + line + " " + result.LastRotated + " -> " + result.VersionCreatedAt)
+		case rotateSkipped:
+			fmt.Println("! " + line + " skipped: " + result.Reason)
+		case rotateFailed:
+			fmt.Println("x " + line + " failed: " + result.Reason)
+		}
+	}
+
+	if dryRun {
+		fmt.Printf("Dry run under %s: %d of %d credential(s) are older than %s, %d would be rotated, %d skipped\n",
+			prefix, len(results), total, olderThan, counts[rotatePlanned], counts[rotateSkipped])
+		fmt.Println("Whether a credential was set or generated cannot be known in advance. Credentials that were set cannot be regenerated and will be skipped.")
+		return
+	}
+
+	fmt.Printf("Rotated credentials under %s older than %s: %d rotated, %d skipped, %d failed\n",
+		prefix, olderThan, counts[rotateRotated], counts[rotateSkipped], counts[rotateFailed])
+}
@@ -0,0 +1,164 @@
+package commands_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("Rotate", func() {
+	var (
+		stored      map[string]map[string]string
+		mu          sync.Mutex
+		regenerated []string
+	)
+
+	daysAgo := func(days int) string {
+		return time.Now().Add(-time.Duration(days) * 24 * time.Hour).UTC().Format(time.RFC3339)
+	}
+
+	BeforeEach(func() {
+		login()
+
+		regenerated = nil
+		stored = map[string]map[string]string{
+			"/team/db-password": {"type": "password", "created": daysAgo(120)},
+			"/team/admin":       {"type": "user", "created": daysAgo(100)},
+			"/team/fresh":       {"type": "password", "created": daysAgo(10)},
+			"/team/tls":         {"type": "certificate", "created": daysAgo(400)},
+			"/team/url":         {"type": "value", "created": daysAgo(200)},
+			"/team/static":      {"type": "password", "created": daysAgo(95)},
+			"/team/broken":      {"type": "ssh", "created": daysAgo(91)},
+		}
+
+		server.RouteToHandler("GET", "/api/v1/data", func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("path") != "" {
+				var names []string
+				for name := range stored {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+
+				found := []map[string]string{}
+				for _, name := range names {
+					found = append(found, map[string]string{"name": name, "version_created_at": stored[name]["created"]})
+				}
+				json.NewEncoder(w).Encode(map[string]interface{}{"credentials": found})
+				return
+			}
+			name := r.URL.Query().Get("name")
+			w.Write([]byte(`{"data":[{"type":"` + stored[name]["type"] + `","id":"` + UUID + `","name":"` + name + `","version_created_at":"` + stored[name]["created"] + `","value":"some-value"}]}`))
+		})
+
+		server.RouteToHandler("POST", "/api/v1/data", func(w http.ResponseWriter, r *http.Request) {
+			var request struct {
+				Name       string `json:"name"`
+				Regenerate bool   `json:"regenerate"`
+			}
+			body, _ := ioutil.ReadAll(r.Body)
+			Expect(json.Unmarshal(body, &request)).To(Succeed())
+			Expect(request.Regenerate).To(BeTrue())
+
+			switch request.Name {
+			case "/team/static":
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"The password could not be regenerated because the value was statically set. Only generated passwords may be regenerated."}`))
+				return
+			case "/team/broken":
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(`{"error":"something went wrong"}`))
+				return
+			}
+
+			mu.Lock()
+			regenerated = append(regenerated, request.Name)
+			mu.Unlock()
+			w.Write([]byte(`{"type":"` + stored[request.Name]["type"] + `","id":"` + UUID + `","name":"` + request.Name + `","version_created_at":"` + TIMESTAMP + `","value":"new-value"}`))
+		})
+	})
+
+	ItRequiresAuthentication("rotate", "-p", "/team", "--older-than", "90d")
+	ItRequiresAnAPIToBeSet("rotate", "-p", "/team", "--older-than", "90d")
+
+	ItBehavesLikeHelp("rotate", "rotate", func(session *Session) {
+		Expect(session.Err).To(Say("older-than"))
+		Expect(session.Err).To(Say("dry-run"))
+	})
+
+	It("regenerates the credentials of the selected types older than the age", func() {
+		session := runCommand("rotate", "-p", "/team", "--older-than", "90d", "--concurrency", "3")
+
+		Eventually(session).Should(Exit(1))
+		Expect(session.Out).To(Say(`~ /team/admin \[user\] \S+ -> ` + TIMESTAMP + `\n`))
+		Expect(session.Out).To(Say(`x /team/broken \[ssh\] failed: something went wrong\n`))
+		Expect(session.Out).To(Say(`~ /team/db-password \[password\] \S+ -> ` + TIMESTAMP + `\n`))
+		Expect(session.Out).To(Say(`! /team/static \[password\] skipped: The password could not be regenerated because the value was statically set.`))
+		Expect(session.Out).To(Say(`! /team/tls \[certificate\] skipped: the type certificate is not included in --type\n`))
+		Expect(session.Out).To(Say(`! /team/url \[value\] skipped: value credentials are not generated\n`))
+		Expect(session.Out).To(Say(`Rotated credentials under /team older than 90d: 2 rotated, 3 skipped, 1 failed\n`))
+		Expect(session.Err).To(Say(`1 credential\(s\) could not be rotated.`))
+
+		sort.Strings(regenerated)
+		Expect(regenerated).To(Equal([]string{"/team/admin", "/team/db-password"}))
+	})
+
+	It("rotates certificates when they are included in --type", func() {
+		session := runCommand("rotate", "-p", "/team", "--older-than", "52w", "--type", "password,certificate")
+
+		Eventually(session).Should(Exit(0))
+		Expect(session.Out).To(Say(`~ /team/tls \[certificate\] \S+ -> ` + TIMESTAMP + `\n`))
+		Expect(session.Out).To(Say(`Rotated credentials under /team older than 52w: 1 rotated, 0 skipped, 0 failed\n`))
+		Expect(regenerated).To(Equal([]string{"/team/tls"}))
+	})
+
+	It("shows the credentials that would be rotated with --dry-run", func() {
+		session := runCommand("rotate", "-p", "/team", "--older-than", "90d", "--dry-run", "--type", "password")
+
+		Eventually(session).Should(Exit(0))
+		Expect(session.Out).To(Say(`! /team/admin \[user\] skipped: the type user is not included in --type\n`))
+		Expect(session.Out).To(Say(`  /team/db-password \[password\] last rotated \S+\n`))
+		Expect(session.Out).To(Say(`  /team/static \[password\] last rotated \S+\n`))
+		Expect(session.Out).To(Say(`Dry run under /team: 6 of 7 credential\(s\) are older than 90d, 2 would be rotated, 4 skipped\n`))
+		Expect(session.Out).To(Say(`Whether a credential was set or generated cannot be known in advance. Credentials that were set cannot be regenerated and will be skipped.\n`))
+		Expect(regenerated).To(BeEmpty())
+	})
+
+	It("returns the report in JSON format", func() {
+		session := runCommand("rotate", "-p", "/team", "--older-than", "100d", "--type", "password", "--output", "json")
+
+		Eventually(session).Should(Exit(0))
+
+		var report struct {
+			Credentials []map[string]string `json:"credentials"`
+		}
+		Expect(json.Unmarshal(session.Out.Contents(), &report)).To(Succeed())
+		Expect(report.Credentials).To(HaveLen(4))
+		Expect(report.Credentials[1]["name"]).To(Equal("/team/db-password"))
+		Expect(report.Credentials[1]["action"]).To(Equal("rotated"))
+		Expect(report.Credentials[1]["last_rotated"]).To(Equal(stored["/team/db-password"]["created"]))
+		Expect(report.Credentials[1]["version_created_at"]).To(Equal(TIMESTAMP))
+	})
+
+	It("returns an error when a type cannot be rotated", func() {
+		session := runCommand("rotate", "-p", "/team", "--older-than", "90d", "--type", "password,json")
+
+		Eventually(session).Should(Exit(1))
+		Expect(session.Err).To(Say(`The type "json" cannot be rotated.`))
+		Expect(regenerated).To(BeEmpty())
+	})
+
+	It("returns an error when the age is not valid", func() {
+		session := runCommand("rotate", "-p", "/team", "--older-than", "quarterly")
+
+		Eventually(session).Should(Exit(1))
+		Expect(session.Err).To(Say(`The age "quarterly" is not valid.`))
+	})
+})
@@ -10,6 +10,7 @@ import (
 	"code.cloudfoundry.org/credhub-cli/credhub/credentials"
 	"code.cloudfoundry.org/credhub-cli/credhub/credentials/values"
 	"code.cloudfoundry.org/credhub-cli/errors"
+	"code.cloudfoundry.org/credhub-cli/models"
 	"code.cloudfoundry.org/credhub-cli/util"
 	"github.com/howeyc/gopass"
 )
@@ -26,9 +27,14 @@ type SetCommand struct {
 	Username             string `short:"z" long:"username" description:"[User] Sets the username value of the credential"`
 	Password             string `short:"w" long:"password" description:"[Password, User] Sets the password value of the credential"`
 	OutputJSON           bool   `short:"j" long:"output-json" description:"Return response in JSON format"`
+	Policy               string `long:"policy" description:"Policy file the credential must comply with before it is set"`
 	ClientCommand
 }
 
+func (c *SetCommand) ResolveNames() {
+	c.CredentialIdentifier = c.resolveName(c.CredentialIdentifier)
+}
+
 func (c *SetCommand) Execute([]string) error {
 	c.Type = strings.ToLower(c.Type)
 
@@ -49,9 +55,7 @@ func (c *SetCommand) Execute([]string) error {
 	}
 
 	credential.Value = "<redacted>"
-	printCredential(c.OutputJSON, credential)
-
-	return nil
+	return printCredential(c.OutputJSON, credential)
 }
 
 func (c *SetCommand) setFieldsFromInteractiveUserInput() {
@@ -126,6 +130,14 @@ func (c *SetCommand) setCredential() (credentials.Credential, error) {
 	default:
 		value = values.Value(c.Value)
 	}
+
+	err := enforcePolicy(c.Policy, func(policy *models.Policy) []models.PolicyViolation {
+		return policy.CheckCredentials([]credentials.Credential{{Name: c.CredentialIdentifier, Type: c.Type, Value: value}})
+	})
+	if err != nil {
+		return credentials.Credential{}, err
+	}
+
 	return c.client.SetCredential(c.CredentialIdentifier, c.Type, value)
 }
 
@@ -135,3 +147,11 @@ func promptForInput(prompt string, value *string) {
 	val, _ := reader.ReadString('\n')
 	*value = string(strings.TrimSpace(val))
 }
+
+func promptForConfirmation(prompt string) bool {
+	fmt.Print(prompt)
+	reader := bufio.NewReader(os.Stdin)
+	answer, _ := reader.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
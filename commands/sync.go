@@ -0,0 +1,335 @@
+package commands
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"code.cloudfoundry.org/credhub-cli/config"
+	"code.cloudfoundry.org/credhub-cli/credhub"
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials"
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials/values"
+	"code.cloudfoundry.org/credhub-cli/errors"
+)
+
+type SyncCommand struct {
+	From        string   `long:"from" required:"yes" value-name:"CONFIG" description:"Config file of the target to read credentials from, e.g. a copy of ~/.credhub/config.json made after logging in to it"`
+	To          string   `long:"to" required:"yes" value-name:"CONFIG" description:"Config file of the target to write credentials to"`
+	Path        string   `short:"p" long:"path" required:"yes" description:"Path of the credentials to sync on the source target"`
+	Destination string   `long:"dest" description:"Path to sync the credentials to on the destination target, defaults to --path"`
+	Mode        string   `long:"mode" default:"create-only" description:"'create-only' only creates credentials that do not exist on the destination, 'overwrite' also updates those with a different value"`
+	Include     []string `long:"include" value-name:"GLOB" description:"Only sync credentials whose name, or name relative to --path, matches the glob. May be repeated."`
+	Exclude     []string `long:"exclude" value-name:"GLOB" description:"Do not sync credentials whose name, or name relative to --path, matches the glob. May be repeated."`
+	DryRun      bool     `long:"dry-run" description:"Show the changes that would be made without making them"`
+}
+
+type syncChange struct {
+	Source      string `json:"source" yaml:"source"`
+	Destination string `json:"destination" yaml:"destination"`
+	Type        string `json:"type" yaml:"type"`
+	Action      string `json:"action" yaml:"action"`
+	Reason      string `json:"reason,omitempty" yaml:"reason,omitempty"`
+
+	credential credentials.Credential
+	ca         *syncChange
+}
+
+const (
+	syncCreate    = "create"
+	syncUpdate    = "update"
+	syncUnchanged = "unchanged"
+	syncSkip      = "skip"
+	syncFailed    = "failed"
+)
+
+func (c *SyncCommand) Execute([]string) error {
+	if c.Mode != "create-only" && c.Mode != "overwrite" {
+		return errors.NewInvalidSyncModeError(c.Mode)
+	}
+
+	if err := validateGlobs(c.Include, c.Exclude); err != nil {
+		return err
+	}
+
+	from, err := syncTargetClient(c.From)
+	if err != nil {
+		return err
+	}
+
+	to, err := syncTargetClient(c.To)
+	if err != nil {
+		return err
+	}
+
+	if c.Destination == "" {
+		c.Destination = c.Path
+	}
+	sourcePrefix := strings.TrimSuffix(absoluteName(c.Path), "/") + "/"
+	destinationPrefix := strings.TrimSuffix(absoluteName(c.Destination), "/") + "/"
+
+	changes, err := c.plan(from, to, sourcePrefix, destinationPrefix)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	if !c.DryRun {
+		for _, change := range changes {
+			if change.Action != syncCreate && change.Action != syncUpdate {
+				continue
+			}
+			if err := setSyncedCredential(to, change); err != nil {
+				change.Action = syncFailed
+				change.Reason = err.Error()
+				failed++
+			}
+		}
+	}
+
+	if structuredOutput(false) {
+		if err := printCredential(false, map[string][]*syncChange{"changes": changes}); err != nil {
+			return err
+		}
+	} else {
+		printSyncChanges(changes, c.DryRun, sourcePrefix, destinationPrefix)
+	}
+
+	if failed > 0 {
+		return errors.NewSyncFailuresError(failed)
+	}
+
+	return nil
+}
+
+func syncTargetClient(target string) (*credhub.CredHub, error) {
+	cfg, err := config.ReadConfigFile(target)
+	if err != nil {
+		return nil, errors.NewSyncTargetError(target, err)
+	}
+
+	client, err := newTargetClient(cfg)
+	if err != nil {
+		return nil, errors.NewSyncTargetError(target, err)
+	}
+
+	return client, nil
+}
+
+// plan compares the latest version of each source credential with its
+// destination and returns the changes in the order they must be made, with
+// certificate authorities before the certificates they sign
+func (c *SyncCommand) plan(from, to *credhub.CredHub, sourcePrefix, destinationPrefix string) ([]*syncChange, error) {
+	sources, err := from.FindByPath(path.Clean(sourcePrefix))
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := to.FindByPath(path.Clean(destinationPrefix))
+	if err != nil {
+		return nil, err
+	}
+
+	hasher, err := newValueHasher()
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []*syncChange
+	for _, source := range sources.Credentials {
+		name := absoluteName(source.Name)
+		relative := strings.TrimPrefix(name, sourcePrefix)
+
+		if len(c.Include) > 0 && !matchesAny(c.Include, name, relative) {
+			continue
+		}
+		if matchesAny(c.Exclude, name, relative) {
+			continue
+		}
+
+		credential, err := from.GetLatestVersion(name)
+		if err != nil {
+			return nil, err
+		}
+
+		change := &syncChange{
+			Source:      name,
+			Destination: destinationPrefix + relative,
+			Type:        credential.Type,
+			Action:      syncCreate,
+			credential:  credential,
+		}
+
+		if containsName(existing.Credentials, change.Destination) {
+			current, err := to.GetLatestVersion(change.Destination)
+			if err != nil {
+				return nil, err
+			}
+
+			same, err := sameContent(hasher, credential, current)
+			if err != nil {
+				return nil, err
+			}
+
+			switch {
+			case current.Type != credential.Type:
+				change.Action = syncSkip
+				change.Reason = "the destination is a " + current.Type + " credential"
+			case same:
+				change.Action = syncUnchanged
+			case c.Mode == "create-only":
+				change.Action = syncSkip
+				change.Reason = "the destination exists with a different value"
+			default:
+				change.Action = syncUpdate
+			}
+		}
+
+		changes = append(changes, change)
+	}
+
+	if len(changes) == 0 {
+		return nil, errors.NewNoMatchingCredentialsFoundError()
+	}
+
+	orderByCertificateAuthority(changes)
+
+	return changes, nil
+}
+
+// orderByCertificateAuthority sorts certificate authorities before the
+// certificates they sign. Certificates signed by a synced CA are set with the
+// destination name of the CA, others with the CA certificate itself.
+func orderByCertificateAuthority(changes []*syncChange) {
+	bySource := map[string]*syncChange{}
+	for _, change := range changes {
+		bySource[change.Source] = change
+	}
+
+	for _, change := range changes {
+		value, _ := change.credential.Value.(map[string]interface{})
+		caName, _ := value["ca_name"].(string)
+		if ca, ok := bySource[absoluteName(caName)]; ok && ca != change {
+			change.ca = ca
+		}
+	}
+
+	depth := func(change *syncChange) int {
+		d := 0
+		seen := map[*syncChange]bool{change: true}
+		for change.ca != nil && !seen[change.ca] {
+			change = change.ca
+			seen[change] = true
+			d++
+		}
+		return d
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		di, dj := depth(changes[i]), depth(changes[j])
+		if di != dj {
+			return di < dj
+		}
+		return changes[i].Destination < changes[j].Destination
+	})
+}
+
+func setSyncedCredential(to *credhub.CredHub, change *syncChange) error {
+	value := change.credential.Value
+
+	if m, ok := value.(map[string]interface{}); ok && change.Type == "certificate" {
+		copied := map[string]interface{}{}
+		for k, v := range m {
+			copied[k] = v
+		}
+		delete(copied, "ca_name")
+		if change.ca != nil {
+			copied["ca_name"] = change.ca.Destination
+			delete(copied, "ca")
+		}
+		value = copied
+	}
+
+	typed, err := values.Typed(change.Type, value)
+	if err != nil {
+		return err
+	}
+
+	_, err = to.SetCredential(change.Destination, change.Type, typed)
+	return err
+}
+
+// sameContent reports whether two credentials have the same type and value,
+// compared by a keyed hash so that it works across servers. Fields computed by
+// the server and the name of a certificate's CA, which differs when paths are
+// renamed, are ignored.
+func sameContent(hasher *valueHasher, source, destination credentials.Credential) (bool, error) {
+	sourceHash, err := contentHash(hasher, source)
+	if err != nil {
+		return false, err
+	}
+
+	destinationHash, err := contentHash(hasher, destination)
+	if err != nil {
+		return false, err
+	}
+
+	return sourceHash == destinationHash, nil
+}
+
+func contentHash(hasher *valueHasher, credential credentials.Credential) (string, error) {
+	value := credential.Value
+
+	if m, ok := value.(map[string]interface{}); ok && credential.Type == "certificate" {
+		copied := map[string]interface{}{}
+		for k, v := range m {
+			if k != "ca_name" {
+				copied[k] = v
+			}
+		}
+		value = copied
+	}
+
+	typed, err := values.Typed(credential.Type, value)
+	if err != nil {
+		return "", err
+	}
+
+	buf, err := marshalCompact(typed)
+	if err != nil {
+		return "", err
+	}
+
+	return hasher.hash(credential.Type + ":" + string(buf)), nil
+}
+
+func printSyncChanges(changes []*syncChange, dryRun bool, sourcePrefix, destinationPrefix string) {
+	counts := map[string]int{}
+
+	for _, change := range changes {
+		counts[change.Action]++
+		arrow := change.Source + " -> " + change.Destination + " [" + change.Type + "]"
+
+		switch change.Action {
+		case syncCreate:
+			fmt.Println("+ " + arrow)
+		case syncUpdate:
+			fmt.Println("~ " + arrow + " changed")
+		case syncUnchanged:
+			fmt.Println("= " + arrow + " unchanged")
+		case syncSkip:
+			fmt.Println("! " + arrow + " skipped: " + change.Reason)
+		case syncFailed:
+			fmt.Println("x " + arrow + " failed: " + change.Reason)
+		}
+	}
+
+	if dryRun {
+		fmt.Printf("Dry run of %s to %s: %d to create, %d to update, %d unchanged, %d skipped\n",
+			sourcePrefix, destinationPrefix, counts[syncCreate], counts[syncUpdate], counts[syncUnchanged], counts[syncSkip])
+		return
+	}
+
+	fmt.Printf("Synced %s to %s: %d created, %d updated, %d unchanged, %d skipped, %d failed\n",
+		sourcePrefix, destinationPrefix, counts[syncCreate], counts[syncUpdate], counts[syncUnchanged], counts[syncSkip], counts[syncFailed])
+}
@@ -0,0 +1,247 @@
+package commands_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"code.cloudfoundry.org/credhub-cli/config"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+	. "github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Sync", func() {
+	var (
+		lock        sync.Mutex
+		destination *Server
+		source      map[string]string
+		existing    map[string]string
+		setRequests []map[string]interface{}
+		fromConfig  string
+		toConfig    string
+	)
+
+	credentialJSON := func(name, credType, value string) string {
+		return `{"type":"` + credType + `","id":"` + UUID + `","name":"` + name + `","version_created_at":"` + TIMESTAMP + `","value":` + value + `}`
+	}
+
+	serveCredentials := func(s *Server, stored *map[string]string) {
+		s.RouteToHandler("GET", "/api/v1/data", func(w http.ResponseWriter, r *http.Request) {
+			lock.Lock()
+			defer lock.Unlock()
+
+			query := r.URL.Query()
+			if prefix := query.Get("path"); prefix != "" {
+				var names []string
+				for name := range *stored {
+					if strings.HasPrefix(name, strings.TrimSuffix(prefix, "/")+"/") {
+						names = append(names, name)
+					}
+				}
+				sort.Strings(names)
+
+				found := []map[string]string{}
+				for _, name := range names {
+					found = append(found, map[string]string{"name": name, "version_created_at": TIMESTAMP})
+				}
+				json.NewEncoder(w).Encode(map[string]interface{}{"credentials": found})
+				return
+			}
+
+			w.Write([]byte(`{"data":[` + (*stored)[query.Get("name")] + `]}`))
+		})
+	}
+
+	writeTargetConfig := func(s *Server) string {
+		ca, err := ioutil.ReadFile("../test/server-tls-ca.pem")
+		Expect(err).NotTo(HaveOccurred())
+
+		file := filepath.Join(homeDir, s.Addr()+".json")
+		data, _ := json.Marshal(config.Config{
+			ApiURL:       s.URL(),
+			AuthURL:      authServer.URL(),
+			AccessToken:  "some-access-token",
+			RefreshToken: "some-refresh-token",
+			CaCerts:      []string{string(ca)},
+		})
+		Expect(ioutil.WriteFile(file, data, 0600)).To(Succeed())
+		return file
+	}
+
+	BeforeEach(func() {
+		destination = NewTlsServer("../test/server-tls-cert.pem", "../test/server-tls-key.pem")
+		SetupServers(destination, authServer)
+
+		source = map[string]string{
+			"/staging/cf/admin":    credentialJSON("/staging/cf/admin", "password", `"new-admin-password"`),
+			"/staging/cf/api-key":  credentialJSON("/staging/cf/api-key", "value", `"some-api-key"`),
+			"/staging/cf/db":       credentialJSON("/staging/cf/db", "password", `"same-password"`),
+			"/staging/cf/conflict": credentialJSON("/staging/cf/conflict", "password", `"some-password"`),
+		}
+		existing = map[string]string{
+			"/prod/cf/admin":    credentialJSON("/prod/cf/admin", "password", `"old-admin-password"`),
+			"/prod/cf/db":       credentialJSON("/prod/cf/db", "password", `"same-password"`),
+			"/prod/cf/conflict": credentialJSON("/prod/cf/conflict", "value", `"some-password"`),
+		}
+		setRequests = nil
+
+		serveCredentials(server, &source)
+		serveCredentials(destination, &existing)
+
+		destination.RouteToHandler("PUT", "/api/v1/data", func(w http.ResponseWriter, r *http.Request) {
+			lock.Lock()
+			defer lock.Unlock()
+
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			setRequests = append(setRequests, body)
+			w.Write([]byte(credentialJSON(body["name"].(string), body["type"].(string), `"<redacted>"`)))
+		})
+
+		fromConfig = writeTargetConfig(server)
+		toConfig = writeTargetConfig(destination)
+	})
+
+	AfterEach(func() {
+		destination.Close()
+	})
+
+	setNames := func() []string {
+		var names []string
+		for _, request := range setRequests {
+			names = append(names, request["name"].(string))
+		}
+		return names
+	}
+
+	ItBehavesLikeHelp("sync", "sync", func(session *Session) {
+		Expect(session.Err).To(Say("from"))
+		Expect(session.Err).To(Say("to"))
+		Expect(session.Err).To(Say("path"))
+		Expect(session.Err).To(Say("dest"))
+		Expect(session.Err).To(Say("mode"))
+	})
+
+	It("creates the credentials that do not exist on the destination", func() {
+		session := runCommand("sync", "--from", fromConfig, "--to", toConfig, "-p", "/staging/cf", "--dest", "/prod/cf")
+
+		Eventually(session).Should(Exit(0))
+		Expect(session.Out).To(Say(`! /staging/cf/admin -> /prod/cf/admin \[password\] skipped: the destination exists with a different value`))
+		Expect(session.Out).To(Say(`\+ /staging/cf/api-key -> /prod/cf/api-key \[value\]\n`))
+		Expect(session.Out).To(Say(`! /staging/cf/conflict -> /prod/cf/conflict \[password\] skipped: the destination is a value credential`))
+		Expect(session.Out).To(Say(`= /staging/cf/db -> /prod/cf/db \[password\] unchanged\n`))
+		Expect(session.Out).To(Say(`Synced /staging/cf/ to /prod/cf/: 1 created, 0 updated, 1 unchanged, 2 skipped, 0 failed`))
+
+		Expect(setRequests).To(Equal([]map[string]interface{}{
+			{"name": "/prod/cf/api-key", "type": "value", "value": "some-api-key"},
+		}))
+	})
+
+	It("also updates credentials with a different value with --mode overwrite", func() {
+		session := runCommand("sync", "--from", fromConfig, "--to", toConfig, "-p", "/staging/cf", "--dest", "/prod/cf", "--mode", "overwrite")
+
+		Eventually(session).Should(Exit(0))
+		Expect(session.Out).To(Say(`~ /staging/cf/admin -> /prod/cf/admin \[password\] changed\n`))
+		Expect(setNames()).To(Equal([]string{"/prod/cf/admin", "/prod/cf/api-key"}))
+	})
+
+	It("never prints values or hashes of them", func() {
+		session := runCommand("sync", "--from", fromConfig, "--to", toConfig, "-p", "/staging/cf", "--dest", "/prod/cf", "--mode", "overwrite", "--output", "json")
+
+		Eventually(session).Should(Exit(0))
+		for _, value := range []string{"new-admin-password", "old-admin-password", "some-api-key", "same-password"} {
+			Expect(string(session.Out.Contents())).NotTo(ContainSubstring(value))
+		}
+
+		var output struct {
+			Changes []map[string]string `json:"changes"`
+		}
+		Expect(json.Unmarshal(session.Out.Contents(), &output)).To(Succeed())
+		Expect(output.Changes).To(HaveLen(4))
+		Expect(output.Changes[0]["destination"]).To(Equal("/prod/cf/admin"))
+		Expect(output.Changes[0]["action"]).To(Equal("update"))
+		Expect(output.Changes[3]["action"]).To(Equal("unchanged"))
+		for _, change := range output.Changes {
+			Expect(change).NotTo(HaveKey("source_hash"))
+			Expect(change).NotTo(HaveKey("destination_hash"))
+		}
+		Expect(string(session.Out.Contents())).NotTo(ContainSubstring(shortHash("some-api-key")))
+	})
+
+	It("makes no changes with --dry-run", func() {
+		session := runCommand("sync", "--from", fromConfig, "--to", toConfig, "-p", "/staging/cf", "--dest", "/prod/cf", "--mode", "overwrite", "--dry-run")
+
+		Eventually(session).Should(Exit(0))
+		Expect(session.Out).To(Say(`Dry run of /staging/cf/ to /prod/cf/: 1 to create, 1 to update, 1 unchanged, 1 skipped`))
+		Expect(setRequests).To(BeEmpty())
+	})
+
+	It("only syncs credentials matching the include and exclude globs", func() {
+		session := runCommand("sync", "--from", fromConfig, "--to", toConfig, "-p", "/staging/cf", "--dest", "/prod/cf", "--mode", "overwrite", "--include", "a*", "--exclude", "api-*")
+
+		Eventually(session).Should(Exit(0))
+		Expect(setNames()).To(Equal([]string{"/prod/cf/admin"}))
+	})
+
+	It("syncs to the same path by default", func() {
+		session := runCommand("sync", "--from", fromConfig, "--to", toConfig, "-p", "/staging/cf", "--include", "api-key")
+
+		Eventually(session).Should(Exit(0))
+		Expect(setNames()).To(Equal([]string{"/staging/cf/api-key"}))
+	})
+
+	It("sets certificate authorities first and renames the CA of the certificates they sign", func() {
+		source = map[string]string{
+			"/staging/certs/leaf": credentialJSON("/staging/certs/leaf", "certificate", `{"ca_name":"/staging/certs/ca","ca":"ca-cert","certificate":"leaf-cert","private_key":"leaf-key"}`),
+			"/staging/certs/ca":   credentialJSON("/staging/certs/ca", "certificate", `{"ca_name":"/staging/certs/ca","ca":"ca-cert","certificate":"ca-cert","private_key":"ca-key"}`),
+			"/staging/certs/ext":  credentialJSON("/staging/certs/ext", "certificate", `{"ca_name":"/elsewhere/ca","ca":"other-ca-cert","certificate":"ext-cert","private_key":"ext-key"}`),
+		}
+
+		session := runCommand("sync", "--from", fromConfig, "--to", toConfig, "-p", "/staging/certs", "--dest", "/prod/certs")
+
+		Eventually(session).Should(Exit(0))
+		Expect(setRequests).To(Equal([]map[string]interface{}{
+			{"name": "/prod/certs/ca", "type": "certificate", "value": map[string]interface{}{"ca": "ca-cert", "certificate": "ca-cert", "private_key": "ca-key"}},
+			{"name": "/prod/certs/ext", "type": "certificate", "value": map[string]interface{}{"ca": "other-ca-cert", "certificate": "ext-cert", "private_key": "ext-key"}},
+			{"name": "/prod/certs/leaf", "type": "certificate", "value": map[string]interface{}{"ca": "", "ca_name": "/prod/certs/ca", "certificate": "leaf-cert", "private_key": "leaf-key"}},
+		}))
+		Expect(string(session.Out.Contents())).NotTo(ContainSubstring("-key"))
+	})
+
+	It("returns an error for an unknown mode", func() {
+		session := runCommand("sync", "--from", fromConfig, "--to", toConfig, "-p", "/staging/cf", "--mode", "converge")
+
+		Eventually(session).Should(Exit(1))
+		Expect(session.Err).To(Say(`The mode "converge" is not supported. Valid modes are 'create-only' and 'overwrite'.`))
+	})
+
+	It("returns an error when a target cannot be read", func() {
+		session := runCommand("sync", "--from", "missing.json", "--to", toConfig, "-p", "/staging/cf")
+
+		Eventually(session).Should(Exit(1))
+		Expect(session.Err).To(Say(`The sync target "missing.json" could not be used: `))
+	})
+
+	It("returns an error when a target has no API set", func() {
+		Expect(ioutil.WriteFile(toConfig, []byte(`{}`), 0600)).To(Succeed())
+
+		session := runCommand("sync", "--from", fromConfig, "--to", toConfig, "-p", "/staging/cf")
+
+		Eventually(session).Should(Exit(1))
+		Expect(session.Err).To(Say(`could not be used: An API target is not set`))
+	})
+
+	It("returns an error when no credentials exist under the path", func() {
+		session := runCommand("sync", "--from", fromConfig, "--to", toConfig, "-p", "/missing")
+
+		Eventually(session).Should(Exit(1))
+		Expect(session.Err).To(Say("No credentials exist which match the provided parameters."))
+	})
+})
@@ -3,22 +3,125 @@ package commands
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"code.cloudfoundry.org/credhub-cli/config"
+	"code.cloudfoundry.org/credhub-cli/credhub"
 	"code.cloudfoundry.org/credhub-cli/credhub/auth"
+	"code.cloudfoundry.org/credhub-cli/credhub/auth/uaa"
+	"code.cloudfoundry.org/credhub-cli/errors"
 )
 
+type TokenCommand struct {
+	Inspect TokenInspectCommand `command:"inspect" description:"Decode and display the current authentication token" long-description:"Decode and display the claims of the current authentication token, including the user or client, scopes, authorities, issuer and expiry. The token signature can be verified against the keys published by the auth server."`
+}
+
+type TokenInspectCommand struct {
+	Verify bool `long:"verify" description:"Verify the token signature against the auth server's token keys"`
+	ConfigCommand
+}
+
+type tokenDetails struct {
+	UserID        string   `json:"user_id,omitempty" yaml:"user_id,omitempty"`
+	UserName      string   `json:"user_name,omitempty" yaml:"user_name,omitempty"`
+	ClientID      string   `json:"client_id" yaml:"client_id"`
+	GrantType     string   `json:"grant_type,omitempty" yaml:"grant_type,omitempty"`
+	Scopes        []string `json:"scopes" yaml:"scopes"`
+	Authorities   []string `json:"authorities,omitempty" yaml:"authorities,omitempty"`
+	Issuer        string   `json:"issuer" yaml:"issuer"`
+	ZoneID        string   `json:"zone_id" yaml:"zone_id"`
+	IssuedAt      string   `json:"issued_at" yaml:"issued_at"`
+	ExpiresAt     string   `json:"expires_at" yaml:"expires_at"`
+	TimeRemaining string   `json:"time_remaining" yaml:"time_remaining"`
+	Signature     string   `json:"signature,omitempty" yaml:"signature,omitempty"`
+}
+
+func (c *TokenInspectCommand) Execute([]string) error {
+	cfg := c.config
+
+	if cfg.BearerToken != "" {
+		cfg.AccessToken = cfg.BearerToken
+	}
+
+	if (cfg.AccessToken == "" || cfg.AccessToken == "revoked") && clientCredentialsInEnvironment() {
+		refreshed, err := refreshConfiguration(cfg)
+		if err != nil {
+			return err
+		}
+		cfg = refreshed
+	}
+
+	if cfg.AccessToken == "" || cfg.AccessToken == "revoked" {
+		return errors.NewRevokedTokenError()
+	}
+
+	token, err := uaa.DecodeToken(cfg.AccessToken)
+	if err != nil {
+		return errors.NewInvalidTokenError(err)
+	}
+
+	details := tokenDetails{
+		UserID:        token.Claims.UserID,
+		UserName:      token.Claims.UserName,
+		ClientID:      token.Claims.ClientID,
+		GrantType:     token.Claims.GrantType,
+		Scopes:        token.Claims.Scopes,
+		Authorities:   token.Claims.Authorities,
+		Issuer:        token.Claims.Issuer,
+		ZoneID:        token.Claims.ZoneID,
+		IssuedAt:      token.Claims.IssuedAtTime().Format(time.RFC3339),
+		ExpiresAt:     token.Claims.ExpiresAtTime().Format(time.RFC3339),
+		TimeRemaining: timeRemaining(token.Claims.ExpiresAtTime()),
+	}
+
+	if c.Verify {
+		if err := verifyToken(cfg, token); err != nil {
+			return errors.NewTokenVerificationError(err)
+		}
+		details.Signature = "verified"
+	}
+
+	return printCredential(false, details)
+}
+
+func verifyToken(cfg config.Config, token *uaa.Token) error {
+	credhubClient, err := credhub.New(cfg.ApiURL, credhub.CaCerts(cfg.CaCerts...), credhub.SkipTLSValidation(cfg.InsecureSkipVerify))
+	if err != nil {
+		return err
+	}
+
+	uaaClient := uaa.Client{
+		AuthURL: cfg.AuthURL,
+		Client:  credhubClient.Client(),
+	}
+
+	keys, err := uaaClient.TokenKeys()
+	if err != nil {
+		return err
+	}
+
+	return token.Verify(keys)
+}
+
+func timeRemaining(expiresAt time.Time) string {
+	remaining := time.Until(expiresAt).Round(time.Second)
+	if remaining <= 0 {
+		return "expired"
+	}
+	return remaining.String()
+}
+
 func init() {
 	CredHub.Token = func() {
 		cfg := config.ReadConfig()
 
-		if cfg.AccessToken != "" && cfg.AccessToken != "revoked" {
-			cfg = refreshConfiguration(cfg)
+		if cfg.BearerToken != "" {
+			fmt.Println("Bearer " + cfg.BearerToken)
+		} else if cfg.AccessToken != "" && cfg.AccessToken != "revoked" {
+			cfg = printRefreshedToken(cfg)
 			config.WriteConfig(cfg)
-			fmt.Println("Bearer " + cfg.AccessToken)
 		} else if os.Getenv("CREDHUB_CLIENT") != "" && os.Getenv("CREDHUB_SECRET") != "" {
-			cfg = refreshConfiguration(cfg)
-			fmt.Println("Bearer " + cfg.AccessToken)
+			printRefreshedToken(cfg)
 		} else {
 			fmt.Fprint(os.Stderr, "You are not currently authenticated. Please log in to continue.")
 		}
@@ -26,17 +129,52 @@ func init() {
 	}
 }
 
-func refreshConfiguration(cfg config.Config) config.Config {
-	credhubClient, _ := initializeCredhubClient(cfg)
-	authObject := credhubClient.Auth
-	oauth := authObject.(*auth.OAuthStrategy)
-	err := oauth.Refresh()
-
+// refreshConfiguration refreshes the tokens in the config. An error is
+// returned when the client does not authenticate with UAA, e.g. when a client
+// certificate, credential process or static token is configured, or when the
+// tokens cannot be refreshed.
+func refreshConfiguration(cfg config.Config) (config.Config, error) {
+	oauth, err := oauthStrategy(cfg)
 	if err != nil {
-		fmt.Println("Bearer " + cfg.AccessToken)
+		return cfg, err
+	}
+
+	if err := oauth.Refresh(); err != nil {
+		return cfg, err
 	}
 
 	cfg.AccessToken = oauth.AccessToken()
 	cfg.RefreshToken = oauth.RefreshToken()
+	return cfg, nil
+}
+
+// printRefreshedToken refreshes the tokens in the config and prints the access
+// token. The current access token is printed when it cannot be refreshed.
+func printRefreshedToken(cfg config.Config) config.Config {
+	oauth, err := oauthStrategy(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+
+	if err := oauth.Refresh(); err == nil {
+		cfg.AccessToken = oauth.AccessToken()
+		cfg.RefreshToken = oauth.RefreshToken()
+	}
+
+	fmt.Println("Bearer " + cfg.AccessToken)
 	return cfg
 }
+
+func oauthStrategy(cfg config.Config) (*auth.OAuthStrategy, error) {
+	credhubClient, err := initializeCredhubClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	oauth, ok := credhubClient.Auth.(*auth.OAuthStrategy)
+	if !ok {
+		return nil, errors.NewTokenNotRefreshableError()
+	}
+	return oauth, nil
+}
@@ -1,11 +1,19 @@
 package commands_test
 
 import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"math/big"
 	"net/http"
 
 	"code.cloudfoundry.org/credhub-cli/config"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
 	. "github.com/onsi/gomega/gexec"
 	. "github.com/onsi/gomega/ghttp"
 )
@@ -97,3 +105,135 @@ var _ = Describe("Token", func() {
 		})
 	})
 })
+
+var _ = Describe("Token inspect", func() {
+	BeforeEach(func() {
+		cfg := config.ReadConfig()
+		cfg.AccessToken = VALID_ACCESS_TOKEN
+		config.WriteConfig(cfg)
+	})
+
+	It("displays the claims of the current token", func() {
+		session := runCommand("token", "inspect")
+
+		Eventually(session).Should(Exit(0))
+		Eventually(session.Out).Should(Say("user_id: 6787bb7e-78bb-4be6-9583-42a75ddba3d5"))
+		Eventually(session.Out).Should(Say("user_name: credhub"))
+		Eventually(session.Out).Should(Say("client_id: credhub_cli"))
+		Eventually(session.Out).Should(Say("grant_type: password"))
+		Eventually(session.Out).Should(Say(`scopes:
+- credhub.write
+- credhub.read`))
+		Eventually(session.Out).Should(Say("issuer: https://34.206.233.195:8443/oauth/token"))
+		Eventually(session.Out).Should(Say("zone_id: uaa"))
+		Eventually(session.Out).Should(Say("issued_at: \"2017-09-07T21:59:45Z\""))
+		Eventually(session.Out).Should(Say("expires_at: \"2017-09-08T21:59:45Z\""))
+		Eventually(session.Out).Should(Say("time_remaining: expired"))
+	})
+
+	It("can output json", func() {
+		session := runCommand("token", "inspect", "--output", "json")
+
+		Eventually(session).Should(Exit(0))
+		Eventually(session.Out).Should(Say(`"client_id": "credhub_cli"`))
+	})
+
+	It("requires a current token", func() {
+		cfg := config.ReadConfig()
+		cfg.AccessToken = "revoked"
+		config.WriteConfig(cfg)
+
+		session := runCommand("token", "inspect")
+
+		Eventually(session).Should(Exit(1))
+		Eventually(session.Err).Should(Say("You are not currently authenticated. Please log in to continue."))
+	})
+
+	It("returns an error when client credentials are set but the config does not use UAA", func() {
+		cfg := config.ReadConfig()
+		cfg.AccessToken = "revoked"
+		cfg.CredentialProcess = "echo some-token"
+		config.WriteConfig(cfg)
+
+		session := runCommandWithEnv([]string{"CREDHUB_CLIENT=some-client", "CREDHUB_SECRET=some-secret"}, "token", "inspect")
+
+		Eventually(session).Should(Exit(1))
+		Eventually(session.Err).Should(Say("The configured authentication method does not use a token that can be refreshed."))
+	})
+
+	It("returns an error without printing a token when the token cannot be refreshed", func() {
+		cfg := config.ReadConfig()
+		cfg.AccessToken = "revoked"
+		config.WriteConfig(cfg)
+
+		authServer.RouteToHandler("POST", "/oauth/token",
+			RespondWith(http.StatusUnauthorized, `{"error":"unauthorized","error_description":"Bad credentials"}`),
+		)
+
+		session := runCommandWithEnv([]string{"CREDHUB_CLIENT=some-client", "CREDHUB_SECRET=some-secret"}, "token", "inspect")
+
+		Eventually(session).Should(Exit(1))
+		Expect(session.Out.Contents()).NotTo(ContainSubstring("Bearer"))
+		Expect(session.Err.Contents()).NotTo(BeEmpty())
+	})
+
+	It("returns an error when the token cannot be decoded", func() {
+		cfg := config.ReadConfig()
+		cfg.AccessToken = "not-a-token"
+		config.WriteConfig(cfg)
+
+		session := runCommand("token", "inspect")
+
+		Eventually(session).Should(Exit(1))
+		Eventually(session.Err).Should(Say("The current authentication token could not be decoded"))
+	})
+
+	Context("with --verify", func() {
+		var key *rsa.PrivateKey
+
+		BeforeEach(func() {
+			var err error
+			key, err = rsa.GenerateKey(rand.Reader, 1024)
+			Expect(err).NotTo(HaveOccurred())
+
+			signingInput := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","kid":"key-1"}`)) + "." +
+				base64.RawURLEncoding.EncodeToString([]byte(`{"jti":"1","client_id":"credhub_cli","exp":1504907985}`))
+			digest := sha256.Sum256([]byte(signingInput))
+			signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+			Expect(err).NotTo(HaveOccurred())
+
+			cfg := config.ReadConfig()
+			cfg.AccessToken = signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+			config.WriteConfig(cfg)
+		})
+
+		It("verifies the signature against the auth server token keys", func() {
+			authServer.RouteToHandler("GET", "/token_keys",
+				RespondWith(http.StatusOK, fmt.Sprintf(`{"keys":[{"kty":"RSA","kid":"key-1","alg":"RS256","n":"%s","e":"%s"}]}`,
+					base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+					base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()))),
+			)
+
+			session := runCommand("token", "inspect", "--verify")
+
+			Eventually(session).Should(Exit(0))
+			Eventually(session.Out).Should(Say("signature: verified"))
+		})
+
+		It("fails when the signature does not match", func() {
+			otherKey, err := rsa.GenerateKey(rand.Reader, 1024)
+			Expect(err).NotTo(HaveOccurred())
+
+			authServer.RouteToHandler("GET", "/token_keys",
+				RespondWith(http.StatusOK, fmt.Sprintf(`{"keys":[{"kty":"RSA","kid":"key-1","alg":"RS256","n":"%s","e":"%s"}]}`,
+					base64.RawURLEncoding.EncodeToString(otherKey.N.Bytes()),
+					base64.RawURLEncoding.EncodeToString(big.NewInt(int64(otherKey.E)).Bytes()))),
+			)
+
+			session := runCommand("token", "inspect", "--verify")
+
+			Eventually(session).Should(Exit(1))
+			Eventually(session.Err).Should(Say("The current authentication token could not be verified: token signature is invalid"))
+		})
+	})
+})
@@ -0,0 +1,213 @@
+package commands
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"code.cloudfoundry.org/credhub-cli/errors"
+)
+
+type TreeCommand struct {
+	Args        PathPositionalArgs `positional-args:"yes"`
+	Concurrency int                `long:"concurrency" default:"4" description:"Number of credentials to look up the type of at the same time"`
+	ClientCommand
+}
+
+type PathPositionalArgs struct {
+	Path string `positional-arg-name:"PATH" description:"Path to show, defaults to the current path"`
+}
+
+type pathNode struct {
+	Path        string          `json:"path" yaml:"path"`
+	Count       int             `json:"credentials_count" yaml:"credentials_count"`
+	Types       map[string]int  `json:"types" yaml:"types"`
+	Children    []*pathNode     `json:"children" yaml:"children"`
+	Credentials []credentialRef `json:"credentials" yaml:"credentials"`
+}
+
+type credentialRef struct {
+	Name string `json:"name" yaml:"name"`
+	Type string `json:"type" yaml:"type"`
+}
+
+func (c *TreeCommand) ResolveNames() {
+	c.Args.Path = c.resolvePath(c.Args.Path)
+}
+
+func (c *TreeCommand) Execute([]string) error {
+	root, err := buildPathTree(c.ClientCommand, c.Args.Path, true, c.Concurrency)
+	if err != nil {
+		return err
+	}
+
+	if structuredOutput(false) {
+		return printCredential(false, root)
+	}
+
+	fmt.Println(root.Path + " " + root.summary())
+	root.printChildren("")
+	return nil
+}
+
+// buildPathTree returns the paths and credentials under the path, with the
+// number and types of the credentials under each path. The type of each
+// credential requires a request, so unless allTypes is set only the types of
+// the credentials directly under the path are looked up, with up to
+// concurrency requests at the same time.
+func buildPathTree(c ClientCommand, requested string, allTypes bool, concurrency int) (*pathNode, error) {
+	prefix := strings.TrimSuffix(absoluteName(requested), "/") + "/"
+
+	paths, err := c.client.FindAllPaths()
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := c.client.FindByPath(path.Clean(prefix))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results.Credentials) == 0 {
+		return nil, errors.NewNoMatchingCredentialsFoundError()
+	}
+
+	root := &pathNode{Path: prefix}
+	nodes := map[string]*pathNode{prefix: root}
+
+	var node func(p string) *pathNode
+	node = func(p string) *pathNode {
+		if n, ok := nodes[p]; ok {
+			return n
+		}
+		n := &pathNode{Path: p}
+		nodes[p] = n
+		parent := node(p[:strings.LastIndex(strings.TrimSuffix(p, "/"), "/")+1])
+		parent.Children = append(parent.Children, n)
+		return n
+	}
+
+	for _, found := range paths.Paths {
+		p := strings.TrimSuffix(absoluteName(found.Path), "/") + "/"
+		if strings.HasPrefix(p, prefix) {
+			node(p)
+		}
+	}
+
+	var (
+		typed   []string
+		parents = map[string]*pathNode{}
+	)
+
+	for _, credential := range results.Credentials {
+		name := absoluteName(credential.Name)
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		parent := node(name[:strings.LastIndex(name, "/")+1])
+		parents[name] = parent
+
+		if allTypes || parent == root {
+			typed = append(typed, name)
+		} else {
+			parent.Credentials = append(parent.Credentials, credentialRef{Name: name})
+		}
+	}
+
+	latest, err := fetchLatestVersions(c.client, typed, concurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, name := range typed {
+		parents[name].Credentials = append(parents[name].Credentials, credentialRef{Name: name, Type: latest[i].Type})
+	}
+
+	root.count()
+	return root, nil
+}
+
+// count sorts the node and fills in the number and types of the credentials under it
+func (n *pathNode) count() {
+	n.Count = len(n.Credentials)
+	n.Types = map[string]int{}
+
+	sort.Slice(n.Credentials, func(i, j int) bool { return n.Credentials[i].Name < n.Credentials[j].Name })
+	sort.Slice(n.Children, func(i, j int) bool { return n.Children[i].Path < n.Children[j].Path })
+
+	for _, credential := range n.Credentials {
+		if credential.Type != "" {
+			n.Types[credential.Type]++
+		}
+	}
+
+	for _, child := range n.Children {
+		child.count()
+		n.Count += child.Count
+		for t, count := range child.Types {
+			n.Types[t] += count
+		}
+	}
+}
+
+// summary describes the number and types of the credentials under the node, e.g. "(3 credentials: 2 password, 1 certificate)"
+func (n *pathNode) summary() string {
+	var types []string
+	for t := range n.Types {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if n.Types[types[i]] != n.Types[types[j]] {
+			return n.Types[types[i]] > n.Types[types[j]]
+		}
+		return types[i] < types[j]
+	})
+
+	counts := make([]string, len(types))
+	for i, t := range types {
+		counts[i] = fmt.Sprintf("%d %s", n.Types[t], t)
+	}
+
+	noun := "credentials"
+	if n.Count == 1 {
+		noun = "credential"
+	}
+
+	if len(counts) == 0 {
+		return fmt.Sprintf("(%d %s)", n.Count, noun)
+	}
+
+	return fmt.Sprintf("(%d %s: %s)", n.Count, noun, strings.Join(counts, ", "))
+}
+
+// name returns the last element of the node's path, e.g. "cf/"
+func (n *pathNode) name() string {
+	trimmed := strings.TrimSuffix(n.Path, "/")
+	return trimmed[strings.LastIndex(trimmed, "/")+1:] + "/"
+}
+
+func (n *pathNode) printChildren(indent string) {
+	total := len(n.Children) + len(n.Credentials)
+	i := 0
+
+	branch := func() (string, string) {
+		i++
+		if i == total {
+			return "└── ", "    "
+		}
+		return "├── ", "│   "
+	}
+
+	for _, child := range n.Children {
+		prefix, next := branch()
+		fmt.Println(indent + prefix + child.name() + " " + child.summary())
+		child.printChildren(indent + next)
+	}
+
+	for _, credential := range n.Credentials {
+		prefix, _ := branch()
+		fmt.Println(indent + prefix + credential.Name[strings.LastIndex(credential.Name, "/")+1:] + " [" + credential.Type + "]")
+	}
+}
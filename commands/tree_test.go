@@ -0,0 +1,129 @@
+package commands_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+)
+
+// servePathTree responds to path, find and get requests for credentials with the given names and types
+func servePathTree(types map[string]string) {
+	server.RouteToHandler("GET", "/api/v1/data", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		var names []string
+		for name := range types {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		switch {
+		case query.Get("paths") == "true":
+			paths := []map[string]string{}
+			seen := map[string]bool{}
+			for _, name := range names {
+				path := name[:strings.LastIndex(name, "/")+1]
+				if !seen[path] {
+					seen[path] = true
+					paths = append(paths, map[string]string{"path": path})
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"paths": paths})
+		case query.Get("path") != "":
+			prefix := strings.TrimSuffix(query.Get("path"), "/") + "/"
+			found := []map[string]string{}
+			for _, name := range names {
+				if strings.HasPrefix(name, prefix) {
+					found = append(found, map[string]string{"name": name, "version_created_at": TIMESTAMP})
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"credentials": found})
+		case query.Get("name") != "":
+			name := query.Get("name")
+			w.Write([]byte(`{"data":[{"type":"` + types[name] + `","id":"` + UUID + `","name":"` + name + `","version_created_at":"` + TIMESTAMP + `","value":"some-value"}]}`))
+		}
+	})
+}
+
+var _ = Describe("Tree", func() {
+	BeforeEach(func() {
+		login()
+
+		servePathTree(map[string]string{
+			"/deploy/cf/admin":       "password",
+			"/deploy/cf/ca":          "certificate",
+			"/deploy/cf/diego/cert":  "certificate",
+			"/deploy/db-password":    "password",
+			"/other/some-credential": "value",
+		})
+	})
+
+	ItRequiresAuthentication("tree", "/deploy")
+	ItRequiresAnAPIToBeSet("tree", "/deploy")
+
+	ItBehavesLikeHelp("tree", "tree", func(session *Session) {
+		Expect(session.Err).To(Say("tree"))
+		Expect(session.Err).To(Say("PATH"))
+	})
+
+	It("shows the paths and credentials under the path as a tree", func() {
+		session := runCommand("tree", "deploy/")
+
+		Eventually(session).Should(Exit(0))
+		Expect(string(session.Out.Contents())).To(Equal(`/deploy/ (4 credentials: 2 certificate, 2 password)
+├── cf/ (3 credentials: 2 certificate, 1 password)
+│   ├── diego/ (1 credential: 1 certificate)
+│   │   └── cert [certificate]
+│   ├── admin [password]
+│   └── ca [certificate]
+└── db-password [password]
+`))
+	})
+
+	It("shows every path when no path is provided", func() {
+		session := runCommand("tree")
+
+		Eventually(session).Should(Exit(0))
+		Expect(session.Out).To(Say(`^/ \(5 credentials: 2 certificate, 2 password, 1 value\)`))
+		Expect(session.Out).To(Say(`├── deploy/ \(4 credentials`))
+		Expect(session.Out).To(Say(`└── other/ \(1 credential: 1 value\)`))
+		Expect(session.Out).To(Say(`    └── some-credential \[value\]`))
+	})
+
+	It("does not list the types when none are known", func() {
+		servePathTree(map[string]string{
+			"/unknown/some-credential": "",
+		})
+
+		session := runCommand("tree", "/unknown", "--concurrency", "2")
+
+		Eventually(session).Should(Exit(0))
+		Expect(session.Out).To(Say(`^/unknown/ \(1 credential\)\n`))
+	})
+
+	It("returns the tree in JSON format", func() {
+		session := runCommand("tree", "/deploy/cf/diego", "--output", "json")
+
+		Eventually(session).Should(Exit(0))
+		Expect(session.Out.Contents()).To(MatchJSON(`{
+			"path": "/deploy/cf/diego/",
+			"credentials_count": 1,
+			"types": {"certificate": 1},
+			"children": null,
+			"credentials": [{"name": "/deploy/cf/diego/cert", "type": "certificate"}]
+		}`))
+	})
+
+	It("returns an error when there are no credentials under the path", func() {
+		session := runCommand("tree", "/missing")
+
+		Eventually(session).Should(Exit(1))
+		Expect(session.Err).To(Say("No credentials exist which match the provided parameters."))
+	})
+})
@@ -6,7 +6,9 @@ import (
 	"io/ioutil"
 	"os"
 	"path"
+	"strings"
 
+	"code.cloudfoundry.org/credhub-cli/errors"
 	"code.cloudfoundry.org/credhub-cli/util"
 )
 
@@ -23,6 +25,21 @@ type Config struct {
 	ServerVersion      string
 	ClientID           string
 	ClientSecret       string
+	ClientCertPath     string
+	ClientKeyPath      string
+	CredentialProcess  string
+	CurrentPath        string
+
+	// BearerToken is a pre-issued token from CREDHUB_TOKEN or CREDHUB_TOKEN_FILE. It is never saved.
+	BearerToken string `json:"-"`
+
+	// tokenFileErr is the error reading CREDHUB_TOKEN_FILE, which is returned by ValidateConfig
+	tokenFileErr error
+}
+
+// UsesClientCertificate returns true when the CLI authenticates with a mutual TLS client certificate instead of UAA
+func (cfg *Config) UsesClientCertificate() bool {
+	return cfg.ClientCertPath != "" && cfg.ClientKeyPath != ""
 }
 
 func ConfigDir() string {
@@ -57,6 +74,24 @@ func ReadConfig() Config {
 	if clientSecret, ok := os.LookupEnv("CREDHUB_SECRET"); ok {
 		c.ClientSecret = clientSecret
 	}
+	if clientCert, ok := os.LookupEnv("CREDHUB_CLIENT_CERT"); ok {
+		c.ClientCertPath = clientCert
+	}
+	if clientKey, ok := os.LookupEnv("CREDHUB_CLIENT_KEY"); ok {
+		c.ClientKeyPath = clientKey
+	}
+	if credentialProcess, ok := os.LookupEnv("CREDHUB_CREDENTIAL_PROCESS"); ok {
+		c.CredentialProcess = credentialProcess
+	}
+	if token, ok := os.LookupEnv("CREDHUB_TOKEN"); ok {
+		c.BearerToken = strings.TrimSpace(token)
+	} else if tokenFile, ok := os.LookupEnv("CREDHUB_TOKEN_FILE"); ok {
+		token, err := ioutil.ReadFile(tokenFile)
+		if err != nil {
+			c.tokenFileErr = errors.NewTokenFileError(tokenFile, err)
+		}
+		c.BearerToken = strings.TrimSpace(string(token))
+	}
 	if caCert, ok := os.LookupEnv("CREDHUB_CA_CERT"); ok {
 		certs, err := ReadOrGetCaCerts([]string{caCert})
 		if err != nil {
@@ -69,6 +104,21 @@ func ReadConfig() Config {
 	return c
 }
 
+// ReadConfigFile reads a config file written by the CLI, e.g. a copy of the
+// config of another target. Unlike ReadConfig, environment variables are not applied.
+func ReadConfigFile(path string) (Config, error) {
+	c := Config{}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return c, err
+	}
+
+	err = json.Unmarshal(data, &c)
+
+	return c, err
+}
+
 func WriteConfig(c Config) error {
 	err := makeDirectory()
 	if err != nil {
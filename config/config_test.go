@@ -4,6 +4,7 @@ package config_test
 
 import (
 	"io/ioutil"
+	"os"
 
 	"code.cloudfoundry.org/credhub-cli/config"
 	. "github.com/onsi/ginkgo"
@@ -91,4 +92,31 @@ var _ = Describe("Config", func() {
 			Expect(cfg.CaCerts).To(HaveLen(0))
 		})
 	})
+	Describe("#ReadConfigFile", func() {
+		It("reads a config file without applying environment variables", func() {
+			file, err := ioutil.TempFile("", "config")
+			Expect(err).To(BeNil())
+			defer os.Remove(file.Name())
+
+			_, err = file.WriteString(`{"ApiURL":"https://other.example.com","AccessToken":"some-token","CurrentPath":"/some/path"}`)
+			Expect(err).To(BeNil())
+			file.Close()
+
+			os.Setenv("CREDHUB_SERVER", "https://env.example.com")
+			defer os.Unsetenv("CREDHUB_SERVER")
+
+			cfg, err := config.ReadConfigFile(file.Name())
+
+			Expect(err).To(BeNil())
+			Expect(cfg.ApiURL).To(Equal("https://other.example.com"))
+			Expect(cfg.AccessToken).To(Equal("some-token"))
+			Expect(cfg.CurrentPath).To(Equal("/some/path"))
+		})
+
+		It("returns an error if the file can't be read", func() {
+			_, err := config.ReadConfigFile("/does/not/exist.json")
+
+			Expect(err).NotTo(BeNil())
+		})
+	})
 })
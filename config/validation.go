@@ -6,7 +6,9 @@ func ValidateConfig(c Config) error {
 	err := ValidateConfigApi(c)
 	if err != nil {
 		return err
-	} else if (c.AccessToken == "" || c.AccessToken == "revoked") && c.ClientID == "" {
+	} else if c.tokenFileErr != nil {
+		return c.tokenFileErr
+	} else if (c.AccessToken == "" || c.AccessToken == "revoked") && c.ClientID == "" && !c.UsesClientCertificate() && c.CredentialProcess == "" && c.BearerToken == "" {
 		return errors.NewRevokedTokenError()
 	}
 
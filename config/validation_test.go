@@ -2,6 +2,7 @@ package config_test
 
 import (
 	"errors"
+	"os"
 
 	"code.cloudfoundry.org/credhub-cli/config"
 	. "github.com/onsi/ginkgo"
@@ -40,4 +41,25 @@ var _ = Describe("Config validation", func() {
 		Expect(config.ValidateConfig(cfg)).To(Equal(errors.New("You are not currently authenticated. Please log in to continue.")))
 
 	})
+
+	It("does not require a token when a client certificate is configured", func() {
+		cfg := config.Config{}
+		cfg.ApiURL = "http://api.example.com"
+		cfg.AccessToken = "revoked"
+		cfg.ClientCertPath = "/path/to/client.crt"
+		cfg.ClientKeyPath = "/path/to/client.key"
+
+		Expect(config.ValidateConfig(cfg)).To(BeNil())
+	})
+
+	It("returns the error reading the token file", func() {
+		os.Setenv("CREDHUB_SERVER", "https://api.example.com")
+		os.Setenv("CREDHUB_TOKEN_FILE", "/does/not/exist")
+		defer os.Unsetenv("CREDHUB_SERVER")
+		defer os.Unsetenv("CREDHUB_TOKEN_FILE")
+
+		err := config.ValidateConfig(config.ReadConfig())
+
+		Expect(err).To(MatchError(ContainSubstring(`The token file "/does/not/exist" set in CREDHUB_TOKEN_FILE could not be read`)))
+	})
 })
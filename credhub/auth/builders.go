@@ -3,6 +3,7 @@ package auth
 import (
 	"net/http"
 
+	"code.cloudfoundry.org/credhub-cli/credhub/auth/oidc"
 	"code.cloudfoundry.org/credhub-cli/credhub/auth/uaa"
 )
 
@@ -24,6 +25,33 @@ var Noop Builder = func(config Config) (Strategy, error) {
 	return &NoopStrategy{config.Client()}, nil
 }
 
+// StaticToken builds a StaticTokenStrategy that authenticates with a pre-issued bearer token
+func StaticToken(token string) Builder {
+	return func(config Config) (Strategy, error) {
+		return &StaticTokenStrategy{
+			Token:     token,
+			ApiClient: config.Client(),
+		}, nil
+	}
+}
+
+// CredentialProcess builds an ExecStrategy that gets bearer tokens by running commandLine
+//
+// The command line is split into words like a POSIX shell would, but is not run by a shell.
+func CredentialProcess(commandLine string) Builder {
+	return func(config Config) (Strategy, error) {
+		command, err := splitCommandLine(commandLine)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ExecStrategy{
+			Command:   command,
+			ApiClient: config.Client(),
+		}, nil
+	}
+}
+
 // UaaPassword builds an OauthStrategy for UAA using password_grant token requests
 func UaaPassword(clientId, clientSecret, username, password string) Builder {
 	return Uaa(clientId, clientSecret, username, password, "", "", false)
@@ -64,3 +92,75 @@ func Uaa(clientId, clientSecret, username, password, accessToken, refreshToken s
 		return oauth, nil
 	}
 }
+
+// Oidc builds an OauthStrategy for an OpenID Connect provider using existing tokens
+//
+// Endpoints are discovered from the provider's /.well-known/openid-configuration and
+// the client authenticates with client_secret_basic. If issuer is empty, the CredHub
+// server's auth server URL is used as the issuer.
+func Oidc(issuer, clientId, clientSecret, username, password, accessToken, refreshToken string, usingClientCredentials bool) Builder {
+	return func(config Config) (Strategy, error) {
+		oidcClient, err := newOidcClient(config, issuer, clientId, clientSecret)
+		if err != nil {
+			return nil, err
+		}
+
+		oauth := &OAuthStrategy{
+			Username:                username,
+			Password:                password,
+			ClientId:                clientId,
+			ClientSecret:            clientSecret,
+			ApiClient:               config.Client(),
+			OAuthClient:             oidcClient,
+			ClientCredentialRefresh: usingClientCredentials,
+		}
+
+		oauth.SetTokens(accessToken, refreshToken)
+
+		return oauth, nil
+	}
+}
+
+// OidcPrivateKeyJWT builds an OauthStrategy for an OpenID Connect provider using client_credentials grant token requests
+//
+// The client authenticates with a JWT assertion signed by the PEM encoded privateKey (private_key_jwt).
+// If issuer is empty, the CredHub server's auth server URL is used as the issuer.
+func OidcPrivateKeyJWT(issuer, clientId, privateKey, keyId string) Builder {
+	return func(config Config) (Strategy, error) {
+		key, err := oidc.ParsePrivateKey(privateKey, keyId)
+		if err != nil {
+			return nil, err
+		}
+
+		oidcClient, err := newOidcClient(config, issuer, clientId, "")
+		if err != nil {
+			return nil, err
+		}
+		oidcClient.AuthMethod = oidc.PrivateKeyJWT
+		oidcClient.PrivateKey = key
+
+		return &OAuthStrategy{
+			ClientId:                clientId,
+			ApiClient:               config.Client(),
+			OAuthClient:             oidcClient,
+			ClientCredentialRefresh: true,
+		}, nil
+	}
+}
+
+func newOidcClient(config Config, issuer, clientId, clientSecret string) (*oidc.Client, error) {
+	if issuer == "" {
+		var err error
+		if issuer, err = config.AuthURL(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &oidc.Client{
+		IssuerURL:    issuer,
+		Client:       config.Client(),
+		AuthMethod:   oidc.ClientSecretBasic,
+		ClientId:     clientId,
+		ClientSecret: clientSecret,
+	}, nil
+}
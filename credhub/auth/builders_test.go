@@ -1,9 +1,14 @@
 package auth
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
 	"errors"
 	"net/http"
 
+	"code.cloudfoundry.org/credhub-cli/credhub/auth/oidc"
 	"code.cloudfoundry.org/credhub-cli/credhub/auth/uaa"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -124,4 +129,122 @@ var _ = Describe("Constructors", func() {
 			})
 		})
 	})
+
+	Describe("Oidc()", func() {
+		It("constructs a OAuthStrategy auth using an OpenID Connect client", func() {
+			config := DummyServerConfig{}
+			builder := Oidc("https://idp.example.com",
+				"some-client-id",
+				"some-client-secret",
+				"some-username",
+				"some-password",
+				"some-access-token",
+				"some-refresh-token",
+				true)
+			strategy, err := builder(&config)
+			Expect(err).NotTo(HaveOccurred())
+			auth := strategy.(*OAuthStrategy)
+			Expect(auth.ClientId).To(Equal("some-client-id"))
+			Expect(auth.ClientSecret).To(Equal("some-client-secret"))
+			Expect(auth.Username).To(Equal("some-username"))
+			Expect(auth.Password).To(Equal("some-password"))
+			Expect(auth.AccessToken()).To(Equal("some-access-token"))
+			Expect(auth.RefreshToken()).To(Equal("some-refresh-token"))
+			oidcClient := auth.OAuthClient.(*oidc.Client)
+			Expect(oidcClient.IssuerURL).To(Equal("https://idp.example.com"))
+			Expect(oidcClient.AuthMethod).To(Equal(oidc.ClientSecretBasic))
+			Expect(oidcClient.ClientId).To(Equal("some-client-id"))
+			Expect(oidcClient.ClientSecret).To(Equal("some-client-secret"))
+			client := config.Client()
+			Expect(oidcClient.Client).To(BeIdenticalTo(client))
+			Expect(auth.ApiClient).To(BeIdenticalTo(client))
+		})
+
+		Context("when no issuer is given", func() {
+			It("uses the auth server URL as the issuer", func() {
+				config := DummyServerConfig{}
+				builder := Oidc("", "some-client-id", "some-client-secret", "", "", "", "", true)
+				strategy, err := builder(&config)
+				Expect(err).NotTo(HaveOccurred())
+				auth := strategy.(*OAuthStrategy)
+				Expect(auth.OAuthClient.(*oidc.Client).IssuerURL).To(Equal("http://example.com/auth/url"))
+			})
+
+			Context("when fetching an Auth URL fails", func() {
+				It("returns an error", func() {
+					config := DummyServerConfig{
+						Error: errors.New("Failed to fetch Auth URL"),
+					}
+					builder := Oidc("", "some-client-id", "some-client-secret", "", "", "", "", true)
+					_, err := builder(&config)
+
+					Expect(err).To(MatchError("Failed to fetch Auth URL"))
+				})
+			})
+		})
+	})
+
+	Describe("OidcPrivateKeyJWT()", func() {
+		It("constructs a OAuthStrategy auth using private_key_jwt client authentication", func() {
+			key, err := rsa.GenerateKey(rand.Reader, 1024)
+			Expect(err).NotTo(HaveOccurred())
+			keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+			config := DummyServerConfig{}
+			builder := OidcPrivateKeyJWT("https://idp.example.com", "some-client-id", string(keyPEM), "some-key-id")
+			strategy, err := builder(&config)
+			Expect(err).NotTo(HaveOccurred())
+			auth := strategy.(*OAuthStrategy)
+			Expect(auth.ClientId).To(Equal("some-client-id"))
+			Expect(auth.ClientSecret).To(BeEmpty())
+			Expect(auth.ClientCredentialRefresh).To(BeTrue())
+			oidcClient := auth.OAuthClient.(*oidc.Client)
+			Expect(oidcClient.AuthMethod).To(Equal(oidc.PrivateKeyJWT))
+			Expect(oidcClient.PrivateKey.KeyID).To(Equal("some-key-id"))
+		})
+
+		Context("when the private key is invalid", func() {
+			It("returns an error", func() {
+				config := DummyServerConfig{}
+				builder := OidcPrivateKeyJWT("https://idp.example.com", "some-client-id", "not-a-key", "")
+				_, err := builder(&config)
+
+				Expect(err).To(MatchError("private key is not PEM encoded"))
+			})
+		})
+	})
+
+	Describe("CredentialProcess()", func() {
+		It("constructs an ExecStrategy with the command split into words", func() {
+			config := DummyServerConfig{}
+			builder := CredentialProcess(`/usr/local/bin/broker fetch --audience "credhub prod" 'it''s' a\ b`)
+			strategy, err := builder(&config)
+			Expect(err).NotTo(HaveOccurred())
+			exec := strategy.(*ExecStrategy)
+			Expect(exec.Command).To(Equal([]string{"/usr/local/bin/broker", "fetch", "--audience", "credhub prod", "its", "a b"}))
+			Expect(exec.ApiClient).To(BeIdenticalTo(config.Client()))
+		})
+
+		Context("when the command has an unterminated quote", func() {
+			It("returns an error", func() {
+				config := DummyServerConfig{}
+				builder := CredentialProcess(`broker "fetch`)
+				_, err := builder(&config)
+
+				Expect(err).To(MatchError(`credential process command "broker \"fetch" has an unterminated quote or escape`))
+			})
+		})
+	})
+
+	Describe("StaticToken()", func() {
+		It("constructs a StaticTokenStrategy with the token", func() {
+			config := DummyServerConfig{}
+			builder := StaticToken("some-access-token")
+			strategy, err := builder(&config)
+			Expect(err).NotTo(HaveOccurred())
+			static := strategy.(*StaticTokenStrategy)
+			Expect(static.Token).To(Equal("some-access-token"))
+			Expect(static.ApiClient).To(BeIdenticalTo(config.Client()))
+		})
+	})
 })
@@ -0,0 +1,196 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ExecStrategy submits requests with a bearer token obtained by running an external command
+//
+// The command must write a JSON object to stdout containing the token and, optionally,
+// its expiry time in RFC 3339 format:
+//
+//	{"token": "some-access-token", "expires_at": "2018-01-01T00:00:00Z"}
+//
+// The token is cached until it expires or the server reports it has expired.
+type ExecStrategy struct {
+	// Command is the program to run followed by its arguments
+	Command   []string
+	ApiClient *http.Client
+
+	mu        sync.Mutex // guards token & expiresAt
+	token     string
+	expiresAt time.Time
+}
+
+type execCredential struct {
+	Token     string `json:"token"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+// expiryWindow is how long before the reported expiry a cached token is considered expired
+const expiryWindow = 30 * time.Second
+
+// Do submits requests with bearer token authorization, using the token returned by the Command.
+//
+// Will automatically re-run the Command and retry the request if the token has expired.
+func (a *ExecStrategy) Do(req *http.Request) (*http.Response, error) {
+	token, err := a.AccessToken()
+	if err != nil {
+		return nil, err
+	}
+
+	clone, err := cloneRequest(req)
+
+	if err != nil {
+		return nil, errors.New("failed to clone request body: " + err.Error())
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := a.ApiClient.Do(req)
+
+	if err != nil {
+		return resp, err
+	}
+
+	expired, err := tokenExpired(resp)
+
+	if err != nil || !expired {
+		return resp, err
+	}
+
+	if err := a.Refresh(); err != nil {
+		return nil, err
+	}
+
+	token, err = a.AccessToken()
+	if err != nil {
+		return nil, err
+	}
+
+	clone.Header.Set("Authorization", "Bearer "+token)
+	return a.ApiClient.Do(clone)
+}
+
+// AccessToken returns the cached token, running the Command first if there is no unexpired token
+func (a *ExecStrategy) AccessToken() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token == "" || (!a.expiresAt.IsZero() && time.Now().Add(expiryWindow).After(a.expiresAt)) {
+		if err := a.run(); err != nil {
+			return "", err
+		}
+	}
+
+	return a.token, nil
+}
+
+// Refresh discards any cached token and runs the Command to get a new one
+func (a *ExecStrategy) Refresh() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.run()
+}
+
+func (a *ExecStrategy) run() error {
+	if len(a.Command) == 0 {
+		return errors.New("no credential process command was provided")
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command(a.Command[0], a.Command[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("credential process failed: %s: %s", err, msg)
+		}
+		return fmt.Errorf("credential process failed: %s", err)
+	}
+
+	var credential execCredential
+	if err := json.Unmarshal(stdout.Bytes(), &credential); err != nil {
+		return errors.New("credential process returned invalid JSON: " + err.Error())
+	}
+
+	if credential.Token == "" {
+		return errors.New("credential process did not return a token")
+	}
+
+	var expiresAt time.Time
+	if credential.ExpiresAt != "" {
+		var err error
+		if expiresAt, err = time.Parse(time.RFC3339, credential.ExpiresAt); err != nil {
+			return errors.New("credential process returned an invalid expires_at: " + err.Error())
+		}
+	}
+
+	a.token = credential.Token
+	a.expiresAt = expiresAt
+
+	return nil
+}
+
+// splitCommandLine splits a command line into words, honoring single quotes,
+// double quotes and backslash escapes the way a POSIX shell would
+func splitCommandLine(commandLine string) ([]string, error) {
+	var (
+		words   []string
+		word    bytes.Buffer
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, r := range commandLine {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if escaped || quote != 0 {
+		return nil, fmt.Errorf("credential process command %q has an unterminated quote or escape", commandLine)
+	}
+
+	if inWord {
+		words = append(words, word.String())
+	}
+
+	return words, nil
+}
+
+var _ Strategy = new(ExecStrategy)
@@ -0,0 +1,167 @@
+package auth_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/credhub-cli/credhub/auth"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ExecStrategy", func() {
+	var (
+		runLog   *os.File
+		runCount func() int
+		command  func(output string) []string
+	)
+
+	BeforeEach(func() {
+		var err error
+		runLog, err = ioutil.TempFile("", "credential-process")
+		Expect(err).NotTo(HaveOccurred())
+
+		runCount = func() int {
+			contents, err := ioutil.ReadFile(runLog.Name())
+			Expect(err).NotTo(HaveOccurred())
+			return strings.Count(string(contents), "run")
+		}
+
+		command = func(output string) []string {
+			return []string{"sh", "-c", `echo run >> "$0"; echo "$1"`, runLog.Name(), output}
+		}
+	})
+
+	AfterEach(func() {
+		os.Remove(runLog.Name())
+	})
+
+	Context("Do()", func() {
+		It("adds the token returned by the command as the bearer token", func() {
+			var actualAuthHeader string
+
+			apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				actualAuthHeader = r.Header.Get("Authorization")
+				w.Write([]byte("success"))
+			}))
+			defer apiServer.Close()
+
+			strategy := auth.ExecStrategy{
+				Command:   command(`{"token":"some-access-token"}`),
+				ApiClient: http.DefaultClient,
+			}
+
+			request, _ := http.NewRequest("GET", apiServer.URL, nil)
+			resp, err := strategy.Do(request)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(actualAuthHeader).To(Equal("Bearer some-access-token"))
+		})
+
+		It("caches the token until it expires", func() {
+			apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			defer apiServer.Close()
+
+			expiresAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+			strategy := auth.ExecStrategy{
+				Command:   command(`{"token":"some-access-token","expires_at":"` + expiresAt + `"}`),
+				ApiClient: http.DefaultClient,
+			}
+
+			for i := 0; i < 3; i++ {
+				request, _ := http.NewRequest("GET", apiServer.URL, nil)
+				_, err := strategy.Do(request)
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			Expect(runCount()).To(Equal(1))
+		})
+
+		It("re-runs the command when the cached token has expired", func() {
+			expiresAt := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+			strategy := auth.ExecStrategy{
+				Command: command(`{"token":"some-access-token","expires_at":"` + expiresAt + `"}`),
+			}
+
+			_, err := strategy.AccessToken()
+			Expect(err).NotTo(HaveOccurred())
+			_, err = strategy.AccessToken()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(runCount()).To(Equal(2))
+		})
+
+		It("re-runs the command and retries the request when the server reports the token expired", func() {
+			var requests []string
+
+			apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				requests = append(requests, string(body))
+				if len(requests) == 1 {
+					w.WriteHeader(http.StatusUnauthorized)
+					w.Write([]byte(`{"error":"access_token_expired"}`))
+					return
+				}
+				w.Write([]byte("success"))
+			}))
+			defer apiServer.Close()
+
+			strategy := auth.ExecStrategy{
+				Command:   command(`{"token":"some-access-token"}`),
+				ApiClient: http.DefaultClient,
+			}
+
+			request, _ := http.NewRequest("PUT", apiServer.URL, strings.NewReader("some-body"))
+			resp, err := strategy.Do(request)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(requests).To(Equal([]string{"some-body", "some-body"}))
+			Expect(runCount()).To(Equal(2))
+		})
+	})
+
+	Context("AccessToken()", func() {
+		It("returns an error including stderr when the command fails", func() {
+			strategy := auth.ExecStrategy{
+				Command: []string{"sh", "-c", "echo broker unavailable >&2; exit 3"},
+			}
+
+			_, err := strategy.AccessToken()
+
+			Expect(err).To(MatchError("credential process failed: exit status 3: broker unavailable"))
+		})
+
+		It("returns an error when the command prints invalid JSON", func() {
+			strategy := auth.ExecStrategy{Command: command("not-json")}
+
+			_, err := strategy.AccessToken()
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HavePrefix("credential process returned invalid JSON"))
+		})
+
+		It("returns an error when the command does not return a token", func() {
+			strategy := auth.ExecStrategy{Command: command(`{}`)}
+
+			_, err := strategy.AccessToken()
+
+			Expect(err).To(MatchError("credential process did not return a token"))
+		})
+
+		It("returns an error when the expiry is not RFC 3339", func() {
+			strategy := auth.ExecStrategy{Command: command(`{"token":"some-access-token","expires_at":"tomorrow"}`)}
+
+			_, err := strategy.AccessToken()
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HavePrefix("credential process returned an invalid expires_at"))
+		})
+	})
+})
@@ -0,0 +1,259 @@
+// OpenID Connect client for token grants and revocation
+package oidc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// AuthMethod is a client authentication method for the token and revocation endpoints
+// See: https://openid.net/specs/openid-connect-core-1_0.html#ClientAuthentication
+type AuthMethod string
+
+const (
+	ClientSecretBasic AuthMethod = "client_secret_basic"
+	ClientSecretPost  AuthMethod = "client_secret_post"
+	PrivateKeyJWT     AuthMethod = "private_key_jwt"
+)
+
+// Client makes requests to the OpenID Connect provider identified by IssuerURL
+//
+// Endpoints are discovered from the provider's /.well-known/openid-configuration
+// document on first use.
+type Client struct {
+	IssuerURL string
+	Client    *http.Client
+
+	// AuthMethod used to authenticate the client. Defaults to ClientSecretBasic.
+	AuthMethod AuthMethod
+
+	// ClientId and ClientSecret are used to authenticate token revocation requests
+	ClientId     string
+	ClientSecret string
+
+	// PrivateKey signs client assertions when AuthMethod is PrivateKeyJWT
+	PrivateKey *PrivateKey
+
+	mu       sync.Mutex
+	metadata *Metadata
+}
+
+// Metadata captures the data returned by GET /.well-known/openid-configuration on an OpenID Connect provider
+// This fields are not exhaustive and can added to over time.
+// See: https://openid.net/specs/openid-connect-discovery-1_0.html#ProviderMetadata
+type Metadata struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	RevocationEndpoint                string   `json:"revocation_endpoint"`
+	DeviceAuthorizationEndpoint       string   `json:"device_authorization_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+}
+
+type token struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+}
+
+type responseError struct {
+	Name        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e *responseError) Error() string {
+	if e.Description == "" {
+		return e.Name
+	}
+
+	return fmt.Sprintf("%s %s", e.Name, e.Description)
+}
+
+// Metadata returns the provider metadata, fetching it from the discovery endpoint if necessary
+func (c *Client) Metadata() (*Metadata, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.metadata != nil {
+		return c.metadata, nil
+	}
+
+	issuer := strings.TrimSuffix(c.IssuerURL, "/")
+
+	request, err := http.NewRequest("GET", issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Add("Accept", "application/json")
+	response, err := c.Client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	defer io.Copy(ioutil.Discard, response.Body)
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Received HTTP %d error while fetching OpenID configuration from %s", response.StatusCode, issuer)
+	}
+
+	var md Metadata
+	if err := json.NewDecoder(response.Body).Decode(&md); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSuffix(md.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("OpenID configuration issuer %q does not match %q", md.Issuer, issuer)
+	}
+
+	if md.TokenEndpoint == "" {
+		return nil, errors.New("OpenID configuration does not contain a token endpoint")
+	}
+
+	c.metadata = &md
+
+	return c.metadata, nil
+}
+
+// ClientCredentialGrant requests a token using client_credentials grant type
+func (c *Client) ClientCredentialGrant(clientId, clientSecret string) (string, error) {
+	values := url.Values{
+		"grant_type": {"client_credentials"},
+	}
+
+	token, err := c.tokenGrantRequest(clientId, clientSecret, values)
+
+	return token.AccessToken, err
+}
+
+// PasswordGrant requests an access token and refresh token using password grant type
+func (c *Client) PasswordGrant(clientId, clientSecret, username, password string) (string, string, error) {
+	values := url.Values{
+		"grant_type": {"password"},
+		"username":   {username},
+		"password":   {password},
+	}
+
+	token, err := c.tokenGrantRequest(clientId, clientSecret, values)
+
+	return token.AccessToken, token.RefreshToken, err
+}
+
+// RefreshTokenGrant requests a new access token and refresh token using refresh_token grant type
+func (c *Client) RefreshTokenGrant(clientId, clientSecret, refreshToken string) (string, string, error) {
+	values := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	}
+
+	token, err := c.tokenGrantRequest(clientId, clientSecret, values)
+
+	return token.AccessToken, token.RefreshToken, err
+}
+
+// RevokeToken revokes the given access token at the provider's revocation endpoint
+// See: https://tools.ietf.org/html/rfc7009
+func (c *Client) RevokeToken(accessToken string) error {
+	md, err := c.Metadata()
+	if err != nil {
+		return err
+	}
+
+	if md.RevocationEndpoint == "" {
+		return errors.New("the identity provider does not support token revocation")
+	}
+
+	values := url.Values{
+		"token":           {accessToken},
+		"token_type_hint": {"access_token"},
+	}
+
+	response, err := c.postForm(md.RevocationEndpoint, c.ClientId, c.ClientSecret, values)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= http.StatusBadRequest {
+		body, err := ioutil.ReadAll(response.Body)
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("Received HTTP %d error while revoking token from auth server: %q", response.StatusCode, body)
+	}
+
+	return nil
+}
+
+func (c *Client) tokenGrantRequest(clientId, clientSecret string, values url.Values) (token, error) {
+	var t token
+
+	md, err := c.Metadata()
+	if err != nil {
+		return t, err
+	}
+
+	response, err := c.postForm(md.TokenEndpoint, clientId, clientSecret, values)
+	if err != nil {
+		return t, err
+	}
+
+	defer response.Body.Close()
+	defer io.Copy(ioutil.Discard, response.Body)
+
+	decoder := json.NewDecoder(response.Body)
+
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		err = decoder.Decode(&t)
+		return t, err
+	}
+
+	respErr := responseError{}
+
+	if err := decoder.Decode(&respErr); err != nil {
+		return t, err
+	}
+
+	return t, &respErr
+}
+
+func (c *Client) postForm(endpoint, clientId, clientSecret string, values url.Values) (*http.Response, error) {
+	switch c.AuthMethod {
+	case ClientSecretPost:
+		values.Set("client_id", clientId)
+		values.Set("client_secret", clientSecret)
+	case PrivateKeyJWT:
+		if c.PrivateKey == nil {
+			return nil, errors.New("a private key is required for private_key_jwt client authentication")
+		}
+		assertion, err := c.PrivateKey.clientAssertion(clientId, endpoint)
+		if err != nil {
+			return nil, err
+		}
+		values.Set("client_id", clientId)
+		values.Set("client_assertion_type", "urn:ietf:params:oauth:client-assertion-type:jwt-bearer")
+		values.Set("client_assertion", assertion)
+	}
+
+	request, err := http.NewRequest("POST", endpoint, bytes.NewBufferString(values.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Add("Accept", "application/json")
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	if c.AuthMethod == "" || c.AuthMethod == ClientSecretBasic {
+		request.SetBasicAuth(url.QueryEscape(clientId), url.QueryEscape(clientSecret))
+	}
+
+	return c.Client.Do(request)
+}
@@ -0,0 +1,314 @@
+package oidc_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "code.cloudfoundry.org/credhub-cli/credhub/auth/oidc"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Client", func() {
+	var (
+		idpServer   *httptest.Server
+		handler     http.HandlerFunc
+		client      *Client
+		discovery   map[string]interface{}
+		discoveries int
+	)
+
+	BeforeEach(func() {
+		discoveries = 0
+		handler = func(w http.ResponseWriter, r *http.Request) {}
+
+		idpServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/.well-known/openid-configuration" {
+				discoveries++
+				Expect(r.Method).To(Equal(http.MethodGet))
+				json.NewEncoder(w).Encode(discovery)
+				return
+			}
+			handler(w, r)
+		}))
+
+		discovery = map[string]interface{}{
+			"issuer":              idpServer.URL,
+			"token_endpoint":      idpServer.URL + "/token",
+			"revocation_endpoint": idpServer.URL + "/revoke",
+		}
+
+		client = &Client{
+			IssuerURL:    idpServer.URL,
+			Client:       http.DefaultClient,
+			ClientId:     "some-client-id",
+			ClientSecret: "some-client-secret",
+		}
+	})
+
+	AfterEach(func() {
+		idpServer.Close()
+	})
+
+	Context("Metadata()", func() {
+		It("discovers and caches the provider metadata", func() {
+			md, err := client.Metadata()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(md.TokenEndpoint).To(Equal(idpServer.URL + "/token"))
+			Expect(md.RevocationEndpoint).To(Equal(idpServer.URL + "/revoke"))
+
+			_, err = client.Metadata()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(discoveries).To(Equal(1))
+		})
+
+		It("returns an error when the issuer does not match", func() {
+			discovery["issuer"] = "https://other.example.com"
+
+			_, err := client.Metadata()
+			Expect(err).To(MatchError(fmt.Sprintf(`OpenID configuration issuer "https://other.example.com" does not match %q`, idpServer.URL)))
+		})
+
+		It("returns an error when there is no token endpoint", func() {
+			delete(discovery, "token_endpoint")
+
+			_, err := client.Metadata()
+			Expect(err).To(MatchError("OpenID configuration does not contain a token endpoint"))
+		})
+	})
+
+	Context("ClientCredentialGrant()", func() {
+		It("authenticates with client_secret_basic by default", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.Method).To(Equal(http.MethodPost))
+				Expect(r.URL.Path).To(Equal("/token"))
+				username, password, ok := r.BasicAuth()
+				Expect(ok).To(BeTrue())
+				Expect(username).To(Equal("some-client-id"))
+				Expect(password).To(Equal("some-client-secret"))
+				Expect(r.FormValue("grant_type")).To(Equal("client_credentials"))
+				Expect(r.PostForm).NotTo(HaveKey("client_secret"))
+
+				w.Write([]byte(`{"access_token":"some-access-token","token_type":"bearer"}`))
+			}
+
+			token, err := client.ClientCredentialGrant("some-client-id", "some-client-secret")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token).To(Equal("some-access-token"))
+		})
+
+		It("sends the credentials in the form with client_secret_post", func() {
+			client.AuthMethod = ClientSecretPost
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				_, _, ok := r.BasicAuth()
+				Expect(ok).To(BeFalse())
+				Expect(r.FormValue("client_id")).To(Equal("some-client-id"))
+				Expect(r.FormValue("client_secret")).To(Equal("some-client-secret"))
+
+				w.Write([]byte(`{"access_token":"some-access-token"}`))
+			}
+
+			token, err := client.ClientCredentialGrant("some-client-id", "some-client-secret")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token).To(Equal("some-access-token"))
+		})
+
+		It("returns the error from the provider", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"error":"invalid_client","error_description":"Bad credentials"}`))
+			}
+
+			_, err := client.ClientCredentialGrant("some-client-id", "some-client-secret")
+
+			Expect(err).To(MatchError("invalid_client Bad credentials"))
+		})
+	})
+
+	Context("with private_key_jwt client authentication", func() {
+		var assertionParts func(r *http.Request) (map[string]interface{}, map[string]interface{}, []byte, string)
+
+		BeforeEach(func() {
+			client.AuthMethod = PrivateKeyJWT
+			client.ClientSecret = ""
+
+			assertionParts = func(r *http.Request) (map[string]interface{}, map[string]interface{}, []byte, string) {
+				Expect(r.FormValue("client_assertion_type")).To(Equal("urn:ietf:params:oauth:client-assertion-type:jwt-bearer"))
+				parts := strings.Split(r.FormValue("client_assertion"), ".")
+				Expect(parts).To(HaveLen(3))
+
+				var header, claims map[string]interface{}
+				decoded, err := base64.RawURLEncoding.DecodeString(parts[0])
+				Expect(err).NotTo(HaveOccurred())
+				Expect(json.Unmarshal(decoded, &header)).To(Succeed())
+				decoded, err = base64.RawURLEncoding.DecodeString(parts[1])
+				Expect(err).NotTo(HaveOccurred())
+				Expect(json.Unmarshal(decoded, &claims)).To(Succeed())
+				signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+				Expect(err).NotTo(HaveOccurred())
+
+				return header, claims, signature, parts[0] + "." + parts[1]
+			}
+		})
+
+		It("signs the client assertion with an RSA key", func() {
+			key, err := rsa.GenerateKey(rand.Reader, 1024)
+			Expect(err).NotTo(HaveOccurred())
+			client.PrivateKey, err = ParsePrivateKey(string(pem.EncodeToMemory(&pem.Block{
+				Type:  "RSA PRIVATE KEY",
+				Bytes: x509.MarshalPKCS1PrivateKey(key),
+			})), "some-key-id")
+			Expect(err).NotTo(HaveOccurred())
+
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				_, _, ok := r.BasicAuth()
+				Expect(ok).To(BeFalse())
+				Expect(r.FormValue("client_id")).To(Equal("some-client-id"))
+
+				header, claims, signature, signingInput := assertionParts(r)
+				Expect(header["alg"]).To(Equal("RS256"))
+				Expect(header["kid"]).To(Equal("some-key-id"))
+				Expect(claims["iss"]).To(Equal("some-client-id"))
+				Expect(claims["sub"]).To(Equal("some-client-id"))
+				Expect(claims["aud"]).To(Equal(idpServer.URL + "/token"))
+				Expect(claims["jti"]).NotTo(BeEmpty())
+
+				digest := sha256.Sum256([]byte(signingInput))
+				Expect(rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature)).To(Succeed())
+
+				w.Write([]byte(`{"access_token":"some-access-token"}`))
+			}
+
+			token, err := client.ClientCredentialGrant("some-client-id", "")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token).To(Equal("some-access-token"))
+		})
+
+		It("signs the client assertion with an ECDSA key", func() {
+			key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			Expect(err).NotTo(HaveOccurred())
+			der, err := x509.MarshalECPrivateKey(key)
+			Expect(err).NotTo(HaveOccurred())
+			client.PrivateKey, err = ParsePrivateKey(string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})), "")
+			Expect(err).NotTo(HaveOccurred())
+
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				header, _, signature, signingInput := assertionParts(r)
+				Expect(header["alg"]).To(Equal("ES256"))
+				Expect(header).NotTo(HaveKey("kid"))
+				Expect(signature).To(HaveLen(64))
+
+				digest := sha256.Sum256([]byte(signingInput))
+				sigR, sigS := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+				Expect(ecdsa.Verify(&key.PublicKey, digest[:], sigR, sigS)).To(BeTrue())
+
+				w.Write([]byte(`{"access_token":"some-access-token"}`))
+			}
+
+			_, err = client.ClientCredentialGrant("some-client-id", "")
+
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns an error when no private key is configured", func() {
+			_, err := client.ClientCredentialGrant("some-client-id", "")
+
+			Expect(err).To(MatchError("a private key is required for private_key_jwt client authentication"))
+		})
+	})
+
+	Context("PasswordGrant()", func() {
+		It("requests an access token and refresh token", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.FormValue("grant_type")).To(Equal("password"))
+				Expect(r.FormValue("username")).To(Equal("some-username"))
+				Expect(r.FormValue("password")).To(Equal("some-password"))
+
+				w.Write([]byte(`{"access_token":"some-access-token","refresh_token":"some-refresh-token"}`))
+			}
+
+			access, refresh, err := client.PasswordGrant("some-client-id", "some-client-secret", "some-username", "some-password")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(access).To(Equal("some-access-token"))
+			Expect(refresh).To(Equal("some-refresh-token"))
+		})
+	})
+
+	Context("RefreshTokenGrant()", func() {
+		It("requests a new access token and refresh token", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.FormValue("grant_type")).To(Equal("refresh_token"))
+				Expect(r.FormValue("refresh_token")).To(Equal("some-refresh-token"))
+
+				w.Write([]byte(`{"access_token":"new-access-token","refresh_token":"new-refresh-token"}`))
+			}
+
+			access, refresh, err := client.RefreshTokenGrant("some-client-id", "some-client-secret", "some-refresh-token")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(access).To(Equal("new-access-token"))
+			Expect(refresh).To(Equal("new-refresh-token"))
+		})
+	})
+
+	Context("RevokeToken()", func() {
+		It("revokes the token at the revocation endpoint", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.Method).To(Equal(http.MethodPost))
+				Expect(r.URL.Path).To(Equal("/revoke"))
+				username, _, ok := r.BasicAuth()
+				Expect(ok).To(BeTrue())
+				Expect(username).To(Equal("some-client-id"))
+				Expect(r.FormValue("token")).To(Equal("some-access-token"))
+				Expect(r.FormValue("token_type_hint")).To(Equal("access_token"))
+			}
+
+			Expect(client.RevokeToken("some-access-token")).To(Succeed())
+		})
+
+		It("returns an error when the revocation fails", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("bad request"))
+			}
+
+			Expect(client.RevokeToken("some-access-token")).To(MatchError(`Received HTTP 400 error while revoking token from auth server: "bad request"`))
+		})
+
+		It("returns an error when the provider does not support revocation", func() {
+			delete(discovery, "revocation_endpoint")
+
+			Expect(client.RevokeToken("some-access-token")).To(MatchError("the identity provider does not support token revocation"))
+		})
+	})
+
+	Context("ParsePrivateKey()", func() {
+		It("rejects ECDSA keys on curves other than P-256", func() {
+			key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+			Expect(err).NotTo(HaveOccurred())
+			der, err := x509.MarshalECPrivateKey(key)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = ParsePrivateKey(string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})), "")
+			Expect(err).To(MatchError("only P-256 ECDSA private keys are supported"))
+		})
+	})
+})
@@ -0,0 +1,134 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"time"
+)
+
+// PrivateKey signs client assertions for private_key_jwt client authentication
+// See: https://tools.ietf.org/html/rfc7523#section-2.2
+type PrivateKey struct {
+	// KeyID is sent as the kid header of client assertions, if set
+	KeyID string
+
+	signer crypto.Signer
+}
+
+// ParsePrivateKey parses a PEM encoded RSA or ECDSA (P-256) private key
+func ParsePrivateKey(privateKeyPEM, keyID string) (*PrivateKey, error) {
+	block, _ := pem.Decode([]byte(privateKeyPEM))
+	if block == nil {
+		return nil, errors.New("private key is not PEM encoded")
+	}
+
+	var signer crypto.Signer
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer = key
+	case "EC PRIVATE KEY":
+		key, err := x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer = key
+	default:
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		var ok bool
+		if signer, ok = key.(crypto.Signer); !ok {
+			return nil, errors.New("unsupported private key type")
+		}
+	}
+
+	switch key := signer.(type) {
+	case *rsa.PrivateKey:
+	case *ecdsa.PrivateKey:
+		if key.Curve.Params().BitSize != 256 {
+			return nil, errors.New("only P-256 ECDSA private keys are supported")
+		}
+	default:
+		return nil, errors.New("unsupported private key type")
+	}
+
+	return &PrivateKey{KeyID: keyID, signer: signer}, nil
+}
+
+func (k *PrivateKey) clientAssertion(clientId, audience string) (string, error) {
+	header := map[string]string{"typ": "JWT"}
+	if k.KeyID != "" {
+		header["kid"] = k.KeyID
+	}
+
+	switch k.signer.(type) {
+	case *rsa.PrivateKey:
+		header["alg"] = "RS256"
+	case *ecdsa.PrivateKey:
+		header["alg"] = "ES256"
+	}
+
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims := map[string]interface{}{
+		"iss": clientId,
+		"sub": clientId,
+		"aud": audience,
+		"jti": base64.RawURLEncoding.EncodeToString(jti),
+		"iat": now.Unix(),
+		"exp": now.Add(5 * time.Minute).Unix(),
+	}
+
+	jsonHeader, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	jsonClaims, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(jsonHeader) + "." + base64.RawURLEncoding.EncodeToString(jsonClaims)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var signature []byte
+
+	switch key := k.signer.(type) {
+	case *rsa.PrivateKey:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	case *ecdsa.PrivateKey:
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, key, digest[:])
+		if err == nil {
+			rb, sb := r.Bytes(), s.Bytes()
+			signature = make([]byte, 64)
+			copy(signature[32-len(rb):32], rb)
+			copy(signature[64-len(sb):], sb)
+		}
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"code.cloudfoundry.org/credhub-cli/credhub/auth/uaa"
+)
+
+// ErrStaticTokenExpired is returned by StaticTokenStrategy when its token has expired
+var ErrStaticTokenExpired = errors.New("The provided bearer token has expired and cannot be refreshed. Please provide a new token and retry your request.")
+
+// StaticTokenStrategy submits requests with a pre-issued bearer token
+//
+// The token is never refreshed. If it is a JWT whose exp claim has passed, or the
+// server reports it has expired, requests fail with ErrStaticTokenExpired.
+type StaticTokenStrategy struct {
+	Token     string
+	ApiClient *http.Client
+}
+
+// Do submits requests with bearer token authorization, using the Token as the bearer token.
+func (a *StaticTokenStrategy) Do(req *http.Request) (*http.Response, error) {
+	if token, err := uaa.DecodeToken(a.Token); err == nil && token.Claims.ExpiresAt != 0 {
+		if time.Now().After(token.Claims.ExpiresAtTime()) {
+			return nil, ErrStaticTokenExpired
+		}
+	}
+
+	req.Header.Set("Authorization", "Bearer "+a.Token)
+	resp, err := a.ApiClient.Do(req)
+
+	if err != nil {
+		return resp, err
+	}
+
+	expired, err := tokenExpired(resp)
+
+	if err != nil || !expired {
+		return resp, err
+	}
+
+	resp.Body.Close()
+
+	return nil, ErrStaticTokenExpired
+}
+
+// AccessToken is the Bearer token used for authenticated requests
+func (a *StaticTokenStrategy) AccessToken() string {
+	return a.Token
+}
+
+var _ Strategy = new(StaticTokenStrategy)
@@ -0,0 +1,110 @@
+package auth_test
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"code.cloudfoundry.org/credhub-cli/credhub/auth"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func unsignedToken(expiresAt time.Time) string {
+	payload := fmt.Sprintf(`{"jti":"some-jti","exp":%d}`, expiresAt.Unix())
+	return "e30." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".c2lnbmF0dXJl"
+}
+
+var _ = Describe("StaticTokenStrategy", func() {
+	var (
+		requests int
+		handler  http.HandlerFunc
+		server   *httptest.Server
+	)
+
+	BeforeEach(func() {
+		requests = 0
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("success"))
+		}
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			handler(w, r)
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Context("Do()", func() {
+		It("adds the token to the request header", func() {
+			var actualAuthHeader string
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				actualAuthHeader = r.Header.Get("Authorization")
+			}
+
+			strategy := auth.StaticTokenStrategy{Token: "some-opaque-token", ApiClient: http.DefaultClient}
+
+			request, _ := http.NewRequest("GET", server.URL, nil)
+			resp, err := strategy.Do(request)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(actualAuthHeader).To(Equal("Bearer some-opaque-token"))
+		})
+
+		It("sends unexpired JWTs", func() {
+			strategy := auth.StaticTokenStrategy{Token: unsignedToken(time.Now().Add(time.Hour)), ApiClient: http.DefaultClient}
+
+			request, _ := http.NewRequest("GET", server.URL, nil)
+			_, err := strategy.Do(request)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(requests).To(Equal(1))
+		})
+
+		It("returns an error without sending the request when the JWT has expired", func() {
+			strategy := auth.StaticTokenStrategy{Token: unsignedToken(time.Now().Add(-time.Minute)), ApiClient: http.DefaultClient}
+
+			request, _ := http.NewRequest("GET", server.URL, nil)
+			_, err := strategy.Do(request)
+
+			Expect(err).To(Equal(auth.ErrStaticTokenExpired))
+			Expect(requests).To(Equal(0))
+		})
+
+		It("returns an error without retrying when the server reports the token has expired", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"error":"access_token_expired"}`))
+			}
+
+			strategy := auth.StaticTokenStrategy{Token: "some-opaque-token", ApiClient: http.DefaultClient}
+
+			request, _ := http.NewRequest("GET", server.URL, nil)
+			_, err := strategy.Do(request)
+
+			Expect(err).To(MatchError("The provided bearer token has expired and cannot be refreshed. Please provide a new token and retry your request."))
+			Expect(requests).To(Equal(1))
+		})
+
+		It("forwards other error responses", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte(`{"error":"forbidden"}`))
+			}
+
+			strategy := auth.StaticTokenStrategy{Token: "some-opaque-token", ApiClient: http.DefaultClient}
+
+			request, _ := http.NewRequest("GET", server.URL, nil)
+			resp, err := strategy.Do(request)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusForbidden))
+		})
+	})
+})
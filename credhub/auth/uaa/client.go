@@ -3,7 +3,6 @@ package uaa
 
 import (
 	"bytes"
-	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,7 +10,6 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
-	"strings"
 )
 
 // Client makes requests to the UAA server at AuthURL
@@ -189,23 +187,15 @@ func (u *Client) tokenGrantRequest(headers url.Values) (token, error) {
 
 // RevokeToken revokes the given access token
 func (u *Client) RevokeToken(accessToken string) error {
-	segments := strings.Split(accessToken, ".")
-
-	if len(segments) < 2 {
-		return errors.New("access token missing segments")
-	}
-
-	jsonPayload, err := base64.RawURLEncoding.DecodeString(segments[1])
+	token, err := DecodeToken(accessToken)
 
 	if err != nil {
-		return errors.New("could not base64 decode token payload")
+		return err
 	}
 
-	payload := make(map[string]interface{})
-	json.Unmarshal(jsonPayload, &payload)
-	jti, ok := payload["jti"].(string)
+	jti := token.Claims.ID
 
-	if !ok {
+	if jti == "" {
 		return errors.New("could not parse jti from payload")
 	}
 
@@ -1,8 +1,11 @@
 package uaa_test
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 
 	. "code.cloudfoundry.org/credhub-cli/credhub/auth/uaa"
 
@@ -259,6 +262,220 @@ var _ = Describe("Client", func() {
 		})
 	})
 
+	Context("AuthorizationCodeGrant()", func() {
+		It("should make an authorization code grant token request", func() {
+			uaaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.ParseForm()
+
+				Expect(r.Method).To(Equal(http.MethodPost))
+				Expect(r.URL.Path).To(Equal("/oauth/token"))
+
+				Expect(r.PostForm.Get("grant_type")).To(Equal("authorization_code"))
+				Expect(r.PostForm.Get("code")).To(Equal("some-code"))
+				Expect(r.PostForm.Get("redirect_uri")).To(Equal("http://127.0.0.1:1234/callback"))
+				Expect(r.PostForm.Get("code_verifier")).To(Equal("some-verifier"))
+				Expect(r.PostForm.Get("client_id")).To(Equal("client-id"))
+				Expect(r.PostForm.Get("client_secret")).To(Equal("client-secret"))
+
+				w.Write([]byte(`{"access_token": "some-access-token", "refresh_token": "some-refresh-token", "token_type": "bearer"}`))
+			}))
+			defer uaaServer.Close()
+
+			client := Client{
+				AuthURL: uaaServer.URL,
+				Client:  http.DefaultClient,
+			}
+
+			accessToken, refreshToken, err := client.AuthorizationCodeGrant("client-id", "client-secret", "some-code", "http://127.0.0.1:1234/callback", "some-verifier")
+
+			Expect(err).To(BeNil())
+			Expect(accessToken).To(Equal("some-access-token"))
+			Expect(refreshToken).To(Equal("some-refresh-token"))
+		})
+	})
+
+	Context("BrowserLogin()", func() {
+		var (
+			uaaServer     *httptest.Server
+			codeChallenge string
+			authorizeErr  string
+		)
+
+		BeforeEach(func() {
+			authorizeErr = ""
+			uaaServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/oauth/authorize":
+					query := r.URL.Query()
+					Expect(query.Get("response_type")).To(Equal("code"))
+					Expect(query.Get("client_id")).To(Equal("client-id"))
+					Expect(query.Get("code_challenge_method")).To(Equal("S256"))
+					codeChallenge = query.Get("code_challenge")
+
+					redirect, _ := url.Parse(query.Get("redirect_uri"))
+					Expect(redirect.Hostname()).To(Equal("127.0.0.1"))
+					params := url.Values{"state": {query.Get("state")}}
+					if authorizeErr != "" {
+						params.Set("error", authorizeErr)
+					} else {
+						params.Set("code", "some-code")
+					}
+					redirect.RawQuery = params.Encode()
+					http.Redirect(w, r, redirect.String(), http.StatusFound)
+				case "/oauth/token":
+					r.ParseForm()
+					Expect(r.PostForm.Get("grant_type")).To(Equal("authorization_code"))
+					Expect(r.PostForm.Get("code")).To(Equal("some-code"))
+
+					verifierHash := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+					Expect(base64.RawURLEncoding.EncodeToString(verifierHash[:])).To(Equal(codeChallenge))
+
+					w.Write([]byte(`{"access_token": "some-access-token", "refresh_token": "some-refresh-token", "token_type": "bearer"}`))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+		})
+
+		AfterEach(func() {
+			uaaServer.Close()
+		})
+
+		It("receives the authorization code on a loopback listener and exchanges it", func() {
+			client := Client{
+				AuthURL: uaaServer.URL,
+				Client:  http.DefaultClient,
+			}
+
+			var openedURL string
+			accessToken, refreshToken, err := client.BrowserLogin("client-id", "client-secret", func(authorizeURL string) error {
+				openedURL = authorizeURL
+				go http.Get(authorizeURL)
+				return nil
+			})
+
+			Expect(err).To(BeNil())
+			Expect(openedURL).To(HavePrefix(uaaServer.URL + "/oauth/authorize?"))
+			Expect(accessToken).To(Equal("some-access-token"))
+			Expect(refreshToken).To(Equal("some-refresh-token"))
+		})
+
+		It("returns an error when the authorization is denied", func() {
+			authorizeErr = "access_denied"
+			client := Client{
+				AuthURL: uaaServer.URL,
+				Client:  http.DefaultClient,
+			}
+
+			_, _, err := client.BrowserLogin("client-id", "client-secret", func(authorizeURL string) error {
+				go http.Get(authorizeURL)
+				return nil
+			})
+
+			Expect(err).To(MatchError("access_denied"))
+		})
+	})
+
+	Context("DeviceAuthorization()", func() {
+		It("should make a device authorization request", func() {
+			uaaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.ParseForm()
+
+				Expect(r.Method).To(Equal(http.MethodPost))
+				Expect(r.URL.Path).To(Equal("/oauth/device_authorization"))
+				Expect(r.Header.Get("Content-Type")).To(Equal("application/x-www-form-urlencoded"))
+				Expect(r.PostForm.Get("client_id")).To(Equal("client-id"))
+
+				w.Write([]byte(`{"device_code":"some-device-code","user_code":"ABCD-EFGH","verification_uri":"https://example.com/device","expires_in":600,"interval":5}`))
+			}))
+			defer uaaServer.Close()
+
+			client := Client{
+				AuthURL: uaaServer.URL,
+				Client:  http.DefaultClient,
+			}
+
+			authorization, err := client.DeviceAuthorization("client-id", "")
+
+			Expect(err).To(BeNil())
+			Expect(*authorization).To(Equal(DeviceAuthorization{
+				DeviceCode:      "some-device-code",
+				UserCode:        "ABCD-EFGH",
+				VerificationURI: "https://example.com/device",
+				ExpiresIn:       600,
+				Interval:        5,
+			}))
+		})
+
+		It("returns an error when the client is not allowed to use the grant", func() {
+			uaaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"unauthorized_client"}`))
+			}))
+			defer uaaServer.Close()
+
+			client := Client{
+				AuthURL: uaaServer.URL,
+				Client:  http.DefaultClient,
+			}
+
+			_, err := client.DeviceAuthorization("client-id", "")
+
+			Expect(err).To(MatchError("unauthorized_client"))
+		})
+	})
+
+	Context("DeviceCodeGrant()", func() {
+		It("polls the token endpoint until the authorization is approved", func() {
+			var attempts int
+			uaaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.ParseForm()
+
+				Expect(r.URL.Path).To(Equal("/oauth/token"))
+				Expect(r.PostForm.Get("grant_type")).To(Equal("urn:ietf:params:oauth:grant-type:device_code"))
+				Expect(r.PostForm.Get("device_code")).To(Equal("some-device-code"))
+
+				attempts++
+				if attempts == 1 {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte(`{"error":"authorization_pending"}`))
+					return
+				}
+				w.Write([]byte(`{"access_token": "some-access-token", "refresh_token": "some-refresh-token", "token_type": "bearer"}`))
+			}))
+			defer uaaServer.Close()
+
+			client := Client{
+				AuthURL: uaaServer.URL,
+				Client:  http.DefaultClient,
+			}
+
+			accessToken, refreshToken, err := client.DeviceCodeGrant("client-id", "", &DeviceAuthorization{DeviceCode: "some-device-code", Interval: 1})
+
+			Expect(err).To(BeNil())
+			Expect(attempts).To(Equal(2))
+			Expect(accessToken).To(Equal("some-access-token"))
+			Expect(refreshToken).To(Equal("some-refresh-token"))
+		})
+
+		It("returns an error when the authorization is denied", func() {
+			uaaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"access_denied"}`))
+			}))
+			defer uaaServer.Close()
+
+			client := Client{
+				AuthURL: uaaServer.URL,
+				Client:  http.DefaultClient,
+			}
+
+			_, _, err := client.DeviceCodeGrant("client-id", "", &DeviceAuthorization{DeviceCode: "some-device-code", Interval: 1})
+
+			Expect(err).To(MatchError("access_denied"))
+		})
+	})
+
 	Context("RevokeToken()", func() {
 		It("requests to revoke the token", func() {
 			token := "e30K.eyJqdGkiOiIxIn0K.e30K" // {}.{"jti":"1"}.{}
@@ -0,0 +1,117 @@
+package uaa
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DeviceAuthorization captures the data returned by a device authorization request
+// See: https://tools.ietf.org/html/rfc8628#section-3.2
+type DeviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+const deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// DeviceAuthorization starts a device authorization grant
+//
+// The user must visit the returned VerificationURI and enter the UserCode, after which
+// DeviceCodeGrant can exchange the DeviceCode for tokens.
+func (u *Client) DeviceAuthorization(clientId, clientSecret string) (*DeviceAuthorization, error) {
+	values := url.Values{
+		"client_id":     {clientId},
+		"client_secret": {clientSecret},
+	}
+
+	request, err := http.NewRequest("POST", u.AuthURL+"/oauth/device_authorization", bytes.NewBufferString(values.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Add("Accept", "application/json")
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	response, err := u.Client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	defer io.Copy(ioutil.Discard, response.Body)
+
+	decoder := json.NewDecoder(response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		respErr := responseError{}
+		if err := decoder.Decode(&respErr); err != nil {
+			return nil, err
+		}
+		return nil, &respErr
+	}
+
+	var authorization DeviceAuthorization
+	if err := decoder.Decode(&authorization); err != nil {
+		return nil, err
+	}
+
+	return &authorization, nil
+}
+
+// DeviceCodeGrant requests an access token and refresh token using the device_code grant type
+//
+// The token endpoint is polled at the interval given by the authorization server until the
+// user approves or denies the request, or the device code expires.
+func (u *Client) DeviceCodeGrant(clientId, clientSecret string, authorization *DeviceAuthorization) (string, string, error) {
+	values := url.Values{
+		"grant_type":    {deviceCodeGrantType},
+		"response_type": {"token"},
+		"device_code":   {authorization.DeviceCode},
+		"client_id":     {clientId},
+		"client_secret": {clientSecret},
+	}
+
+	interval := time.Duration(authorization.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	var deadline <-chan time.Time
+	if authorization.ExpiresIn > 0 {
+		deadline = time.After(time.Duration(authorization.ExpiresIn) * time.Second)
+	}
+
+	for {
+		token, err := u.tokenGrantRequest(values)
+		if err == nil {
+			return token.AccessToken, token.RefreshToken, nil
+		}
+
+		respErr, ok := err.(*responseError)
+		if !ok {
+			return "", "", err
+		}
+
+		switch respErr.Name {
+		case "authorization_pending":
+		case "slow_down":
+			interval += 5 * time.Second
+		default:
+			return "", "", err
+		}
+
+		select {
+		case <-time.After(interval):
+		case <-deadline:
+			return "", "", errors.New("the device code expired before the login was approved")
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package uaa
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// BrowserLoginTimeout is how long BrowserLogin waits for the authorization server to redirect back
+var BrowserLoginTimeout = 5 * time.Minute
+
+// AuthorizationCodeGrant requests an access token and refresh token using authorization_code grant type
+//
+// The codeVerifier is the PKCE verifier matching the code_challenge sent in the authorization request.
+func (u *Client) AuthorizationCodeGrant(clientId, clientSecret, code, redirectURI, codeVerifier string) (string, string, error) {
+	values := url.Values{
+		"grant_type":    {"authorization_code"},
+		"response_type": {"token"},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"code_verifier": {codeVerifier},
+		"client_id":     {clientId},
+		"client_secret": {clientSecret},
+	}
+
+	token, err := u.tokenGrantRequest(values)
+
+	return token.AccessToken, token.RefreshToken, err
+}
+
+// BrowserLogin requests an access token and refresh token using the authorization code flow with PKCE
+//
+// A loopback HTTP listener is started to receive the authorization code, and openBrowser
+// is called with the authorization URL the user should visit. BrowserLogin returns once
+// the code has been exchanged for tokens, or after BrowserLoginTimeout.
+func (u *Client) BrowserLogin(clientId, clientSecret string, openBrowser func(authorizeURL string) error) (string, string, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", "", err
+	}
+	defer listener.Close()
+
+	redirectURI := fmt.Sprintf("http://%s/callback", listener.Addr().String())
+
+	state, err := randomURLSafeString(16)
+	if err != nil {
+		return "", "", err
+	}
+	codeVerifier, err := randomURLSafeString(32)
+	if err != nil {
+		return "", "", err
+	}
+	challenge := sha256.Sum256([]byte(codeVerifier))
+
+	authorizeURL := u.AuthURL + "/oauth/authorize?" + url.Values{
+		"response_type":         {"code"},
+		"client_id":             {clientId},
+		"redirect_uri":          {redirectURI},
+		"state":                 {state},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}.Encode()
+
+	type callbackResult struct {
+		code string
+		err  error
+	}
+	results := make(chan callbackResult, 1)
+
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/callback" {
+			http.NotFound(w, r)
+			return
+		}
+
+		query := r.URL.Query()
+		var result callbackResult
+
+		switch {
+		case query.Get("state") != state:
+			result.err = errors.New("authorization response state does not match the request")
+		case query.Get("error") != "":
+			result.err = &responseError{Name: query.Get("error"), Description: query.Get("error_description")}
+		case query.Get("code") == "":
+			result.err = errors.New("authorization response did not contain a code")
+		default:
+			result.code = query.Get("code")
+		}
+
+		if result.err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "Login failed: %s. You may close this window.", result.err)
+		} else {
+			fmt.Fprint(w, "Login successful. You may close this window and return to the CredHub CLI.")
+		}
+
+		select {
+		case results <- result:
+		default:
+		}
+	})}
+	go server.Serve(listener)
+	defer server.Close()
+
+	if err := openBrowser(authorizeURL); err != nil {
+		return "", "", err
+	}
+
+	select {
+	case result := <-results:
+		if result.err != nil {
+			return "", "", result.err
+		}
+		return u.AuthorizationCodeGrant(clientId, clientSecret, result.code, redirectURI, codeVerifier)
+	case <-time.After(BrowserLoginTimeout):
+		return "", "", errors.New("timed out waiting for the browser login to complete")
+	}
+}
+
+func randomURLSafeString(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,220 @@
+package uaa
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Token is a decoded UAA access token
+//
+// Decoding does not verify the signature. Use Verify() with the keys returned
+// by Client.TokenKeys() to check that the token was issued by the auth server.
+type Token struct {
+	Header struct {
+		Algorithm string `json:"alg"`
+		KeyID     string `json:"kid"`
+	}
+	Claims Claims
+
+	signingInput string
+	signature    string
+}
+
+// Claims captures the claims of a UAA access token.
+// These fields are not exhaustive and can added to over time.
+type Claims struct {
+	ID          string   `json:"jti"`
+	Subject     string   `json:"sub"`
+	UserID      string   `json:"user_id"`
+	UserName    string   `json:"user_name"`
+	ClientID    string   `json:"client_id"`
+	GrantType   string   `json:"grant_type"`
+	Scopes      []string `json:"scope"`
+	Authorities []string `json:"authorities"`
+	Issuer      string   `json:"iss"`
+	ZoneID      string   `json:"zid"`
+	IssuedAt    int64    `json:"iat"`
+	ExpiresAt   int64    `json:"exp"`
+}
+
+// IssuedAtTime returns the iat claim as a time.Time
+func (c Claims) IssuedAtTime() time.Time {
+	return time.Unix(c.IssuedAt, 0).UTC()
+}
+
+// ExpiresAtTime returns the exp claim as a time.Time
+func (c Claims) ExpiresAtTime() time.Time {
+	return time.Unix(c.ExpiresAt, 0).UTC()
+}
+
+// TokenKey is a token verification key as returned by GET /token_keys on a UAA server
+// See: https://docs.cloudfoundry.org/api/uaa/version/4.6.0/index.html#token-key-s
+type TokenKey struct {
+	KeyID     string `json:"kid"`
+	KeyType   string `json:"kty"`
+	Algorithm string `json:"alg"`
+	Value     string `json:"value"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+}
+
+// DecodeToken splits and decodes the header and claims of an access token without verifying its signature
+func DecodeToken(accessToken string) (*Token, error) {
+	segments := strings.Split(accessToken, ".")
+
+	if len(segments) < 2 {
+		return nil, errors.New("access token missing segments")
+	}
+
+	t := &Token{}
+
+	jsonHeader, err := base64.RawURLEncoding.DecodeString(segments[0])
+	if err != nil {
+		return nil, errors.New("could not base64 decode token header")
+	}
+	json.Unmarshal(jsonHeader, &t.Header)
+
+	jsonPayload, err := base64.RawURLEncoding.DecodeString(segments[1])
+	if err != nil {
+		return nil, errors.New("could not base64 decode token payload")
+	}
+
+	if err := json.Unmarshal(jsonPayload, &t.Claims); err != nil {
+		return nil, errors.New("could not parse token payload")
+	}
+
+	if len(segments) > 2 {
+		t.signingInput = segments[0] + "." + segments[1]
+		t.signature = segments[2]
+	}
+
+	return t, nil
+}
+
+// Verify checks the token signature against the given keys
+//
+// If the token header names a key ID, only the key with that ID is used.
+// Only RSA signatures (RS256, RS384 and RS512) are supported.
+func (t *Token) Verify(keys []TokenKey) error {
+	var hash crypto.Hash
+
+	switch t.Header.Algorithm {
+	case "RS256":
+		hash = crypto.SHA256
+	case "RS384":
+		hash = crypto.SHA384
+	case "RS512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported token signing algorithm %q", t.Header.Algorithm)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(t.signature)
+	if err != nil || len(signature) == 0 {
+		return errors.New("could not base64 decode token signature")
+	}
+
+	hasher := hash.New()
+	hasher.Write([]byte(t.signingInput))
+	digest := hasher.Sum(nil)
+
+	var candidates int
+	for _, key := range keys {
+		if t.Header.KeyID != "" && key.KeyID != t.Header.KeyID {
+			continue
+		}
+
+		publicKey, err := key.PublicKey()
+		if err != nil {
+			continue
+		}
+		candidates++
+
+		if rsa.VerifyPKCS1v15(publicKey, hash, digest, signature) == nil {
+			return nil
+		}
+	}
+
+	if candidates == 0 {
+		return fmt.Errorf("no token key found matching key id %q", t.Header.KeyID)
+	}
+
+	return errors.New("token signature is invalid")
+}
+
+// PublicKey returns the RSA public key described by the token key
+func (k TokenKey) PublicKey() (*rsa.PublicKey, error) {
+	if k.N != "" && k.E != "" {
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	}
+
+	block, _ := pem.Decode([]byte(k.Value))
+	if block == nil {
+		return nil, errors.New("token key is not a PEM encoded public key")
+	}
+
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, ok := parsed.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("token key is not an RSA public key")
+	}
+
+	return publicKey, nil
+}
+
+// TokenKeys fetches the token verification keys of the UAA server
+func (u *Client) TokenKeys() ([]TokenKey, error) {
+	request, err := http.NewRequest("GET", u.AuthURL+"/token_keys", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Add("Accept", "application/json")
+	response, err := u.Client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	defer io.Copy(ioutil.Discard, response.Body)
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Received HTTP %d error while fetching token keys from auth server", response.StatusCode)
+	}
+
+	var body struct {
+		Keys []TokenKey `json:"keys"`
+	}
+
+	if err := json.NewDecoder(response.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	return body.Keys, nil
+}
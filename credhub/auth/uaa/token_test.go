@@ -0,0 +1,177 @@
+package uaa_test
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+
+	. "code.cloudfoundry.org/credhub-cli/credhub/auth/uaa"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func signedToken(key *rsa.PrivateKey, header, payload string) string {
+	signingInput := base64.RawURLEncoding.EncodeToString([]byte(header)) + "." + base64.RawURLEncoding.EncodeToString([]byte(payload))
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	Expect(err).NotTo(HaveOccurred())
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+var _ = Describe("Token", func() {
+	var key *rsa.PrivateKey
+
+	BeforeEach(func() {
+		var err error
+		key, err = rsa.GenerateKey(rand.Reader, 1024)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Context("DecodeToken()", func() {
+		It("decodes the header and claims", func() {
+			token := signedToken(key, `{"alg":"RS256","kid":"key-1"}`, `{
+				"jti":"some-jti",
+				"user_name":"some-user",
+				"client_id":"credhub_cli",
+				"scope":["credhub.read","credhub.write"],
+				"authorities":["uaa.none"],
+				"iss":"https://uaa.example.com/oauth/token",
+				"zid":"uaa",
+				"iat":1504821585,
+				"exp":1504907985}`)
+
+			decoded, err := DecodeToken(token)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decoded.Header.Algorithm).To(Equal("RS256"))
+			Expect(decoded.Header.KeyID).To(Equal("key-1"))
+			Expect(decoded.Claims.ID).To(Equal("some-jti"))
+			Expect(decoded.Claims.UserName).To(Equal("some-user"))
+			Expect(decoded.Claims.ClientID).To(Equal("credhub_cli"))
+			Expect(decoded.Claims.Scopes).To(ConsistOf("credhub.read", "credhub.write"))
+			Expect(decoded.Claims.Authorities).To(ConsistOf("uaa.none"))
+			Expect(decoded.Claims.Issuer).To(Equal("https://uaa.example.com/oauth/token"))
+			Expect(decoded.Claims.ZoneID).To(Equal("uaa"))
+			Expect(decoded.Claims.IssuedAtTime().Unix()).To(Equal(int64(1504821585)))
+			Expect(decoded.Claims.ExpiresAtTime().Unix()).To(Equal(int64(1504907985)))
+		})
+
+		It("returns an error when the token is malformed", func() {
+			_, err := DecodeToken("first")
+			Expect(err).To(MatchError("access token missing segments"))
+
+			_, err = DecodeToken("e30K.^second")
+			Expect(err).To(MatchError("could not base64 decode token payload"))
+		})
+	})
+
+	Context("Verify()", func() {
+		It("accepts a token signed by a key with a matching key id", func() {
+			token, err := DecodeToken(signedToken(key, `{"alg":"RS256","kid":"key-1"}`, `{"jti":"1"}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			keys := []TokenKey{{
+				KeyID: "key-1",
+				N:     base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:     base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}}
+
+			Expect(token.Verify(keys)).To(Succeed())
+		})
+
+		It("accepts PEM encoded token keys", func() {
+			token, err := DecodeToken(signedToken(key, `{"alg":"RS256"}`, `{"jti":"1"}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+			Expect(err).NotTo(HaveOccurred())
+			keys := []TokenKey{{Value: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))}}
+
+			Expect(token.Verify(keys)).To(Succeed())
+		})
+
+		It("rejects a token signed by another key", func() {
+			otherKey, err := rsa.GenerateKey(rand.Reader, 1024)
+			Expect(err).NotTo(HaveOccurred())
+
+			token, err := DecodeToken(signedToken(otherKey, `{"alg":"RS256","kid":"key-1"}`, `{"jti":"1"}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			keys := []TokenKey{{
+				KeyID: "key-1",
+				N:     base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:     base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}}
+
+			Expect(token.Verify(keys)).To(MatchError("token signature is invalid"))
+		})
+
+		It("rejects a token when no key matches the key id", func() {
+			token, err := DecodeToken(signedToken(key, `{"alg":"RS256","kid":"key-2"}`, `{"jti":"1"}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(token.Verify([]TokenKey{{KeyID: "key-1"}})).To(MatchError(`no token key found matching key id "key-2"`))
+		})
+
+		It("rejects unsupported algorithms", func() {
+			token, err := DecodeToken(`eyJhbGciOiJIUzI1NiJ9.e30K.c2lnbmF0dXJl`) // {"alg":"HS256"}.{}
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(token.Verify(nil)).To(MatchError(`unsupported token signing algorithm "HS256"`))
+		})
+	})
+
+	Context("TokenKeys()", func() {
+		It("fetches the token keys", func() {
+			uaaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.Method).To(Equal(http.MethodGet))
+				Expect(r.URL.Path).To(Equal("/token_keys"))
+				Expect(r.Header.Get("Accept")).To(Equal("application/json"))
+
+				w.Write([]byte(`{"keys":[{"kty":"RSA","kid":"key-1","alg":"RS256","value":"some-pem","n":"some-n","e":"AQAB"}]}`))
+			}))
+			defer uaaServer.Close()
+
+			client := Client{
+				AuthURL: uaaServer.URL,
+				Client:  http.DefaultClient,
+			}
+
+			keys, err := client.TokenKeys()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(keys).To(Equal([]TokenKey{{
+				KeyID:     "key-1",
+				KeyType:   "RSA",
+				Algorithm: "RS256",
+				Value:     "some-pem",
+				N:         "some-n",
+				E:         "AQAB",
+			}}))
+		})
+
+		It("returns an error when the request is not successful", func() {
+			uaaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			}))
+			defer uaaServer.Close()
+
+			client := Client{
+				AuthURL: uaaServer.URL,
+				Client:  http.DefaultClient,
+			}
+
+			_, err := client.TokenKeys()
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
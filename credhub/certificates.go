@@ -0,0 +1,28 @@
+package credhub
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials"
+)
+
+// GetAllCertificatesMetadata retrieves the name of every certificate with the
+// CA that signs it and the certificates it signs, without their values.
+func (ch *CredHub) GetAllCertificatesMetadata() ([]credentials.CertificateMetadata, error) {
+	resp, err := ch.Request(http.MethodGet, "/api/v1/certificates", nil, nil, true)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	defer io.Copy(ioutil.Discard, resp.Body)
+
+	var metadata credentials.CertificatesMetadata
+	err = json.NewDecoder(resp.Body).Decode(&metadata)
+
+	return metadata.Certificates, err
+}
@@ -0,0 +1,63 @@
+package credhub_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+
+	. "code.cloudfoundry.org/credhub-cli/credhub"
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Certificates", func() {
+	Describe("GetAllCertificatesMetadata()", func() {
+		It("requests the metadata of every certificate", func() {
+			dummyAuth := &DummyAuth{Response: &http.Response{
+				Body: ioutil.NopCloser(bytes.NewBufferString("")),
+			}}
+
+			ch, _ := New("https://example.com", Auth(dummyAuth.Builder()))
+
+			ch.GetAllCertificatesMetadata()
+			Expect(dummyAuth.Request.URL.String()).To(Equal("https://example.com/api/v1/certificates"))
+			Expect(dummyAuth.Request.Method).To(Equal(http.MethodGet))
+		})
+
+		It("returns the certificates with the CA that signs them and the certificates they sign", func() {
+			responseString := `{
+				"certificates": [
+					{"id": "some-id", "name": "/example-ca", "signed_by": "/example-ca", "signs": ["/example-cert"], "versions": []},
+					{"id": "other-id", "name": "/example-cert", "signed_by": "/example-ca", "signs": []}
+				]
+			}`
+
+			dummyAuth := &DummyAuth{Response: &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(responseString)),
+			}}
+
+			ch, _ := New("https://example.com", Auth(dummyAuth.Builder()))
+
+			metadata, err := ch.GetAllCertificatesMetadata()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(metadata).To(Equal([]credentials.CertificateMetadata{
+				{Id: "some-id", Name: "/example-ca", SignedBy: "/example-ca", Signs: []string{"/example-cert"}},
+				{Id: "other-id", Name: "/example-cert", SignedBy: "/example-ca", Signs: []string{}},
+			}))
+		})
+
+		It("returns an error when the response cannot be unmarshalled", func() {
+			dummyAuth := &DummyAuth{Response: &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewBufferString("something-invalid")),
+			}}
+
+			ch, _ := New("https://example.com", Auth(dummyAuth.Builder()))
+
+			_, err := ch.GetAllCertificatesMetadata()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
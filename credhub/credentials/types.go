@@ -80,6 +80,18 @@ type BulkRegenerateResults struct {
 	Certificates []string `json:"regenerated_credentials" yaml:"regenerated_credentials"`
 }
 
+// Types needed for Get All Certificates functionality
+type CertificateMetadata struct {
+	Id       string   `json:"id" yaml:"id"`
+	Name     string   `json:"name" yaml:"name"`
+	SignedBy string   `json:"signed_by" yaml:"signed_by"`
+	Signs    []string `json:"signs" yaml:"signs"`
+}
+
+type CertificatesMetadata struct {
+	Certificates []CertificateMetadata `json:"certificates" yaml:"certificates"`
+}
+
 // Types needed for Find functionality
 type FindResults struct {
 	Credentials []Base `json:"credentials" yaml:"credentials"`
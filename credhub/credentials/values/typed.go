@@ -0,0 +1,49 @@
+package values
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Typed converts a value as returned by the server, e.g. the Value of a credentials.Credential,
+// to the value type used to set a credential of credType. Fields that are computed by the
+// server, such as password hashes and public key fingerprints, are dropped.
+//
+// Values of unknown types are returned unchanged.
+func Typed(credType string, value interface{}) (interface{}, error) {
+	var typed interface{}
+
+	switch credType {
+	case "value":
+		typed = new(Value)
+	case "json":
+		typed = new(JSON)
+	case "password":
+		typed = new(Password)
+	case "user":
+		typed = new(User)
+	case "certificate":
+		typed = new(Certificate)
+	case "rsa":
+		typed = new(RSA)
+	case "ssh":
+		typed = new(SSH)
+	default:
+		return value, nil
+	}
+
+	buf, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(buf, typed); err != nil {
+		return nil, fmt.Errorf("value is not a valid %s value: %s", credType, err)
+	}
+
+	if certificate, ok := typed.(*Certificate); ok && certificate.CaName != "" {
+		certificate.Ca = ""
+	}
+
+	return typed, nil
+}
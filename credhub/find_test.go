@@ -162,4 +162,78 @@ var _ = Describe("Find", func() {
 		})
 	})
 
+	Describe("FindAllPaths()", func() {
+		It("requests all paths", func() {
+			dummy := &DummyAuth{Response: &http.Response{
+				Body: ioutil.NopCloser(bytes.NewBufferString("")),
+			}}
+
+			ch, _ := New("https://example.com", Auth(dummy.Builder()))
+
+			ch.FindAllPaths()
+			url := dummy.Request.URL
+			Expect(url.String()).To(Equal("https://example.com/api/v1/data?paths=true"))
+			Expect(dummy.Request.Method).To(Equal(http.MethodGet))
+		})
+
+		Context("when successful", func() {
+			It("returns a list of all paths which contain credentials", func() {
+				expectedResponse := `{
+  "paths": [
+    {
+      "path": "/"
+    },
+    {
+      "path": "/deploy123/"
+    },
+    {
+      "path": "/deploy123/child/"
+    }
+  ]
+}`
+				dummy := &DummyAuth{Response: &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(expectedResponse)),
+				}}
+
+				ch, _ := New("https://example.com", Auth(dummy.Builder()))
+
+				paths, err := ch.FindAllPaths()
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(paths.Paths).To(HaveLen(3))
+				Expect(paths.Paths[0].Path).To(Equal("/"))
+				Expect(paths.Paths[1].Path).To(Equal("/deploy123/"))
+				Expect(paths.Paths[2].Path).To(Equal("/deploy123/child/"))
+			})
+		})
+
+		Context("when request fails", func() {
+			It("returns an error", func() {
+				dummy := &DummyAuth{Error: errors.New("Network error occurred")}
+
+				ch, _ := New("https://example.com", Auth(dummy.Builder()))
+
+				_, err := ch.FindAllPaths()
+
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Network error occurred"))
+			})
+		})
+
+		Context("when response body cannot be unmarshalled", func() {
+			It("returns an error", func() {
+				dummy := &DummyAuth{Response: &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       ioutil.NopCloser(bytes.NewBufferString("something-invalid")),
+				}}
+
+				ch, _ := New("https://example.com", Auth(dummy.Builder()))
+
+				_, err := ch.FindAllPaths()
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
 })
@@ -0,0 +1,29 @@
+package credhub
+
+import (
+	"fmt"
+	"strings"
+
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials"
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials/values"
+)
+
+// Rollback sets a credential to the value of one of its previous versions, identified by versionId.
+// A new version is created with the same type and value as the previous version.
+func (ch *CredHub) Rollback(name, versionId string) (credentials.Credential, error) {
+	previous, err := ch.GetById(versionId)
+	if err != nil {
+		return credentials.Credential{}, err
+	}
+
+	if strings.TrimPrefix(previous.Name, "/") != strings.TrimPrefix(name, "/") {
+		return credentials.Credential{}, fmt.Errorf("version %s does not belong to credential %s", versionId, name)
+	}
+
+	value, err := values.Typed(previous.Type, previous.Value)
+	if err != nil {
+		return credentials.Credential{}, err
+	}
+
+	return ch.SetCredential(name, previous.Type, value)
+}
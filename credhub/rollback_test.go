@@ -0,0 +1,116 @@
+package credhub_test
+
+import (
+	"net/http"
+
+	. "code.cloudfoundry.org/credhub-cli/credhub"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Rollback", func() {
+	var server *ghttp.Server
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("sets the credential to the typed value of the previous version", func() {
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/api/v1/data/some-old-id"),
+				ghttp.RespondWith(http.StatusOK, `{
+					"id": "some-old-id",
+					"name": "/example-ssh",
+					"type": "ssh",
+					"value": {"public_key": "some-public-key", "private_key": "some-private-key", "public_key_fingerprint": "some-fingerprint"},
+					"version_created_at": "2017-01-01T01:01:01Z"
+				}`),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("PUT", "/api/v1/data"),
+				ghttp.VerifyJSON(`{
+					"name": "/example-ssh",
+					"type": "ssh",
+					"value": {"public_key": "some-public-key", "private_key": "some-private-key"}
+				}`),
+				ghttp.RespondWith(http.StatusOK, `{
+					"id": "some-new-id",
+					"name": "/example-ssh",
+					"type": "ssh",
+					"value": {"public_key": "some-public-key", "private_key": "some-private-key", "public_key_fingerprint": "some-fingerprint"},
+					"version_created_at": "2017-01-05T01:01:01Z"
+				}`),
+			),
+		)
+
+		ch, _ := New(server.URL())
+
+		cred, err := ch.Rollback("/example-ssh", "some-old-id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cred.Id).To(Equal("some-new-id"))
+		Expect(cred.Type).To(Equal("ssh"))
+	})
+
+	It("sets certificates that reference a CA by name without the CA certificate", func() {
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/api/v1/data/some-old-id"),
+				ghttp.RespondWith(http.StatusOK, `{
+					"id": "some-old-id",
+					"name": "/example-certificate",
+					"type": "certificate",
+					"value": {"ca": "some-ca", "ca_name": "/example-ca", "certificate": "some-certificate", "private_key": "some-private-key"},
+					"version_created_at": "2017-01-01T01:01:01Z"
+				}`),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("PUT", "/api/v1/data"),
+				ghttp.VerifyJSON(`{
+					"name": "/example-certificate",
+					"type": "certificate",
+					"value": {"ca": "", "ca_name": "/example-ca", "certificate": "some-certificate", "private_key": "some-private-key"}
+				}`),
+				ghttp.RespondWith(http.StatusOK, `{"id": "some-new-id", "name": "/example-certificate", "type": "certificate"}`),
+			),
+		)
+
+		ch, _ := New(server.URL())
+
+		cred, err := ch.Rollback("/example-certificate", "some-old-id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cred.Id).To(Equal("some-new-id"))
+	})
+
+	It("returns an error when the version belongs to another credential", func() {
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/api/v1/data/some-old-id"),
+				ghttp.RespondWith(http.StatusOK, `{"id": "some-old-id", "name": "/other", "type": "password", "value": "some-password"}`),
+			),
+		)
+
+		ch, _ := New(server.URL())
+
+		_, err := ch.Rollback("/example-password", "some-old-id")
+		Expect(err).To(MatchError("version some-old-id does not belong to credential /example-password"))
+		Expect(server.ReceivedRequests()).To(HaveLen(1))
+	})
+
+	It("returns an error when the version cannot be retrieved", func() {
+		server.AppendHandlers(
+			ghttp.RespondWith(http.StatusNotFound, `{"error": "The request could not be completed because the credential does not exist or you do not have sufficient authorization."}`),
+		)
+
+		ch, _ := New(server.URL())
+
+		_, err := ch.Rollback("/example-password", "some-old-id")
+		Expect(err).To(MatchError("The request could not be completed because the credential does not exist or you do not have sufficient authorization."))
+	})
+})
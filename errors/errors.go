@@ -3,6 +3,7 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
 func NewNetworkError(e error) error {
@@ -29,8 +30,12 @@ func NewMissingGetParametersError() error {
 	return errors.New("A name or ID must be provided. Please update and retry your request.")
 }
 
+func NewHistoryParametersError() error {
+	return errors.New("Either a name or a path must be provided. Please update and retry your request.")
+}
+
 func NewMixedAuthorizationParametersError() error {
-	return errors.New("Client, password, SSO and/or SSO passcode credentials may not be combined. Please update and retry your request with a single login method.")
+	return errors.New("Client, password, SSO, SSO passcode, browser, device, client certificate and/or credential process login methods may not be combined. Please update and retry your request with a single login method.")
 }
 
 func NewPasswordAuthorizationParametersError() error {
@@ -41,6 +46,26 @@ func NewClientAuthorizationParametersError() error {
 	return errors.New("Both client name and client secret must be provided to authenticate. Please update and retry your request.")
 }
 
+func NewClientCertificateParametersError() error {
+	return errors.New("Both client certificate and client key must be provided to authenticate with mutual TLS. Please update and retry your request.")
+}
+
+func NewCredentialProcessError(err error) error {
+	return errors.New("Unable to get a token from the credential process: " + err.Error())
+}
+
+func NewInvalidOutputFormatError(format string) error {
+	return errors.New(fmt.Sprintf("The output format %q is not supported. Valid formats are 'yaml', 'json', 'jsonl', 'table', 'template=<template>' and 'jsonpath=<expression>'.", format))
+}
+
+func NewInvalidOutputTemplateError(err error) error {
+	return errors.New("The output template could not be used: " + err.Error())
+}
+
+func NewJSONPathNoMatchError(expression string) error {
+	return errors.New(fmt.Sprintf("The JSONPath expression %q did not match any values.", expression))
+}
+
 func NewRefreshError() error {
 	return errors.New("You are not currently authenticated. Please log in to continue.")
 }
@@ -65,12 +90,128 @@ func NewInvalidImportYamlError() error {
 	return errors.New("The referenced file does not contain valid yaml structure. Please update and retry your request.")
 }
 
+func NewVarsStoreEmptyValueError(name string) error {
+	return errors.New(fmt.Sprintf("The variable %q in the vars-store file has no value. Please update and retry your request.", name))
+}
+
 func NewNoCredentialsTag() error {
 	return errors.New("The referenced import file does not begin with the key 'credentials'. The import file must contain a list of credentials under the key 'credentials'. Please update and retry your request.")
 }
 
-func NewGetVersionAndKeyError() error {
-	return errors.New("The --version flag and --key flag are incompatible")
+func NewInvalidKeySelectorError(err error) error {
+	return errors.New("The --key selector could not be used: " + err.Error())
+}
+
+func NewKeyNotFoundError(key string) error {
+	return errors.New(fmt.Sprintf("The key %q was not found in the credential value.", key))
+}
+
+func NewDiffSingleVersionError(name string) error {
+	return errors.New(fmt.Sprintf("The credential %q has only one version. At least two versions are required to show a diff.", name))
+}
+
+func NewDiffNoEarlierVersionError(id string) error {
+	return errors.New(fmt.Sprintf("The version %q is the earliest version of the credential. Please provide a version to compare from with --from.", id))
+}
+
+func NewVersionNameMismatchError(id, name string) error {
+	return errors.New(fmt.Sprintf("The version %q does not belong to the credential %q.", id, name))
+}
+
+func NewRollbackParametersError() error {
+	return errors.New("Either a version ID (--to) or a number of steps (--steps) must be provided. Please update and retry your request.")
+}
+
+func NewRollbackTooManyStepsError(name string, versions int) error {
+	return errors.New(fmt.Sprintf("The credential %q does not have enough versions to roll back. Only %d version(s) exist.", name, versions))
+}
+
+func NewRollbackCancelledError() error {
+	return errors.New("The rollback was cancelled.")
+}
+
+func NewInvalidModeError(mode string) error {
+	return errors.New(fmt.Sprintf("The mode %q is not supported. Valid modes are 'overwrite', 'no-overwrite' and 'converge'.", mode))
+}
+
+func NewMissingDeleteParametersError() error {
+	return errors.New("A name or path must be provided. Please update and retry your request.")
+}
+
+func NewRecursiveDeleteParametersError() error {
+	return errors.New("Deleting by path requires both --path and --recursive, and cannot be combined with --name. Please update and retry your request.")
+}
+
+func NewDeleteCancelledError() error {
+	return errors.New("The path was not confirmed. No credentials were deleted.")
+}
+
+func NewDeleteFailuresError(failed int) error {
+	return errors.New(fmt.Sprintf("%d credential(s) could not be deleted.", failed))
+}
+
+func NewInvalidGlobError(pattern string) error {
+	return errors.New(fmt.Sprintf("The glob %q is not valid. Please update and retry your request.", pattern))
+}
+
+func NewBackupError(err error) error {
+	return errors.New("The backup could not be written: " + err.Error() + ". No credentials were deleted.")
+}
+
+func NewBackupPassphraseMismatchError() error {
+	return errors.New("The backup passphrases do not match. No credentials were deleted. Please retry your request.")
+}
+
+func NewBackupDecryptError(err error) error {
+	return errors.New("The encrypted backup could not be read: " + err.Error())
+}
+
+func NewSyncTargetError(target string, err error) error {
+	return errors.New(fmt.Sprintf("The sync target %q could not be used: %s", target, err))
+}
+
+func NewInvalidSyncModeError(mode string) error {
+	return errors.New(fmt.Sprintf("The mode %q is not supported. Valid modes are 'create-only' and 'overwrite'.", mode))
+}
+
+func NewSyncFailuresError(failed int) error {
+	return errors.New(fmt.Sprintf("%d credential(s) could not be synced.", failed))
+}
+
+func NewInvalidDesiredStateError(reason string) error {
+	return errors.New("The desired state file is not valid: " + reason + ". Please update and retry your request.")
+}
+
+func NewApplyCancelledError() error {
+	return errors.New("The changes were not applied.")
+}
+
+func NewInvalidFileFormatError(format string) error {
+	return errors.New(fmt.Sprintf("The format %q is not supported. Valid formats are 'credhub' and 'vars-store'.", format))
+}
+
+func NewInvalidManifestError(reason string) error {
+	return errors.New("The manifest is not valid: " + reason + ". Please update and retry your request.")
+}
+
+func NewGenerateVarsFailuresError(failed int) error {
+	return errors.New(fmt.Sprintf("%d variable(s) could not be generated.", failed))
+}
+
+func NewInvalidPolicyError(reason string) error {
+	return errors.New("The policy file is not valid: " + reason + ". Please update and retry your request.")
+}
+
+func NewPolicyViolationsError(violations []string) error {
+	return errors.New("The request does not comply with the policy. Nothing was written.\n" + strings.Join(violations, "\n"))
+}
+
+func NewLintFailedError(failed int) error {
+	return errors.New(fmt.Sprintf("%d policy violation(s) with the error severity were found.", failed))
+}
+
+func NewDuplicatesFetchFailuresError(failed int) error {
+	return errors.New(fmt.Sprintf("%d credential(s) could not be fetched and were not compared.", failed))
 }
 
 func NewUserNameOnlyValidForUserType() error {
@@ -80,3 +221,35 @@ func NewUserNameOnlyValidForUserType() error {
 func NewUAAError(err error) error {
 	return errors.New("UAA error: " + err.Error())
 }
+
+func NewInvalidTokenError(err error) error {
+	return errors.New("The current authentication token could not be decoded: " + err.Error() + ". Please log in to continue.")
+}
+
+func NewTokenFileError(path string, err error) error {
+	return errors.New(fmt.Sprintf("The token file %q set in CREDHUB_TOKEN_FILE could not be read: %s. Please update and retry your request.", path, err))
+}
+
+func NewTokenNotRefreshableError() error {
+	return errors.New("The configured authentication method does not use a token that can be refreshed. Please log in with a client ID and secret or a username and password to use a token.")
+}
+
+func NewTokenVerificationError(err error) error {
+	return errors.New("The current authentication token could not be verified: " + err.Error())
+}
+
+func NewInvalidAgeError(age string) error {
+	return errors.New(fmt.Sprintf("The age %q is not valid. Please provide a number of days such as '90d', weeks such as '12w' or a duration such as '36h'.", age))
+}
+
+func NewInvalidRotateTypeError(credType string) error {
+	return errors.New(fmt.Sprintf("The type %q cannot be rotated. Valid types are 'password', 'user', 'ssh', 'rsa' and 'certificate'.", credType))
+}
+
+func NewRotateFailuresError(failed int) error {
+	return errors.New(fmt.Sprintf("%d credential(s) could not be rotated.", failed))
+}
+
+func NewCertificateNotFoundError(name string) error {
+	return errors.New(fmt.Sprintf("The certificate %q was not found. Please update and retry your request.", name))
+}
@@ -18,6 +18,12 @@ type NeedsClient interface {
 type NeedsConfig interface {
 	SetConfig(config.Config)
 }
+type NeedsCurrentPath interface {
+	SetCurrentPath(string)
+}
+type ResolvesNames interface {
+	ResolveNames()
+}
 
 func main() {
 	debug.SetTraceback("all")
@@ -38,19 +44,27 @@ func main() {
 			if err := config.ValidateConfig(cfg); err != nil {
 				return err
 			}
-			clientId := cfg.ClientID
-			clientSecret := cfg.ClientSecret
-			useClientCredentials := true
-			if clientId == "" {
-				clientId = config.AuthClient
-				clientSecret = config.AuthPassword
-				useClientCredentials = false
-			}
-			client, err := credhub.New(cfg.ApiURL,
+			options := []credhub.Option{
 				credhub.AuthURL(cfg.AuthURL),
 				credhub.CaCerts(cfg.CaCerts...),
 				credhub.SkipTLSValidation(cfg.InsecureSkipVerify),
-				credhub.Auth(auth.Uaa(
+			}
+			if cfg.BearerToken != "" {
+				options = append(options, credhub.Auth(auth.StaticToken(cfg.BearerToken)))
+			} else if cfg.UsesClientCertificate() {
+				options = append(options, credhub.ClientCert(cfg.ClientCertPath, cfg.ClientKeyPath))
+			} else if cfg.CredentialProcess != "" {
+				options = append(options, credhub.Auth(auth.CredentialProcess(cfg.CredentialProcess)))
+			} else {
+				clientId := cfg.ClientID
+				clientSecret := cfg.ClientSecret
+				useClientCredentials := true
+				if clientId == "" {
+					clientId = config.AuthClient
+					clientSecret = config.AuthPassword
+					useClientCredentials = false
+				}
+				options = append(options, credhub.Auth(auth.Uaa(
 					clientId,
 					clientSecret,
 					"",
@@ -59,11 +73,21 @@ func main() {
 					cfg.RefreshToken,
 					useClientCredentials,
 				)))
+			}
+			client, err := credhub.New(cfg.ApiURL, options...)
 			if err != nil {
 				return err
 			}
 			cmd.SetClient(client)
 		}
+
+		if cmd, ok := command.(NeedsCurrentPath); ok {
+			cmd.SetCurrentPath(config.ReadConfig().CurrentPath)
+		}
+
+		if cmd, ok := command.(ResolvesNames); ok {
+			cmd.ResolveNames()
+		}
 		return command.Execute(args)
 	}
 
@@ -0,0 +1,221 @@
+package models
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"regexp"
+	"strings"
+
+	"code.cloudfoundry.org/credhub-cli/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// BoshManifest is the part of a BOSH deployment manifest that declares the
+// variables the director generates in CredHub
+//
+// Variable names and the ca option are relative to the prefix the director
+// stores the deployment's credentials under, e.g. /director/deployment,
+// unless they start with a /.
+type BoshManifest struct {
+	Variables []BoshVariable `yaml:"variables"`
+}
+
+type BoshVariable struct {
+	Name    string                 `yaml:"name"`
+	Type    string                 `yaml:"type"`
+	Options map[string]interface{} `yaml:"options"`
+}
+
+var boshVariableTypes = []string{"password", "certificate", "ssh", "rsa", "user"}
+
+func (m *BoshManifest) ReadFile(filepath string) error {
+	data, err := ioutil.ReadFile(filepath)
+	if err != nil {
+		return err
+	}
+
+	return m.ReadBytes(data)
+}
+
+func (m *BoshManifest) ReadBytes(data []byte) error {
+	if err := yaml.Unmarshal(data, m); err != nil {
+		return errors.NewInvalidManifestError(err.Error())
+	}
+
+	seen := map[string]bool{}
+	for i := range m.Variables {
+		variable := &m.Variables[i]
+
+		if variable.Name == "" {
+			return errors.NewInvalidManifestError(fmt.Sprintf("the variable at index %d has no name", i))
+		}
+		if seen[variable.Name] {
+			return errors.NewInvalidManifestError(fmt.Sprintf("the variable %q is declared more than once", variable.Name))
+		}
+		seen[variable.Name] = true
+
+		variable.Type = strings.ToLower(variable.Type)
+		if !isBoshVariableType(variable.Type) {
+			return errors.NewInvalidManifestError(fmt.Sprintf("the variable %q has the type %q. Valid types are 'password', 'certificate', 'ssh', 'rsa' and 'user'", variable.Name, variable.Type))
+		}
+
+		for key, value := range variable.Options {
+			variable.Options[key] = unpackAnyType(value)
+		}
+
+		if _, err := parseGenerationParameters(variable.Options); err != nil {
+			return errors.NewInvalidManifestError(fmt.Sprintf("the options of the variable %q are not valid: %s", variable.Name, err))
+		}
+	}
+
+	return nil
+}
+
+func isBoshVariableType(credType string) bool {
+	for _, t := range boshVariableTypes {
+		if credType == t {
+			return true
+		}
+	}
+	return false
+}
+
+// CredentialName returns the name of the credential a variable is stored as under the prefix
+func CredentialName(prefix, name string) string {
+	if strings.HasPrefix(name, "/") {
+		return name
+	}
+	return path.Join("/", prefix, name)
+}
+
+// Ordered returns the variables in the order they can be generated in, with
+// each CA before the certificates it signs and otherwise in manifest order.
+// An error is returned if CAs sign each other in a cycle.
+func (m BoshManifest) Ordered() ([]BoshVariable, error) {
+	byName := map[string]BoshVariable{}
+	for _, variable := range m.Variables {
+		byName[variable.Name] = variable
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	ordered := []BoshVariable{}
+
+	var visit func(variable BoshVariable) error
+	visit = func(variable BoshVariable) error {
+		switch state[variable.Name] {
+		case visited:
+			return nil
+		case visiting:
+			return errors.NewInvalidManifestError(fmt.Sprintf("the variable %q is signed by a CA that it signs", variable.Name))
+		}
+
+		state[variable.Name] = visiting
+		if ca, ok := byName[variable.ca()]; ok && ca.Name != variable.Name {
+			if err := visit(ca); err != nil {
+				return err
+			}
+		}
+		state[variable.Name] = visited
+
+		ordered = append(ordered, variable)
+		return nil
+	}
+
+	for _, variable := range m.Variables {
+		if err := visit(variable); err != nil {
+			return nil, err
+		}
+	}
+
+	return ordered, nil
+}
+
+func (v BoshVariable) ca() string {
+	ca, _ := v.Options["ca"].(string)
+	return ca
+}
+
+// GenerationParameters returns the parameters to generate the variable with
+// in the form used by GenerateCredential, with the ca option made absolute
+func (v BoshVariable) GenerationParameters(prefix string) (interface{}, error) {
+	parameters, err := parseGenerationParameters(v.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	if parameters.Ca != "" {
+		parameters.Ca = CredentialName(prefix, parameters.Ca)
+	}
+
+	return generationRequest(v.Type, parameters), nil
+}
+
+// BoshReferences are the names of the variables used by BOSH manifests and
+// runtime configs, relative to the prefix of the deployment unless they start with a /
+type BoshReferences struct {
+	// Referenced are the variables interpolated with ((name)) or ((name.field))
+	Referenced []string
+	// Declared are the variables in the variables block and the CAs their options refer to
+	Declared []string
+}
+
+var boshReferencePattern = regexp.MustCompile(`\(\((!?[-/\.\w\pL]+)\)\)`)
+
+// ReadBoshReferences returns the variables used by a manifest or runtime config.
+// Unlike ReadBytes, variables of any type are accepted.
+func ReadBoshReferences(data []byte) (BoshReferences, error) {
+	var (
+		references BoshReferences
+		document   interface{}
+		manifest   struct {
+			Variables []struct {
+				Name    string                 `yaml:"name"`
+				Options map[string]interface{} `yaml:"options"`
+			} `yaml:"variables"`
+		}
+	)
+
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return references, errors.NewInvalidManifestError(err.Error())
+	}
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return references, errors.NewInvalidManifestError(err.Error())
+	}
+
+	collectBoshReferences(unpackAnyType(document), &references.Referenced)
+
+	for _, variable := range manifest.Variables {
+		if variable.Name != "" {
+			references.Declared = append(references.Declared, variable.Name)
+		}
+		if ca, ok := variable.Options["ca"].(string); ok && ca != "" {
+			references.Declared = append(references.Declared, ca)
+		}
+	}
+
+	return references, nil
+}
+
+func collectBoshReferences(value interface{}, references *[]string) {
+	switch typedValue := value.(type) {
+	case string:
+		for _, match := range boshReferencePattern.FindAllStringSubmatch(typedValue, -1) {
+			name := strings.TrimPrefix(match[1], "!")
+			*references = append(*references, strings.SplitN(name, ".", 2)[0])
+		}
+	case map[string]interface{}:
+		for key, v := range typedValue {
+			collectBoshReferences(key, references)
+			collectBoshReferences(v, references)
+		}
+	case []interface{}:
+		for _, v := range typedValue {
+			collectBoshReferences(v, references)
+		}
+	}
+}
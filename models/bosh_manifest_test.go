@@ -0,0 +1,150 @@
+package models_test
+
+import (
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials/generate"
+	"code.cloudfoundry.org/credhub-cli/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BoshManifest", func() {
+	names := func(variables []models.BoshVariable) []string {
+		var result []string
+		for _, variable := range variables {
+			result = append(result, variable.Name)
+		}
+		return result
+	}
+
+	Describe("ReadBytes()", func() {
+		It("parses the variables and ignores the rest of the manifest", func() {
+			var manifest models.BoshManifest
+			err := manifest.ReadBytes([]byte(`
+name: deployment
+instance_groups:
+- name: web
+  properties:
+    password: ((admin_password))
+variables:
+- name: admin_password
+  type: Password
+- name: admin
+  type: user
+  options:
+    username: admin
+- name: tls
+  type: certificate
+  update_mode: converge
+  options:
+    ca: /shared/ca
+    common_name: web.example.com
+    alternative_names: [web.example.com, 10.0.0.1]
+    extended_key_usage: [server_auth]
+`))
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names(manifest.Variables)).To(Equal([]string{"admin_password", "admin", "tls"}))
+			Expect(manifest.Variables[0].Type).To(Equal("password"))
+
+			Expect(manifest.Variables[1].GenerationParameters("/director/deployment")).To(Equal(generate.User{Username: "admin"}))
+			Expect(manifest.Variables[2].GenerationParameters("/director/deployment")).To(Equal(models.GenerationParameters{
+				Ca:               "/shared/ca",
+				CommonName:       "web.example.com",
+				AlternativeName:  []string{"web.example.com", "10.0.0.1"},
+				ExtendedKeyUsage: []string{"server_auth"},
+			}))
+		})
+
+		DescribeTable("returns an error for invalid variables",
+			func(yaml, reason string) {
+				var manifest models.BoshManifest
+				err := manifest.ReadBytes([]byte(yaml))
+
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(reason))
+			},
+			Entry("no name", "variables:\n- type: password", "the variable at index 0 has no name"),
+			Entry("duplicate names", "variables:\n- {name: a, type: password}\n- {name: a, type: ssh}", `the variable "a" is declared more than once`),
+			Entry("an unsupported type", "variables:\n- {name: a, type: json}", `the variable "a" has the type "json"`),
+			Entry("unknown options", "variables:\n- {name: a, type: certificate, options: {commonname: a}}", `the options of the variable "a" are not valid`),
+		)
+	})
+
+	Describe("Ordered()", func() {
+		It("orders CAs before the certificates they sign", func() {
+			var manifest models.BoshManifest
+			Expect(manifest.ReadBytes([]byte(`
+variables:
+- {name: leaf, type: certificate, options: {ca: intermediate, common_name: leaf}}
+- {name: password, type: password}
+- {name: intermediate, type: certificate, options: {ca: root, is_ca: true, common_name: intermediate}}
+- {name: root, type: certificate, options: {is_ca: true, common_name: root}}
+- {name: external, type: certificate, options: {ca: /elsewhere/ca, common_name: external}}
+`))).To(Succeed())
+
+			ordered, err := manifest.Ordered()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names(ordered)).To(Equal([]string{"root", "intermediate", "leaf", "password", "external"}))
+		})
+
+		It("returns an error when CAs sign each other", func() {
+			var manifest models.BoshManifest
+			Expect(manifest.ReadBytes([]byte(`
+variables:
+- {name: a, type: certificate, options: {ca: b, is_ca: true}}
+- {name: b, type: certificate, options: {ca: a, is_ca: true}}
+`))).To(Succeed())
+
+			_, err := manifest.Ordered()
+
+			Expect(err).To(MatchError(`The manifest is not valid: the variable "a" is signed by a CA that it signs. Please update and retry your request.`))
+		})
+	})
+
+	Describe("ReadBoshReferences()", func() {
+		It("returns the interpolated and declared variables", func() {
+			references, err := models.ReadBoshReferences([]byte(`
+# ((commented))
+instance_groups:
+- name: web
+  properties:
+    url: https://((admin.username)):((!admin.password))@((host))
+    tls: ((/shared/tls))
+variables:
+- name: admin
+  type: user
+- name: tls
+  type: certificate
+  options:
+    ca: ca
+    common_name: ((host))
+`))
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(references.Referenced).To(ConsistOf("admin", "admin", "host", "/shared/tls", "host"))
+			Expect(references.Declared).To(Equal([]string{"admin", "tls", "ca"}))
+		})
+
+		It("accepts variables of any type", func() {
+			references, err := models.ReadBoshReferences([]byte("variables:\n- name: a\n  type: custom\n"))
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(references.Declared).To(Equal([]string{"a"}))
+		})
+	})
+
+	Describe("CredentialName()", func() {
+		It("stores relative names under the prefix", func() {
+			Expect(models.CredentialName("/director/deployment/", "password")).To(Equal("/director/deployment/password"))
+			Expect(models.CredentialName("/director/deployment", "/shared/ca")).To(Equal("/shared/ca"))
+		})
+
+		It("stores relative names directly under / for an empty or root prefix", func() {
+			Expect(models.CredentialName("", "password")).To(Equal("/password"))
+			Expect(models.CredentialName("/", "password")).To(Equal("/password"))
+		})
+	})
+})
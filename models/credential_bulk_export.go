@@ -6,13 +6,13 @@ import (
 )
 
 type exportCredential struct {
-	Name  string
-	Type  string
-	Value interface{}
+	Name  string      `json:"name"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
 }
 
 type exportCredentials struct {
-	Credentials []exportCredential
+	Credentials []exportCredential `json:"credentials"`
 }
 
 type CredentialBulkExport struct {
@@ -20,13 +20,7 @@ type CredentialBulkExport struct {
 }
 
 func ExportCredentials(credentials []credentials.Credential) (*CredentialBulkExport, error) {
-	exportCreds := exportCredentials{make([]exportCredential, len(credentials))}
-
-	for i, credential := range credentials {
-		exportCreds.Credentials[i] = exportCredential{credential.Name, credential.Type, credential.Value}
-	}
-
-	result, err := yaml.Marshal(exportCreds)
+	result, err := yaml.Marshal(ExportedCredentials(credentials))
 
 	if err != nil {
 		return nil, err
@@ -35,6 +29,18 @@ func ExportCredentials(credentials []credentials.Credential) (*CredentialBulkExp
 	return &CredentialBulkExport{result}, nil
 }
 
+// ExportedCredentials returns the credentials in the structure of an export file,
+// for encoding in formats other than YAML
+func ExportedCredentials(credentials []credentials.Credential) interface{} {
+	exportCreds := exportCredentials{make([]exportCredential, len(credentials))}
+
+	for i, credential := range credentials {
+		exportCreds.Credentials[i] = exportCredential{credential.Name, credential.Type, credential.Value}
+	}
+
+	return exportCreds
+}
+
 func (credentialBulkExport *CredentialBulkExport) String() string {
 	return string(credentialBulkExport.Bytes)
 }
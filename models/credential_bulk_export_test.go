@@ -1,6 +1,8 @@
 package models_test
 
 import (
+	"encoding/json"
+
 	"code.cloudfoundry.org/credhub-cli/credhub/credentials"
 	"code.cloudfoundry.org/credhub-cli/models"
 
@@ -85,6 +87,16 @@ var _ = Describe("ExportCredentials", func() {
 
 		Expect(err).To(BeNil())
 	})
+
+	It("encodes as JSON with the same structure as the YAML", func() {
+		exported, err := json.Marshal(models.ExportedCredentials(credentials))
+
+		Expect(err).To(BeNil())
+		Expect(exported).To(MatchJSON(`{"credentials": [
+			{"name": "valueName", "type": "value", "value": "test"},
+			{"name": "passwordName", "type": "password", "value": "test"}
+		]}`))
+	})
 })
 
 var _ = Describe("CredentialBulkExport", func() {
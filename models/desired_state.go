@@ -0,0 +1,148 @@
+package models
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"code.cloudfoundry.org/credhub-cli/credhub/permissions"
+	"code.cloudfoundry.org/credhub-cli/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// DesiredState is the desired state of the credentials under a path
+//
+// Each credential holds either a literal value or the parameters to generate
+// its value with, and optionally the permissions it should have.
+type DesiredState struct {
+	Path        string              `yaml:"path"`
+	Credentials []DesiredCredential `yaml:"credentials"`
+}
+
+type DesiredCredential struct {
+	Name        string                 `yaml:"name"`
+	Type        string                 `yaml:"type"`
+	Value       interface{}            `yaml:"value"`
+	Parameters  map[string]interface{} `yaml:"parameters"`
+	Permissions []DesiredPermission    `yaml:"permissions"`
+}
+
+type DesiredPermission struct {
+	Actor      string   `yaml:"actor"`
+	Operations []string `yaml:"operations"`
+}
+
+func (s *DesiredState) ReadFile(filepath, currentPath string) error {
+	data, err := ioutil.ReadFile(filepath)
+	if err != nil {
+		return err
+	}
+
+	return s.ReadBytes(data, currentPath)
+}
+
+// ReadBytes parses the desired state. A path that does not start with / is
+// resolved against the current path, if one is given.
+func (s *DesiredState) ReadBytes(data []byte, currentPath string) error {
+	if err := yaml.UnmarshalStrict(data, s); err != nil {
+		return errors.NewInvalidDesiredStateError(err.Error())
+	}
+
+	if s.Path == "" {
+		return errors.NewInvalidDesiredStateError("a path is required")
+	}
+	if currentPath != "" && !strings.HasPrefix(s.Path, "/") {
+		s.Path = path.Join(currentPath, s.Path)
+	}
+	s.Path = "/" + strings.Trim(s.Path, "/")
+
+	seen := map[string]bool{}
+	for i := range s.Credentials {
+		credential := &s.Credentials[i]
+
+		if credential.Name == "" {
+			return errors.NewInvalidDesiredStateError(fmt.Sprintf("the credential at index %d has no name", i))
+		}
+
+		name, err := s.absoluteName(credential.Name)
+		if err != nil {
+			return err
+		}
+		credential.Name = name
+
+		if seen[name] {
+			return errors.NewInvalidDesiredStateError(fmt.Sprintf("the credential %q is listed more than once", name))
+		}
+		seen[name] = true
+
+		if credential.Type == "" {
+			return errors.NewInvalidDesiredStateError(fmt.Sprintf("the credential %q has no type", name))
+		}
+		credential.Type = strings.ToLower(credential.Type)
+
+		if (credential.Value == nil) == (credential.Parameters == nil) {
+			return errors.NewInvalidDesiredStateError(fmt.Sprintf("the credential %q must have either a value or parameters", name))
+		}
+
+		credential.Value = unpackAnyType(credential.Value)
+		for key, value := range credential.Parameters {
+			credential.Parameters[key] = unpackAnyType(value)
+		}
+		if ca, ok := credential.Parameters["ca"].(string); ok && ca != "" {
+			credential.Parameters["ca"] = CredentialName(s.Path, ca)
+		}
+
+		if _, err := credential.GenerationParameters(); err != nil {
+			return errors.NewInvalidDesiredStateError(fmt.Sprintf("the parameters of the credential %q are not valid: %s", name, err))
+		}
+	}
+
+	return nil
+}
+
+// absoluteName returns the name of a credential relative to the path, or an error if an absolute name is outside the path
+func (s *DesiredState) absoluteName(name string) (string, error) {
+	if !strings.HasPrefix(name, "/") {
+		return strings.TrimSuffix(s.Path, "/") + "/" + name, nil
+	}
+
+	if !strings.HasPrefix(name, strings.TrimSuffix(s.Path, "/")+"/") {
+		return "", errors.NewInvalidDesiredStateError(fmt.Sprintf("the credential %q is not under the path %q", name, s.Path))
+	}
+
+	return name, nil
+}
+
+// Generated returns true when the value of the credential is generated from parameters
+func (c DesiredCredential) Generated() bool {
+	return c.Parameters != nil
+}
+
+// ParsedParameters returns the parameters of a generated credential
+func (c DesiredCredential) ParsedParameters() (GenerationParameters, error) {
+	return parseGenerationParameters(c.Parameters)
+}
+
+// GenerationParameters returns the parameters to generate the credential with in the form used by GenerateCredential
+func (c DesiredCredential) GenerationParameters() (interface{}, error) {
+	if !c.Generated() {
+		return nil, nil
+	}
+
+	parameters, err := c.ParsedParameters()
+	if err != nil {
+		return nil, err
+	}
+
+	return generationRequest(c.Type, parameters), nil
+}
+
+// DesiredPermissions returns the permissions of the credential in the form used by the permissions API
+func (c DesiredCredential) DesiredPermissions() []permissions.Permission {
+	var perms []permissions.Permission
+	for _, p := range c.Permissions {
+		perms = append(perms, permissions.Permission{Actor: p.Actor, Operations: p.Operations})
+	}
+	return perms
+}
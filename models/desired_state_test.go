@@ -0,0 +1,134 @@
+package models_test
+
+import (
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials/generate"
+	"code.cloudfoundry.org/credhub-cli/credhub/permissions"
+	"code.cloudfoundry.org/credhub-cli/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DesiredState", func() {
+	Describe("ReadBytes()", func() {
+		It("parses values, parameters and permissions and makes names absolute", func() {
+			var state models.DesiredState
+			err := state.ReadBytes([]byte(`
+path: /deploy/
+credentials:
+- name: api-key
+  type: Value
+  value: some-value
+- name: /deploy/cert
+  type: certificate
+  value:
+    ca: some-ca
+    certificate: some-certificate
+    private_key: some-private-key
+- name: admin
+  type: user
+  parameters:
+    username: admin
+    length: 40
+  permissions:
+  - actor: uaa-user:some-user
+    operations: [read, write]
+`), "")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.Path).To(Equal("/deploy"))
+			Expect(state.Credentials).To(HaveLen(3))
+
+			Expect(state.Credentials[0].Name).To(Equal("/deploy/api-key"))
+			Expect(state.Credentials[0].Type).To(Equal("value"))
+			Expect(state.Credentials[0].Value).To(Equal("some-value"))
+			Expect(state.Credentials[0].Generated()).To(BeFalse())
+
+			Expect(state.Credentials[1].Name).To(Equal("/deploy/cert"))
+			Expect(state.Credentials[1].Value).To(Equal(map[string]interface{}{
+				"ca":          "some-ca",
+				"certificate": "some-certificate",
+				"private_key": "some-private-key",
+			}))
+
+			admin := state.Credentials[2]
+			Expect(admin.Generated()).To(BeTrue())
+			Expect(admin.GenerationParameters()).To(Equal(generate.User{Username: "admin", Length: 40}))
+			Expect(admin.DesiredPermissions()).To(Equal([]permissions.Permission{
+				{Actor: "uaa-user:some-user", Operations: []string{"read", "write"}},
+			}))
+		})
+
+		It("resolves a relative path against the current path", func() {
+			var state models.DesiredState
+			err := state.ReadBytes([]byte("path: cf\ncredentials:\n- name: api-key\n  type: value\n  value: v\n"), "/deploy")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.Path).To(Equal("/deploy/cf"))
+			Expect(state.Credentials[0].Name).To(Equal("/deploy/cf/api-key"))
+		})
+
+		It("returns generation parameters for other types", func() {
+			var state models.DesiredState
+			err := state.ReadBytes([]byte(`
+path: /deploy
+credentials:
+- name: ca
+  type: certificate
+  parameters:
+    common_name: some-ca
+    is_ca: true
+    alternative_names: [example.com]
+`), "")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.Credentials[0].GenerationParameters()).To(Equal(models.GenerationParameters{
+				CommonName:      "some-ca",
+				IsCA:            true,
+				AlternativeName: []string{"example.com"},
+			}))
+		})
+
+		It("resolves the CA of a certificate against the path", func() {
+			var state models.DesiredState
+			err := state.ReadBytes([]byte(`
+path: /deploy
+credentials:
+- name: cert
+  type: certificate
+  parameters:
+    common_name: some-cert
+    ca: some-ca
+- name: other-cert
+  type: certificate
+  parameters:
+    common_name: other-cert
+    ca: /shared/ca
+`), "")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.Credentials[0].GenerationParameters()).To(Equal(models.GenerationParameters{CommonName: "some-cert", Ca: "/deploy/some-ca"}))
+			Expect(state.Credentials[1].GenerationParameters()).To(Equal(models.GenerationParameters{CommonName: "other-cert", Ca: "/shared/ca"}))
+		})
+
+		DescribeTable("returns an error for invalid files",
+			func(yaml, reason string) {
+				var state models.DesiredState
+				err := state.ReadBytes([]byte(yaml), "")
+
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(reason))
+			},
+			Entry("no path", "credentials: []", "a path is required"),
+			Entry("unknown keys", "path: /a\nextra: true", "field extra not found"),
+			Entry("no name", "path: /a\ncredentials:\n- type: value\n  value: v", "the credential at index 0 has no name"),
+			Entry("no type", "path: /a\ncredentials:\n- name: b\n  value: v", `the credential "/a/b" has no type`),
+			Entry("neither value nor parameters", "path: /a\ncredentials:\n- name: b\n  type: value", `the credential "/a/b" must have either a value or parameters`),
+			Entry("both value and parameters", "path: /a\ncredentials:\n- name: b\n  type: password\n  value: v\n  parameters: {}", `the credential "/a/b" must have either a value or parameters`),
+			Entry("a name outside the path", "path: /a\ncredentials:\n- name: /b/c\n  type: value\n  value: v", `the credential "/b/c" is not under the path "/a"`),
+			Entry("duplicate names", "path: /a\ncredentials:\n- name: b\n  type: value\n  value: v\n- name: /a/b\n  type: value\n  value: v", `the credential "/a/b" is listed more than once`),
+			Entry("unknown parameters", "path: /a\ncredentials:\n- name: b\n  type: password\n  parameters: {lenght: 10}", `the parameters of the credential "/a/b" are not valid`),
+		)
+	})
+})
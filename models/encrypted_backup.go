@@ -0,0 +1,150 @@
+package models
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	encryptedBackupFormat        = "credhub-encrypted-backup"
+	encryptedBackupIterations    = 200000
+	encryptedBackupMinIterations = 10000
+	encryptedBackupMaxIterations = 10000000
+	encryptedBackupKeyLength     = 32
+	encryptedBackupSaltLength    = 16
+)
+
+// EncryptedBackup is a file of credentials encrypted with a passphrase
+//
+// The key is derived from the passphrase with PBKDF2-HMAC-SHA256 and the
+// contents are encrypted with AES-256-GCM.
+type EncryptedBackup struct {
+	Format     string `json:"format"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// EncryptBackup encrypts plaintext with a key derived from passphrase and returns the encoded backup
+func EncryptBackup(plaintext []byte, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, errors.New("a passphrase is required to encrypt a backup")
+	}
+
+	backup := EncryptedBackup{
+		Format:     encryptedBackupFormat,
+		KDF:        "pbkdf2-sha256",
+		Iterations: encryptedBackupIterations,
+		Salt:       make([]byte, encryptedBackupSaltLength),
+	}
+
+	if _, err := io.ReadFull(rand.Reader, backup.Salt); err != nil {
+		return nil, err
+	}
+
+	aead, err := backup.cipher(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	backup.Nonce = make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, backup.Nonce); err != nil {
+		return nil, err
+	}
+
+	backup.Ciphertext = aead.Seal(nil, backup.Nonce, plaintext, []byte(backup.Format))
+
+	return json.MarshalIndent(backup, "", "  ")
+}
+
+// IsEncryptedBackup reports whether data is a backup written by EncryptBackup
+func IsEncryptedBackup(data []byte) bool {
+	var backup EncryptedBackup
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) || json.Unmarshal(data, &backup) != nil {
+		return false
+	}
+	return backup.Format == encryptedBackupFormat
+}
+
+// DecryptBackup decrypts a backup written by EncryptBackup
+func DecryptBackup(data []byte, passphrase string) ([]byte, error) {
+	var backup EncryptedBackup
+	if err := json.Unmarshal(data, &backup); err != nil || backup.Format != encryptedBackupFormat {
+		return nil, errors.New("the file is not an encrypted backup")
+	}
+
+	if backup.KDF != "pbkdf2-sha256" {
+		return nil, errors.New("the encrypted backup uses an unsupported key derivation function")
+	}
+
+	if backup.Iterations < encryptedBackupMinIterations || backup.Iterations > encryptedBackupMaxIterations {
+		return nil, fmt.Errorf("the encrypted backup has %d key derivation iterations, which is outside the supported range of %d to %d", backup.Iterations, encryptedBackupMinIterations, encryptedBackupMaxIterations)
+	}
+
+	aead, err := backup.cipher(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(backup.Nonce) != aead.NonceSize() {
+		return nil, errors.New("the encrypted backup is corrupt")
+	}
+
+	plaintext, err := aead.Open(nil, backup.Nonce, backup.Ciphertext, []byte(backup.Format))
+	if err != nil {
+		return nil, errors.New("the encrypted backup could not be decrypted: the passphrase is incorrect or the file is corrupt")
+	}
+
+	return plaintext, nil
+}
+
+func (b *EncryptedBackup) cipher(passphrase string) (cipher.AEAD, error) {
+	key := pbkdf2SHA256([]byte(passphrase), b.Salt, b.Iterations, encryptedBackupKeyLength)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// pbkdf2SHA256 derives a key as described in RFC 8018, section 5.2
+func pbkdf2SHA256(password, salt []byte, iterations, keyLength int) []byte {
+	prf := hmac.New(sha256.New, password)
+	size := prf.Size()
+
+	var key []byte
+	for block := uint32(1); len(key) < keyLength; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.Write(prf, binary.BigEndian, block)
+		u := prf.Sum(nil)
+
+		t := make([]byte, size)
+		copy(t, u)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+
+		key = append(key, t...)
+	}
+
+	return key[:keyLength]
+}
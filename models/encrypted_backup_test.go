@@ -0,0 +1,71 @@
+package models_test
+
+import (
+	"encoding/json"
+
+	"code.cloudfoundry.org/credhub-cli/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EncryptedBackup", func() {
+	plaintext := []byte("credentials:\n- name: /some-password\n  type: password\n  value: some-value\n")
+
+	It("decrypts what it encrypts with the same passphrase", func() {
+		backup, err := models.EncryptBackup(plaintext, "some-passphrase")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(backup)).NotTo(ContainSubstring("some-value"))
+		Expect(models.IsEncryptedBackup(backup)).To(BeTrue())
+
+		decrypted, err := models.DecryptBackup(backup, "some-passphrase")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(decrypted).To(Equal(plaintext))
+	})
+
+	It("uses a new salt and nonce for every backup", func() {
+		first, _ := models.EncryptBackup(plaintext, "some-passphrase")
+		second, _ := models.EncryptBackup(plaintext, "some-passphrase")
+
+		var a, b models.EncryptedBackup
+		Expect(json.Unmarshal(first, &a)).To(Succeed())
+		Expect(json.Unmarshal(second, &b)).To(Succeed())
+		Expect(a.Salt).NotTo(Equal(b.Salt))
+		Expect(a.Nonce).NotTo(Equal(b.Nonce))
+		Expect(a.Ciphertext).NotTo(Equal(b.Ciphertext))
+	})
+
+	It("returns an error for the wrong passphrase", func() {
+		backup, _ := models.EncryptBackup(plaintext, "some-passphrase")
+
+		_, err := models.DecryptBackup(backup, "wrong-passphrase")
+		Expect(err).To(MatchError("the encrypted backup could not be decrypted: the passphrase is incorrect or the file is corrupt"))
+	})
+
+	It("rejects backups with too few or too many iterations before deriving the key", func() {
+		backup, _ := models.EncryptBackup(plaintext, "some-passphrase")
+
+		for _, iterations := range []int{0, 1000, 1000000000} {
+			var tampered map[string]interface{}
+			Expect(json.Unmarshal(backup, &tampered)).To(Succeed())
+			tampered["iterations"] = iterations
+			data, _ := json.Marshal(tampered)
+
+			_, err := models.DecryptBackup(data, "some-passphrase")
+			Expect(err).To(MatchError(ContainSubstring("key derivation iterations, which is outside the supported range of 10000 to 10000000")))
+		}
+	})
+
+	It("requires a passphrase to encrypt", func() {
+		_, err := models.EncryptBackup(plaintext, "")
+		Expect(err).To(MatchError("a passphrase is required to encrypt a backup"))
+	})
+
+	It("does not treat import files as encrypted backups", func() {
+		Expect(models.IsEncryptedBackup(plaintext)).To(BeFalse())
+		Expect(models.IsEncryptedBackup([]byte(`{"credentials":[]}`))).To(BeFalse())
+
+		_, err := models.DecryptBackup(plaintext, "some-passphrase")
+		Expect(err).To(MatchError("the file is not an encrypted backup"))
+	})
+})
@@ -1,5 +1,12 @@
 package models
 
+import (
+	"bytes"
+	"encoding/json"
+
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials/generate"
+)
+
 type GenerationParameters struct {
 	IncludeSpecial   bool     `json:"include_special,omitempty"`
 	ExcludeNumber    bool     `json:"exclude_number,omitempty"`
@@ -23,3 +30,36 @@ type GenerationParameters struct {
 	SSHComment       string   `json:"ssh_comment,omitempty"`
 	Username         string   `json:"username,omitempty"`
 }
+
+// parseGenerationParameters decodes parameters read from a file, returning an error for unknown parameters
+func parseGenerationParameters(raw map[string]interface{}) (GenerationParameters, error) {
+	var parameters GenerationParameters
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return parameters, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&parameters)
+
+	return parameters, err
+}
+
+// generationRequest returns the parameters in the form used by GenerateCredential,
+// which sends the username of a user credential as part of its value
+func generationRequest(credType string, parameters GenerationParameters) interface{} {
+	if credType == "user" && parameters.Username != "" {
+		return generate.User{
+			Username:       parameters.Username,
+			Length:         parameters.Length,
+			IncludeSpecial: parameters.IncludeSpecial,
+			ExcludeNumber:  parameters.ExcludeNumber,
+			ExcludeUpper:   parameters.ExcludeUpper,
+			ExcludeLower:   parameters.ExcludeLower,
+		}
+	}
+
+	return parameters
+}
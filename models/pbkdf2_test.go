@@ -0,0 +1,28 @@
+package models
+
+import (
+	"encoding/hex"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("pbkdf2SHA256()", func() {
+	DescribeTable("derives the PBKDF2-HMAC-SHA256 test vectors",
+		func(iterations int, expected string) {
+			key := pbkdf2SHA256([]byte("password"), []byte("salt"), iterations, 32)
+
+			Expect(hex.EncodeToString(key)).To(Equal(expected))
+		},
+		Entry("1 iteration", 1, "120fb6cffcf8b32c43e7225256c4f837a86548c92ccc35480805987cb70be17b"),
+		Entry("2 iterations", 2, "ae4d0c95af6b46d32d0adff928f06dd02a303f8ef3c251dfd6e2d85a95474c43"),
+		Entry("4096 iterations", 4096, "c5e478d59288c841aa530db6845c4c8d962893a001ce4e11a4963873aa98134a"),
+	)
+
+	It("derives keys longer than one block", func() {
+		key := pbkdf2SHA256([]byte("passwordPASSWORDpassword"), []byte("saltSALTsaltSALTsaltSALTsaltSALTsalt"), 4096, 40)
+
+		Expect(hex.EncodeToString(key)).To(Equal("348c89dbcbd32b2f32d814b8116e84cf2b17347ebc1800181c4e2a1fb8dd53e1c635518c7dac47e9"))
+	})
+})
@@ -0,0 +1,419 @@
+package models
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials"
+	"code.cloudfoundry.org/credhub-cli/errors"
+	"golang.org/x/crypto/ssh"
+	"gopkg.in/yaml.v2"
+)
+
+// Policy is a set of rules that credentials are linted against, and that can
+// optionally be enforced before credentials are set, generated or imported
+//
+// Each section only applies when it is present in the policy file, and its
+// violations have the section's severity, error unless it is set to warning.
+type Policy struct {
+	Password    *PasswordPolicy    `yaml:"password"`
+	Keys        *KeyPolicy         `yaml:"keys"`
+	Certificate *CertificatePolicy `yaml:"certificate"`
+	Names       *NamePolicy        `yaml:"names"`
+}
+
+// PasswordPolicy applies to password credentials and the passwords of user credentials
+type PasswordPolicy struct {
+	MinLength int      `yaml:"min_length"`
+	Require   []string `yaml:"require"`
+	Severity  string   `yaml:"severity"`
+}
+
+// KeyPolicy applies to RSA and SSH keys. The minimum length defaults to 2048 bits.
+type KeyPolicy struct {
+	MinLength int    `yaml:"min_length"`
+	Severity  string `yaml:"severity"`
+}
+
+// CertificatePolicy flags certificates signed with SHA-1 unless they are allowed,
+// certificates valid for longer than the maximum, and certificates that sign
+// other certificates without being a CA
+type CertificatePolicy struct {
+	MaxValidityDays int    `yaml:"max_validity_days"`
+	AllowSHA1       bool   `yaml:"allow_sha1"`
+	Severity        string `yaml:"severity"`
+}
+
+// NamePolicy requires credential names to match one of the allowed patterns, if
+// any, and none of the denied patterns
+type NamePolicy struct {
+	Allow    []string `yaml:"allow"`
+	Deny     []string `yaml:"deny"`
+	Severity string   `yaml:"severity"`
+
+	allow []*regexp.Regexp
+	deny  []*regexp.Regexp
+}
+
+type PolicyViolation struct {
+	Name     string `json:"name" yaml:"name"`
+	Type     string `json:"type" yaml:"type"`
+	Rule     string `json:"rule" yaml:"rule"`
+	Severity string `json:"severity" yaml:"severity"`
+	Message  string `json:"message" yaml:"message"`
+}
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+
+	defaultKeyLength      = 2048
+	defaultPasswordLength = 30
+	defaultDurationDays   = 365
+)
+
+var characterClasses = map[string]func(rune) bool{
+	"upper":   unicode.IsUpper,
+	"lower":   unicode.IsLower,
+	"number":  unicode.IsDigit,
+	"special": func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) },
+}
+
+func (p *Policy) ReadFile(filepath string) error {
+	data, err := ioutil.ReadFile(filepath)
+	if err != nil {
+		return err
+	}
+
+	return p.ReadBytes(data)
+}
+
+func (p *Policy) ReadBytes(data []byte) error {
+	if err := yaml.UnmarshalStrict(data, p); err != nil {
+		return errors.NewInvalidPolicyError(err.Error())
+	}
+
+	var severities []*string
+	if p.Password != nil {
+		severities = append(severities, &p.Password.Severity)
+	}
+	if p.Keys != nil {
+		severities = append(severities, &p.Keys.Severity)
+	}
+	if p.Certificate != nil {
+		severities = append(severities, &p.Certificate.Severity)
+	}
+	if p.Names != nil {
+		severities = append(severities, &p.Names.Severity)
+	}
+
+	for _, severity := range severities {
+		if *severity == "" {
+			*severity = SeverityError
+		}
+		if *severity != SeverityError && *severity != SeverityWarning {
+			return errors.NewInvalidPolicyError(fmt.Sprintf("the severity %q is not valid. Valid severities are 'error' and 'warning'", *severity))
+		}
+	}
+
+	if p.Password != nil {
+		for _, class := range p.Password.Require {
+			if characterClasses[class] == nil {
+				return errors.NewInvalidPolicyError(fmt.Sprintf("the character class %q is not valid. Valid classes are 'upper', 'lower', 'number' and 'special'", class))
+			}
+		}
+	}
+
+	if p.Keys != nil && p.Keys.MinLength == 0 {
+		p.Keys.MinLength = defaultKeyLength
+	}
+
+	if p.Names != nil {
+		var err error
+		if p.Names.allow, err = compileNamePatterns(p.Names.Allow); err != nil {
+			return err
+		}
+		if p.Names.deny, err = compileNamePatterns(p.Names.Deny); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func compileNamePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	var compiled []*regexp.Regexp
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, errors.NewInvalidPolicyError(fmt.Sprintf("the name pattern %q is not valid: %s", pattern, err))
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// CheckCredentials returns the violations of the policy by stored credentials.
+// A certificate is considered to sign other certificates when another
+// credential, or the certificate itself, names it as its CA.
+func (p *Policy) CheckCredentials(creds []credentials.Credential) []PolicyViolation {
+	usedAsCA := map[string]bool{}
+	normalized := make([]credentials.Credential, len(creds))
+
+	for i, credential := range creds {
+		credential.Name = "/" + strings.TrimPrefix(credential.Name, "/")
+		credential.Value = genericValue(credential.Value)
+		normalized[i] = credential
+
+		if value, ok := credential.Value.(map[string]interface{}); ok && credential.Type == "certificate" {
+			if caName, _ := value["ca_name"].(string); caName != "" {
+				usedAsCA["/"+strings.TrimPrefix(caName, "/")] = true
+			}
+		}
+	}
+
+	violations := []PolicyViolation{}
+	for _, credential := range normalized {
+		violations = append(violations, p.checkCredential(credential, usedAsCA[credential.Name])...)
+	}
+	return violations
+}
+
+func (p *Policy) checkCredential(credential credentials.Credential, usedAsCA bool) []PolicyViolation {
+	check := violationCollector{name: credential.Name, credType: credential.Type}
+	p.checkName(&check)
+
+	value, _ := credential.Value.(map[string]interface{})
+
+	switch credential.Type {
+	case "password":
+		password, _ := credential.Value.(string)
+		p.checkPassword(&check, password)
+	case "user":
+		password, _ := value["password"].(string)
+		p.checkPassword(&check, password)
+	case "rsa":
+		publicKey, _ := value["public_key"].(string)
+		if block, _ := pem.Decode([]byte(publicKey)); block != nil {
+			if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+				p.checkKey(&check, key)
+			}
+		}
+	case "ssh":
+		publicKey, _ := value["public_key"].(string)
+		if key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(publicKey)); err == nil {
+			if cryptoKey, ok := key.(ssh.CryptoPublicKey); ok {
+				p.checkKey(&check, cryptoKey.CryptoPublicKey())
+			}
+		}
+	case "certificate":
+		certificate, _ := value["certificate"].(string)
+		if block, _ := pem.Decode([]byte(certificate)); block != nil {
+			if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+				p.checkCertificate(&check, cert, usedAsCA)
+			}
+		}
+	}
+
+	return check.violations
+}
+
+// CheckGeneration returns the violations of the policy by a credential generated with the parameters,
+// using the defaults of the server for the parameters that are not set
+func (p *Policy) CheckGeneration(name, credType string, parameters GenerationParameters) []PolicyViolation {
+	check := violationCollector{name: "/" + strings.TrimPrefix(name, "/"), credType: credType}
+	p.checkName(&check)
+
+	switch credType {
+	case "password", "user":
+		if p.Password == nil {
+			break
+		}
+
+		length := parameters.Length
+		if length == 0 {
+			length = defaultPasswordLength
+		}
+		p.checkPasswordLength(&check, length)
+
+		excluded := map[string]bool{
+			"upper":   parameters.ExcludeUpper,
+			"lower":   parameters.ExcludeLower,
+			"number":  parameters.ExcludeNumber,
+			"special": !parameters.IncludeSpecial,
+		}
+		var missing []string
+		for _, class := range p.Password.Require {
+			if excluded[class] {
+				missing = append(missing, class)
+			}
+		}
+		p.checkPasswordClasses(&check, missing)
+	case "rsa", "ssh":
+		if p.Keys == nil {
+			break
+		}
+
+		keyLength := parameters.KeyLength
+		if keyLength == 0 {
+			keyLength = defaultKeyLength
+		}
+		p.checkKeyLength(&check, keyLength)
+	case "certificate":
+		if p.Certificate == nil {
+			break
+		}
+
+		duration := parameters.Duration
+		if duration == 0 {
+			duration = defaultDurationDays
+		}
+		p.checkValidity(&check, duration)
+	}
+
+	return check.violations
+}
+
+type violationCollector struct {
+	name       string
+	credType   string
+	violations []PolicyViolation
+}
+
+func (c *violationCollector) add(rule, severity, message string) {
+	c.violations = append(c.violations, PolicyViolation{
+		Name:     c.name,
+		Type:     c.credType,
+		Rule:     rule,
+		Severity: severity,
+		Message:  message,
+	})
+}
+
+func (p *Policy) checkName(check *violationCollector) {
+	if p.Names == nil {
+		return
+	}
+
+	if len(p.Names.allow) > 0 {
+		allowed := false
+		for _, re := range p.Names.allow {
+			if re.MatchString(check.name) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			check.add("name-pattern", p.Names.Severity, "the name does not match any allowed pattern")
+		}
+	}
+
+	for _, re := range p.Names.deny {
+		if re.MatchString(check.name) {
+			check.add("name-pattern", p.Names.Severity, fmt.Sprintf("the name matches the denied pattern %q", re.String()))
+		}
+	}
+}
+
+func (p *Policy) checkPassword(check *violationCollector, password string) {
+	if p.Password == nil {
+		return
+	}
+
+	p.checkPasswordLength(check, len([]rune(password)))
+
+	var missing []string
+	for _, class := range p.Password.Require {
+		if strings.IndexFunc(password, characterClasses[class]) < 0 {
+			missing = append(missing, class)
+		}
+	}
+	p.checkPasswordClasses(check, missing)
+}
+
+func (p *Policy) checkPasswordLength(check *violationCollector, length int) {
+	if length < p.Password.MinLength {
+		check.add("password-length", p.Password.Severity, fmt.Sprintf("the password is %d characters, shorter than the minimum of %d", length, p.Password.MinLength))
+	}
+}
+
+func (p *Policy) checkPasswordClasses(check *violationCollector, missing []string) {
+	if len(missing) > 0 {
+		check.add("password-characters", p.Password.Severity, "the password has no characters of the required classes: "+strings.Join(missing, ", "))
+	}
+}
+
+func (p *Policy) checkKey(check *violationCollector, key interface{}) {
+	if p.Keys == nil {
+		return
+	}
+
+	if rsaKey, ok := key.(*rsa.PublicKey); ok {
+		p.checkKeyLength(check, rsaKey.N.BitLen())
+	}
+}
+
+func (p *Policy) checkKeyLength(check *violationCollector, bits int) {
+	if bits < p.Keys.MinLength {
+		check.add("key-length", p.Keys.Severity, fmt.Sprintf("the key is %d bits, shorter than the minimum of %d", bits, p.Keys.MinLength))
+	}
+}
+
+func (p *Policy) checkCertificate(check *violationCollector, cert *x509.Certificate, usedAsCA bool) {
+	if p.Certificate == nil {
+		return
+	}
+
+	switch cert.SignatureAlgorithm {
+	case x509.SHA1WithRSA, x509.DSAWithSHA1, x509.ECDSAWithSHA1:
+		if !p.Certificate.AllowSHA1 {
+			check.add("certificate-signature", p.Certificate.Severity, "the certificate is signed with "+cert.SignatureAlgorithm.String())
+		}
+	}
+
+	p.checkValidity(check, int(math.Ceil(cert.NotAfter.Sub(cert.NotBefore).Hours()/24)))
+
+	if usedAsCA && !cert.IsCA {
+		check.add("certificate-is-ca", p.Certificate.Severity, "the certificate signs other certificates but is not a CA")
+	}
+}
+
+func (p *Policy) checkValidity(check *violationCollector, days int) {
+	if p.Certificate.MaxValidityDays > 0 && days > p.Certificate.MaxValidityDays {
+		check.add("certificate-validity", p.Certificate.Severity, fmt.Sprintf("the certificate is valid for %d days, longer than the maximum of %d", days, p.Certificate.MaxValidityDays))
+	}
+}
+
+// genericValue converts a value to the maps, slices and scalars it would be
+// decoded to from JSON, so that typed values are checked like stored ones
+func genericValue(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return value
+	}
+	return generic
+}
+
+// PolicyErrors returns the number of violations with the error severity
+func PolicyErrors(violations []PolicyViolation) int {
+	count := 0
+	for _, violation := range violations {
+		if violation.Severity == SeverityError {
+			count++
+		}
+	}
+	return count
+}
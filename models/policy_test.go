@@ -0,0 +1,153 @@
+package models_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials"
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials/values"
+	"code.cloudfoundry.org/credhub-cli/models"
+	"golang.org/x/crypto/ssh"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Policy", func() {
+	generateKey := func(bits int) *rsa.PrivateKey {
+		key, err := rsa.GenerateKey(rand.Reader, bits)
+		Expect(err).NotTo(HaveOccurred())
+		return key
+	}
+
+	certificate := func(algorithm x509.SignatureAlgorithm, days int, isCA bool) string {
+		key := generateKey(2048)
+		template := &x509.Certificate{
+			SerialNumber:          big.NewInt(1),
+			Subject:               pkix.Name{CommonName: "example"},
+			NotBefore:             time.Now(),
+			NotAfter:              time.Now().AddDate(0, 0, days),
+			SignatureAlgorithm:    algorithm,
+			IsCA:                  isCA,
+			BasicConstraintsValid: true,
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+		Expect(err).NotTo(HaveOccurred())
+		return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	}
+
+	rules := func(violations []models.PolicyViolation) []string {
+		result := []string{}
+		for _, violation := range violations {
+			result = append(result, violation.Name+" "+violation.Rule+" "+violation.Severity)
+		}
+		return result
+	}
+
+	var policy models.Policy
+
+	BeforeEach(func() {
+		policy = models.Policy{}
+		Expect(policy.ReadBytes([]byte(`
+password:
+  min_length: 20
+  require: [upper, number]
+keys: {}
+certificate:
+  max_validity_days: 398
+names:
+  allow: ['^/cf/']
+  deny: ['secret$']
+  severity: warning
+`))).To(Succeed())
+	})
+
+	Describe("CheckCredentials()", func() {
+		It("checks passwords, keys, certificates and names", func() {
+			rsaKey := generateKey(1024)
+			rsaPublicKey, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+			Expect(err).NotTo(HaveOccurred())
+			sshPublicKey, err := ssh.NewPublicKey(&generateKey(1024).PublicKey)
+			Expect(err).NotTo(HaveOccurred())
+
+			violations := policy.CheckCredentials([]credentials.Credential{
+				{Name: "/cf/short", Type: "password", Value: "Short1"},
+				{Name: "/cf/lower", Type: "user", Value: map[string]interface{}{"username": "u", "password": "onlylowercaseletters-long"}},
+				{Name: "/cf/good", Type: "password", Value: values.Password("A-good-password-with-1-number")},
+				{Name: "/cf/rsa", Type: "rsa", Value: values.RSA{PublicKey: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: rsaPublicKey}))}},
+				{Name: "/cf/ssh", Type: "ssh", Value: map[string]interface{}{"public_key": string(ssh.MarshalAuthorizedKey(sshPublicKey))}},
+				{Name: "/cf/sha1", Type: "certificate", Value: map[string]interface{}{"certificate": certificate(x509.SHA1WithRSA, 30, false)}},
+				{Name: "/cf/long", Type: "certificate", Value: map[string]interface{}{"certificate": certificate(x509.SHA256WithRSA, 825, false)}},
+				{Name: "/cf/not-ca", Type: "certificate", Value: map[string]interface{}{"certificate": certificate(x509.SHA256WithRSA, 30, false)}},
+				{Name: "/cf/leaf", Type: "certificate", Value: map[string]interface{}{"ca_name": "/cf/not-ca", "certificate": certificate(x509.SHA256WithRSA, 30, false)}},
+				{Name: "/other/secret", Type: "value", Value: "v"},
+			})
+
+			Expect(rules(violations)).To(Equal([]string{
+				"/cf/short password-length error",
+				"/cf/lower password-characters error",
+				"/cf/rsa key-length error",
+				"/cf/ssh key-length error",
+				"/cf/sha1 certificate-signature error",
+				"/cf/long certificate-validity error",
+				"/cf/not-ca certificate-is-ca error",
+				"/other/secret name-pattern warning",
+				"/other/secret name-pattern warning",
+			}))
+
+			Expect(violations[0].Message).To(Equal("the password is 6 characters, shorter than the minimum of 20"))
+			Expect(violations[1].Message).To(Equal("the password has no characters of the required classes: upper, number"))
+			Expect(violations[2].Message).To(Equal("the key is 1024 bits, shorter than the minimum of 2048"))
+			Expect(violations[4].Message).To(Equal("the certificate is signed with SHA1-RSA"))
+			Expect(violations[5].Message).To(Equal("the certificate is valid for 825 days, longer than the maximum of 398"))
+			Expect(violations[7].Message).To(Equal("the name does not match any allowed pattern"))
+			Expect(violations[8].Message).To(Equal(`the name matches the denied pattern "secret$"`))
+		})
+
+		It("only applies the sections in the policy", func() {
+			policy = models.Policy{}
+			Expect(policy.ReadBytes([]byte("keys: {min_length: 1024}\ncertificate: {allow_sha1: true}"))).To(Succeed())
+
+			violations := policy.CheckCredentials([]credentials.Credential{
+				{Name: "/short", Type: "password", Value: "a"},
+				{Name: "/sha1", Type: "certificate", Value: map[string]interface{}{"certificate": certificate(x509.SHA1WithRSA, 3650, true)}},
+			})
+
+			Expect(violations).To(BeEmpty())
+		})
+	})
+
+	Describe("CheckGeneration()", func() {
+		It("checks the parameters with the defaults of the server", func() {
+			Expect(rules(policy.CheckGeneration("/cf/password", "password", models.GenerationParameters{}))).To(BeEmpty())
+			Expect(rules(policy.CheckGeneration("/cf/password", "password", models.GenerationParameters{Length: 10, ExcludeNumber: true}))).To(Equal([]string{
+				"/cf/password password-length error",
+				"/cf/password password-characters error",
+			}))
+			Expect(rules(policy.CheckGeneration("/cf/ssh", "ssh", models.GenerationParameters{KeyLength: 1024}))).To(Equal([]string{"/cf/ssh key-length error"}))
+			Expect(rules(policy.CheckGeneration("/cf/cert", "certificate", models.GenerationParameters{}))).To(BeEmpty())
+			Expect(rules(policy.CheckGeneration("/cf/cert", "certificate", models.GenerationParameters{Duration: 730}))).To(Equal([]string{"/cf/cert certificate-validity error"}))
+		})
+	})
+
+	Describe("ReadBytes()", func() {
+		DescribeTable("returns an error for invalid policies",
+			func(yaml, reason string) {
+				err := (&models.Policy{}).ReadBytes([]byte(yaml))
+
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(reason))
+			},
+			Entry("unknown rules", "password: {minimum: 1}", "field minimum not found"),
+			Entry("an unknown severity", "keys: {severity: fatal}", `the severity "fatal" is not valid`),
+			Entry("an unknown character class", "password: {require: [emoji]}", `the character class "emoji" is not valid`),
+			Entry("an invalid name pattern", "names: {deny: ['(']}", `the name pattern "(" is not valid`),
+		)
+	})
+})
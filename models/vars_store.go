@@ -0,0 +1,140 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials"
+	"code.cloudfoundry.org/credhub-cli/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// A BOSH vars-store file is a flat map of variable names to values, in the
+// shape the BOSH CLI generates them in for each variable type:
+//
+//   password:    a string
+//   certificate: a map of ca, certificate and private_key
+//   ssh:         a map of private_key, public_key and public_key_fingerprint
+//   rsa:         a map of private_key and public_key
+//   user:        a map of username and password
+
+// ReadVarsStore returns the variables of a vars-store file as credentials to
+// import, named under the prefix with their type inferred from the shape of
+// their value
+func ReadVarsStore(data []byte, prefix string) (CredentialBulkImport, error) {
+	// the variables are read once in order, and again for values without nested ordering
+	var (
+		ordered   yaml.MapSlice
+		varsStore map[interface{}]interface{}
+	)
+	if yaml.Unmarshal(data, &ordered) != nil || yaml.Unmarshal(data, &varsStore) != nil {
+		return CredentialBulkImport{}, errors.NewInvalidImportYamlError()
+	}
+
+	bulkImport := CredentialBulkImport{Credentials: []map[string]interface{}{}}
+	for _, item := range ordered {
+		name := unpackKey(item.Key)
+		if varsStore[item.Key] == nil {
+			return CredentialBulkImport{}, errors.NewVarsStoreEmptyValueError(name)
+		}
+		credType, value := varsStoreCredential(unpackAnyType(varsStore[item.Key]))
+
+		bulkImport.Credentials = append(bulkImport.Credentials, map[string]interface{}{
+			"name":  CredentialName(prefix, name),
+			"type":  credType,
+			"value": value,
+		})
+	}
+
+	return bulkImport, nil
+}
+
+// varsStoreCredential infers the type of a variable from the shape of its value
+// and returns the value in the form CredHub accepts for that type
+func varsStoreCredential(value interface{}) (string, interface{}) {
+	switch typedValue := value.(type) {
+	case string:
+		return "password", typedValue
+	case map[string]interface{}:
+		switch {
+		case hasKeys(typedValue, "certificate"):
+			return "certificate", selectKeys(typedValue, "ca", "certificate", "private_key")
+		case hasKeys(typedValue, "public_key", "private_key", "public_key_fingerprint"):
+			return "ssh", selectKeys(typedValue, "public_key", "private_key")
+		case hasKeys(typedValue, "public_key", "private_key") && len(typedValue) == 2:
+			return "rsa", typedValue
+		case hasKeys(typedValue, "username", "password") && len(typedValue) == 2:
+			return "user", typedValue
+		}
+		return "json", typedValue
+	}
+	return "value", fmt.Sprint(value)
+}
+
+func hasKeys(value map[string]interface{}, keys ...string) bool {
+	for _, key := range keys {
+		if _, ok := value[key]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func selectKeys(value map[string]interface{}, keys ...string) map[string]interface{} {
+	selected := map[string]interface{}{}
+	for _, key := range keys {
+		if v, ok := value[key]; ok {
+			selected[key] = v
+		}
+	}
+	return selected
+}
+
+// ExportVarsStore returns the credentials as a vars-store file, named relative to the prefix
+func ExportVarsStore(credentials []credentials.Credential, prefix string) (*CredentialBulkExport, error) {
+	result, err := yaml.Marshal(ExportedVarsStore(credentials, prefix))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &CredentialBulkExport{result}, nil
+}
+
+// ExportedVarsStore returns the credentials in the structure of a vars-store file,
+// for encoding in formats other than YAML
+func ExportedVarsStore(credentials []credentials.Credential, prefix string) map[string]interface{} {
+	prefix = strings.TrimSuffix("/"+strings.Trim(prefix, "/"), "/") + "/"
+
+	varsStore := map[string]interface{}{}
+	for _, credential := range credentials {
+		name := credential.Name
+		if !strings.HasPrefix(name, "/") {
+			name = "/" + name
+		}
+		varsStore[strings.TrimPrefix(name, prefix)] = varsStoreValue(credential)
+	}
+
+	return varsStore
+}
+
+// varsStoreValue returns the value of a credential in the shape BOSH generates it in
+func varsStoreValue(credential credentials.Credential) interface{} {
+	value, ok := credential.Value.(map[string]interface{})
+	if !ok {
+		return credential.Value
+	}
+
+	switch credential.Type {
+	case "certificate":
+		return selectKeys(value, "ca", "certificate", "private_key")
+	case "ssh":
+		return selectKeys(value, "private_key", "public_key", "public_key_fingerprint")
+	case "rsa":
+		return selectKeys(value, "private_key", "public_key")
+	case "user":
+		return selectKeys(value, "username", "password")
+	}
+
+	return value
+}
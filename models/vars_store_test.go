@@ -0,0 +1,102 @@
+package models_test
+
+import (
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials"
+	"code.cloudfoundry.org/credhub-cli/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("VarsStore", func() {
+	Describe("ReadVarsStore()", func() {
+		It("infers the type of each variable from its value and names it under the prefix", func() {
+			bulkImport, err := models.ReadVarsStore([]byte(`
+admin_password: some-password
+port: 8080
+tls:
+  ca: some-ca
+  certificate: some-certificate
+  private_key: some-private-key
+ssh_key:
+  private_key: some-private-key
+  public_key: ssh-rsa some-public-key
+  public_key_fingerprint: some-fingerprint
+jwt:
+  private_key: some-private-key
+  public_key: some-public-key
+admin:
+  username: admin
+  password: some-password
+/shared/settings:
+  some: setting
+`), "/director/deployment/")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bulkImport.Credentials).To(Equal([]map[string]interface{}{
+				{"name": "/director/deployment/admin_password", "type": "password", "value": "some-password"},
+				{"name": "/director/deployment/port", "type": "value", "value": "8080"},
+				{"name": "/director/deployment/tls", "type": "certificate", "value": map[string]interface{}{
+					"ca": "some-ca", "certificate": "some-certificate", "private_key": "some-private-key",
+				}},
+				{"name": "/director/deployment/ssh_key", "type": "ssh", "value": map[string]interface{}{
+					"private_key": "some-private-key", "public_key": "ssh-rsa some-public-key",
+				}},
+				{"name": "/director/deployment/jwt", "type": "rsa", "value": map[string]interface{}{
+					"private_key": "some-private-key", "public_key": "some-public-key",
+				}},
+				{"name": "/director/deployment/admin", "type": "user", "value": map[string]interface{}{
+					"username": "admin", "password": "some-password",
+				}},
+				{"name": "/shared/settings", "type": "json", "value": map[string]interface{}{"some": "setting"}},
+			}))
+		})
+
+		It("returns an error naming a variable without a value", func() {
+			_, err := models.ReadVarsStore([]byte("admin_password: some-password\nempty_password:\n"), "/a")
+
+			Expect(err).To(MatchError(`The variable "empty_password" in the vars-store file has no value. Please update and retry your request.`))
+		})
+
+		It("returns an error for invalid YAML", func() {
+			_, err := models.ReadVarsStore([]byte("- not a map"), "/a")
+
+			Expect(err).To(MatchError("The referenced file does not contain valid yaml structure. Please update and retry your request."))
+		})
+	})
+
+	Describe("ExportVarsStore()", func() {
+		It("names the variables relative to the prefix and keeps the values BOSH generates", func() {
+			export, err := models.ExportVarsStore([]credentials.Credential{
+				{Name: "/director/deployment/tls", Type: "certificate", Value: map[string]interface{}{
+					"ca_name": "/director/deployment/ca", "ca": "some-ca", "certificate": "some-certificate", "private_key": "some-private-key",
+				}},
+				{Name: "/director/deployment/admin", Type: "user", Value: map[string]interface{}{
+					"username": "admin", "password": "some-password", "password_hash": "some-hash",
+				}},
+				{Name: "/director/deployment/admin_password", Type: "password", Value: "some-password"},
+				{Name: "/director/deployment/ssh_key", Type: "ssh", Value: map[string]interface{}{
+					"private_key": "some-private-key", "public_key": "some-public-key", "public_key_fingerprint": "some-fingerprint",
+				}},
+				{Name: "/director/other/password", Type: "password", Value: "other-password"},
+			}, "/director/deployment")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(export.String()).To(MatchYAML(`
+/director/other/password: other-password
+admin:
+  password: some-password
+  username: admin
+admin_password: some-password
+ssh_key:
+  private_key: some-private-key
+  public_key: some-public-key
+  public_key_fingerprint: some-fingerprint
+tls:
+  ca: some-ca
+  certificate: some-certificate
+  private_key: some-private-key
+`))
+		})
+	})
+})
@@ -5,7 +5,7 @@ import (
 	"os"
 )
 
-var CREDHUB_ENV_VARS []string = []string{"CREDHUB_SERVER", "CREDHUB_CLIENT", "CREDHUB_SECRET", "CREDHUB_CA_CERT"}
+var CREDHUB_ENV_VARS []string = []string{"CREDHUB_SERVER", "CREDHUB_CLIENT", "CREDHUB_SECRET", "CREDHUB_CA_CERT", "CREDHUB_CLIENT_CERT", "CREDHUB_CLIENT_KEY", "CREDHUB_CREDENTIAL_PROCESS", "CREDHUB_TOKEN", "CREDHUB_TOKEN_FILE", "CREDHUB_BACKUP_PASSPHRASE"}
 
 func UnsetAndCacheCredHubEnvVars() map[string]string {
 	credhubEnv := make(map[string]string)
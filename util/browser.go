@@ -0,0 +1,29 @@
+package util
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// OpenBrowser opens the url in the user's default web browser. The BROWSER
+// environment variable, if set to a command, is used instead of the platform default.
+func OpenBrowser(url string) error {
+	var cmd *exec.Cmd
+
+	if args := strings.Fields(os.Getenv("BROWSER")); len(args) > 0 {
+		cmd = exec.Command(args[0], append(args[1:], url)...)
+	} else {
+		switch runtime.GOOS {
+		case "darwin":
+			cmd = exec.Command("open", url)
+		case "windows":
+			cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+		default:
+			cmd = exec.Command("xdg-open", url)
+		}
+	}
+
+	return cmd.Start()
+}
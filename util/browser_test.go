@@ -0,0 +1,61 @@
+package util_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"code.cloudfoundry.org/credhub-cli/util"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OpenBrowser", func() {
+	var (
+		tempDir string
+		oldPath string
+	)
+
+	BeforeEach(func() {
+		if runtime.GOOS == "windows" {
+			Skip("uses a shell script as the browser")
+		}
+
+		var err error
+		tempDir, err = ioutil.TempDir("", "browser")
+		Expect(err).NotTo(HaveOccurred())
+		oldPath = os.Getenv("PATH")
+	})
+
+	AfterEach(func() {
+		os.Unsetenv("BROWSER")
+		os.Setenv("PATH", oldPath)
+		os.RemoveAll(tempDir)
+	})
+
+	It("opens the url with the command in BROWSER", func() {
+		opened := filepath.Join(tempDir, "opened")
+		script := filepath.Join(tempDir, "browser")
+		Expect(ioutil.WriteFile(script, []byte("#!/bin/sh\necho \"$@\" > "+opened+"\n"), 0700)).To(Succeed())
+		os.Setenv("BROWSER", script+" --new-window")
+
+		Expect(util.OpenBrowser("https://example.com")).To(Succeed())
+
+		Eventually(func() string {
+			contents, _ := ioutil.ReadFile(opened)
+			return string(contents)
+		}, 5*time.Second).Should(Equal("--new-window https://example.com\n"))
+	})
+
+	It("falls back to the platform opener when BROWSER is blank", func() {
+		os.Setenv("BROWSER", "   ")
+		os.Setenv("PATH", tempDir)
+
+		err := util.OpenBrowser("https://example.com")
+
+		Expect(err).To(MatchError(ContainSubstring("executable file not found")))
+	})
+})
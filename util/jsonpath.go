@@ -0,0 +1,210 @@
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// JSONPath is a parsed JSONPath expression that can be evaluated against
+// values decoded from JSON, i.e. map[string]interface{}, []interface{} and scalars.
+//
+// The supported syntax is a subset of JSONPath as used by kubectl:
+//
+//	$            the root value (optional)
+//	.key         a map key
+//	['key']      a map key that contains special characters
+//	[n]          a list index, negative indexes count from the end
+//	[*] or .*    every element of a list or value of a map
+//	..key        the key at any depth
+//
+// The expression may be wrapped in braces, e.g. {.credentials[*].name}.
+type JSONPath struct {
+	expression string
+	steps      []jsonPathStep
+}
+
+type jsonPathStepKind int
+
+const (
+	jsonPathKey jsonPathStepKind = iota
+	jsonPathIndex
+	jsonPathWildcard
+	jsonPathRecursiveKey
+)
+
+type jsonPathStep struct {
+	kind  jsonPathStepKind
+	key   string
+	index int
+}
+
+// ParseJSONPath parses a JSONPath expression
+func ParseJSONPath(expression string) (*JSONPath, error) {
+	path := &JSONPath{expression: expression}
+
+	expr := strings.TrimSpace(expression)
+	if strings.HasPrefix(expr, "{") && strings.HasSuffix(expr, "}") {
+		expr = strings.TrimSpace(expr[1 : len(expr)-1])
+	}
+	expr = strings.TrimPrefix(expr, "$")
+
+	if expr != "" && expr[0] != '.' && expr[0] != '[' {
+		expr = "." + expr
+	}
+
+	for len(expr) > 0 {
+		var (
+			step jsonPathStep
+			err  error
+		)
+
+		switch {
+		case strings.HasPrefix(expr, ".."):
+			var key string
+			key, expr = readJSONPathKey(expr[2:])
+			if key == "" {
+				return nil, path.syntaxError("expected a key after '..'")
+			}
+			step = jsonPathStep{kind: jsonPathRecursiveKey, key: key}
+		case expr[0] == '.':
+			var key string
+			key, expr = readJSONPathKey(expr[1:])
+			switch key {
+			case "":
+				return nil, path.syntaxError("expected a key after '.'")
+			case "*":
+				step = jsonPathStep{kind: jsonPathWildcard}
+			default:
+				step = jsonPathStep{kind: jsonPathKey, key: key}
+			}
+		case expr[0] == '[':
+			end := strings.Index(expr, "]")
+			if end < 0 {
+				return nil, path.syntaxError("unterminated '['")
+			}
+			step, err = parseJSONPathBracket(expr[1:end])
+			if err != nil {
+				return nil, path.syntaxError(err.Error())
+			}
+			expr = expr[end+1:]
+		default:
+			return nil, path.syntaxError(fmt.Sprintf("unexpected %q", expr))
+		}
+
+		path.steps = append(path.steps, step)
+	}
+
+	return path, nil
+}
+
+// String returns the expression the JSONPath was parsed from
+func (p *JSONPath) String() string {
+	return p.expression
+}
+
+// Find returns every value in data matched by the expression, in document order.
+// Map values are visited in key order.
+func (p *JSONPath) Find(data interface{}) []interface{} {
+	current := []interface{}{data}
+
+	for _, step := range p.steps {
+		var next []interface{}
+		for _, value := range current {
+			next = append(next, step.apply(value)...)
+		}
+		current = next
+	}
+
+	return current
+}
+
+func (s jsonPathStep) apply(value interface{}) []interface{} {
+	switch s.kind {
+	case jsonPathKey:
+		if m, ok := value.(map[string]interface{}); ok {
+			if v, ok := m[s.key]; ok {
+				return []interface{}{v}
+			}
+		}
+	case jsonPathIndex:
+		if list, ok := value.([]interface{}); ok {
+			i := s.index
+			if i < 0 {
+				i += len(list)
+			}
+			if i >= 0 && i < len(list) {
+				return []interface{}{list[i]}
+			}
+		}
+	case jsonPathWildcard:
+		switch t := value.(type) {
+		case []interface{}:
+			return t
+		case map[string]interface{}:
+			var values []interface{}
+			for _, key := range sortedKeys(t) {
+				values = append(values, t[key])
+			}
+			return values
+		}
+	case jsonPathRecursiveKey:
+		var values []interface{}
+		switch t := value.(type) {
+		case []interface{}:
+			for _, v := range t {
+				values = append(values, s.apply(v)...)
+			}
+		case map[string]interface{}:
+			if v, ok := t[s.key]; ok {
+				values = append(values, v)
+			}
+			for _, key := range sortedKeys(t) {
+				values = append(values, s.apply(t[key])...)
+			}
+		}
+		return values
+	}
+
+	return nil
+}
+
+func (p *JSONPath) syntaxError(reason string) error {
+	return fmt.Errorf("invalid JSONPath expression %q: %s", p.expression, reason)
+}
+
+func readJSONPathKey(expr string) (string, string) {
+	end := strings.IndexAny(expr, ".[")
+	if end < 0 {
+		return expr, ""
+	}
+	return expr[:end], expr[end:]
+}
+
+func parseJSONPathBracket(contents string) (jsonPathStep, error) {
+	contents = strings.TrimSpace(contents)
+
+	switch {
+	case contents == "*":
+		return jsonPathStep{kind: jsonPathWildcard}, nil
+	case len(contents) >= 2 && (contents[0] == '\'' || contents[0] == '"') && contents[len(contents)-1] == contents[0]:
+		return jsonPathStep{kind: jsonPathKey, key: contents[1 : len(contents)-1]}, nil
+	}
+
+	index, err := strconv.Atoi(contents)
+	if err != nil {
+		return jsonPathStep{}, fmt.Errorf("%q is not an index, quoted key or '*'", contents)
+	}
+
+	return jsonPathStep{kind: jsonPathIndex, index: index}, nil
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
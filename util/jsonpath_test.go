@@ -0,0 +1,82 @@
+package util_test
+
+import (
+	"encoding/json"
+
+	"code.cloudfoundry.org/credhub-cli/util"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("JSONPath", func() {
+	var data interface{}
+
+	BeforeEach(func() {
+		err := json.Unmarshal([]byte(`{
+			"credentials": [
+				{"name": "/a", "version_created_at": "2017-01-01T00:00:00Z"},
+				{"name": "/b", "version_created_at": "2017-01-02T00:00:00Z"}
+			],
+			"value": {
+				"ca": "some-ca",
+				"nested.key": {"certificate": "some-cert"}
+			}
+		}`), &data)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	find := func(expression string) []interface{} {
+		path, err := util.ParseJSONPath(expression)
+		Expect(err).NotTo(HaveOccurred())
+		return path.Find(data)
+	}
+
+	It("finds map keys", func() {
+		Expect(find(".value.ca")).To(Equal([]interface{}{"some-ca"}))
+		Expect(find("value.ca")).To(Equal([]interface{}{"some-ca"}))
+		Expect(find("$.value.ca")).To(Equal([]interface{}{"some-ca"}))
+		Expect(find("{.value.ca}")).To(Equal([]interface{}{"some-ca"}))
+	})
+
+	It("finds quoted keys", func() {
+		Expect(find(`.value['nested.key'].certificate`)).To(Equal([]interface{}{"some-cert"}))
+		Expect(find(`.value["nested.key"]["certificate"]`)).To(Equal([]interface{}{"some-cert"}))
+	})
+
+	It("finds list elements by index", func() {
+		Expect(find(".credentials[0].name")).To(Equal([]interface{}{"/a"}))
+		Expect(find(".credentials[-1].name")).To(Equal([]interface{}{"/b"}))
+	})
+
+	It("expands wildcards", func() {
+		Expect(find(".credentials[*].name")).To(Equal([]interface{}{"/a", "/b"}))
+		Expect(find(".value.*")).To(HaveLen(2))
+	})
+
+	It("finds keys at any depth", func() {
+		Expect(find("..certificate")).To(Equal([]interface{}{"some-cert"}))
+		Expect(find("..name")).To(Equal([]interface{}{"/a", "/b"}))
+	})
+
+	It("returns the root for an empty expression", func() {
+		Expect(find("$")).To(Equal([]interface{}{data}))
+	})
+
+	It("returns nothing when the path does not match", func() {
+		Expect(find(".value.missing")).To(BeEmpty())
+		Expect(find(".credentials[5]")).To(BeEmpty())
+		Expect(find(".value[0]")).To(BeEmpty())
+	})
+
+	It("returns an error for invalid expressions", func() {
+		_, err := util.ParseJSONPath(".credentials[0")
+		Expect(err).To(MatchError(`invalid JSONPath expression ".credentials[0": unterminated '['`))
+
+		_, err = util.ParseJSONPath(".credentials[first]")
+		Expect(err).To(MatchError(`invalid JSONPath expression ".credentials[first]": "first" is not an index, quoted key or '*'`))
+
+		_, err = util.ParseJSONPath(".value.")
+		Expect(err).To(MatchError(`invalid JSONPath expression ".value.": expected a key after '.'`))
+	})
+})